@@ -15,6 +15,44 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/calendar/{token}": {
+            "get": {
+                "description": "Public feed behind the secret URL from POST /user/calendar. Each completed workout of the last year is an all-day event whose summary lists the program and exercises.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "iCalendar feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feed token followed by .ics",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/exercises": {
             "get": {
                 "description": "Retrieve a list of all available exercises",
@@ -50,29 +88,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/exercises/search": {
+        "/exercises/available": {
             "get": {
-                "description": "Find exercises by one of the following parameters: id, name, type, muscle group, or difficulty",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Catalog exercises that need only the equipment of the profile given by profile, or of the user's default profile, or body weight. Without profiles the whole catalog is searched. name matches any part of the exercise name; the other filters match exactly. Filters combine.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Exercises"
                 ],
-                "summary": "Search exercises by parameter",
+                "summary": "Search exercises available with an equipment profile",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Exercise ID",
-                        "name": "id",
+                        "type": "integer",
+                        "description": "Equipment profile ID, defaults to the user's default profile",
+                        "name": "profile",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Exercise name",
+                        "description": "Part of the exercise name",
                         "name": "name",
                         "in": "query"
                     },
@@ -126,14 +166,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/programs": {
+        "/exercises/search": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve a user's specific program by program ID",
+                "description": "Find exercises by one of the following parameters: id, name, type, muscle group, or difficulty",
                 "consumes": [
                     "application/json"
                 ],
@@ -141,23 +176,49 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Programs"
+                    "Exercises"
                 ],
-                "summary": "Get a program by ID",
+                "summary": "Search exercises by parameter",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Program ID",
+                        "type": "string",
+                        "description": "Exercise ID",
                         "name": "id",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exercise name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exercise type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target muscle group",
+                        "name": "muscle",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exercise difficulty level",
+                        "name": "difficulty",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Program"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Exercise"
+                            }
                         }
                     },
                     "400": {
@@ -179,40 +240,58 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/exercises/{id}/alternatives": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a program for a user with a list of exercises",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Exercises training the same muscle group, best match first: the score adds 3 for the same equipment (2 for the same kind, e.g. barbell and dumbbell), 2 for the same type (1 for another strength type) and 2 for the same difficulty (1 for one level off). Only exercises using the available equipment or body weight are suggested: the equipment parameter if set, otherwise the equipment profile given by profile or the user's default profile.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Programs"
+                    "Exercises"
                 ],
-                "summary": "Create a new workout program",
+                "summary": "Suggest substitutes for an exercise",
                 "parameters": [
                     {
-                        "description": "Program information",
-                        "name": "program",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RequestCreateProgram"
-                        }
+                        "type": "integer",
+                        "description": "Exercise ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated available equipment, e.g. dumbbell,bands",
+                        "name": "equipment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Equipment profile ID, defaults to the user's default profile",
+                        "name": "profile",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of suggestions, 1 to 50 (default 10)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Created Program ID",
+                        "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExerciseAlternative"
+                            }
                         }
                     },
                     "400": {
@@ -234,38 +313,55 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/export": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a user's program by its ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Streams the user's data as a download. Workouts and body metrics are limited to from/to (defaults to all history); programs are always exported in full. Weights use the requested unit, distances km or mi, elevation m or ft, lengths cm.\n\nSections and their columns, in this order:\nworkouts: workout_id, date, program, workout_duration_seconds, calories, exercise, exercise_type, set_number, reps, weight, weight_unit, distance, distance_unit, duration_seconds, elevation_gain, elevation_unit, avg_heart_rate, max_heart_rate (one row per set; cardio entries and empty workouts take one row)\nprograms: program_id, program, created_at, exercise, sets, reps, weight, weight_unit, phase, week, day, reps_max, percent_e1rm (one row per exercise slot)\nbody_metrics: measured_at, body_weight, weight_unit, body_fat, waist, chest, arms, thighs, resting_heart_rate\n\ncsv is a single table: a record_type column with the section name followed by the union of the columns above. json is an object with one array per section. xlsx has one sheet per section.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Programs"
+                    "Export"
                 ],
-                "summary": "Delete a workout program",
+                "summary": "Export workouts, programs and body metrics",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Program ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "csv, json or xlsx",
+                        "name": "format",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "From date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "To date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Deleted Program ID",
+                        "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -276,58 +372,39 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/imports": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a program's name and exercises by its ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Status, progress and results of a history import",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Programs"
+                    "Imports"
                 ],
-                "summary": "Update an existing workout program",
+                "summary": "Get an import job",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Program ID",
+                        "description": "Import ID",
                         "name": "id",
                         "in": "query",
                         "required": true
-                    },
-                    {
-                        "description": "Updated program information",
-                        "name": "program",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RequestCreateProgram"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Updated Program ID",
+                        "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/models.ImportJob"
                         }
                     },
                     "400": {
@@ -349,40 +426,50 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/user": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve user information using email address",
+                "description": "Upload a Strong or Hevy CSV export. The file is checked right away and imported in the background; poll GET /imports with the returned id for progress. With dry_run nothing is saved and the finished job contains a preview of the workouts that would be created. Exercise names are matched against the user's mappings first, then the catalog (case-insensitive); unmatched ones are listed under unmapped.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Imports"
                 ],
-                "summary": "Get user by email",
+                "summary": "Import workout history from Strong or Hevy",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User email",
-                        "name": "email",
-                        "in": "query",
+                        "type": "file",
+                        "description": "CSV export",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview without saving",
+                        "name": "dry_run",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of exports that don't state it (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.ImportJob"
                         }
                     },
                     "400": {
@@ -394,8 +481,15 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -404,51 +498,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/imports/mappings": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a user account using their email address",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Names from other apps and the catalog exercises they are imported as",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
-                ],
-                "summary": "Delete user by email",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User email",
-                        "name": "email",
-                        "in": "query",
-                        "required": true
-                    }
+                    "Imports"
                 ],
+                "summary": "List exercise name mappings",
                 "responses": {
                     "200": {
-                        "description": "Deleted user ID",
-                        "schema": {
-                            "type": "integer"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExerciseNameMapping"
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -457,11 +535,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/user/login": {
-            "post": {
-                "description": "User login to obtain JWT token",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates or replaces the mapping for a name (case-insensitive); used by later imports",
                 "consumes": [
                     "application/json"
                 ],
@@ -469,23 +550,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Imports"
                 ],
-                "summary": "Authenticate user and get token",
+                "summary": "Map an exercise name to a catalog exercise",
                 "parameters": [
                     {
-                        "description": "User login credentials",
-                        "name": "user",
+                        "description": "Mapping",
+                        "name": "mapping",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RequestLoginUser"
+                            "$ref": "#/definitions/models.RequestExerciseNameMapping"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT Token",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -501,50 +582,38 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 }
-            }
-        },
-        "/user/register": {
-            "post": {
-                "description": "Create a new user account",
-                "consumes": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Imports"
                 ],
-                "summary": "Register a new user",
+                "summary": "Delete an exercise name mapping",
                 "parameters": [
                     {
-                        "description": "User registration information",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RequestCreateUser"
-                        }
+                        "type": "string",
+                        "description": "Name used by the other app",
+                        "name": "source_name",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Registered user ID",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "integer"
+                                "type": "string"
                             }
                         }
                     },
@@ -557,8 +626,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -569,38 +638,94 @@ const docTemplate = `{
                 }
             }
         },
-        "/workouts": {
+        "/library/programs": {
             "get": {
-                "security": [
+                "description": "Search the public program library, most followed first. Programs must carry every tag given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Library"
+                ],
+                "summary": "Browse public programs",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "description": "Case-insensitive part of the program name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags, e.g. strength,beginner",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 1 to 100 (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of programs to skip",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
-                "description": "Retrieve a specific workout for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LibraryProgramSummary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/library/programs/{id}": {
+            "get": {
+                "description": "Retrieve a public program with its author, followers and forks count",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workouts"
+                    "Library"
                 ],
-                "summary": "Get a workout by ID",
+                "summary": "Get a public program",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Workout ID",
+                        "description": "Program ID",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb)",
+                        "name": "unit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Workout"
+                            "$ref": "#/definitions/models.ResponseLibraryProgram"
                         }
                     },
                     "400": {
@@ -622,40 +747,36 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/library/programs/{id}/follow": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new workout for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workouts"
+                    "Library"
                 ],
-                "summary": "Create a new workout",
+                "summary": "Follow a public program",
                 "parameters": [
                     {
-                        "description": "Workout information",
-                        "name": "workout",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RequestCreateWorkout"
-                        }
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Created workout ID",
+                        "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/models.ResponseProgramFollowers"
                         }
                     },
                     "400": {
@@ -667,17 +788,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -693,31 +805,27 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a specific workout for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Workouts"
+                    "Library"
                 ],
-                "summary": "Delete a workout by ID",
+                "summary": "Stop following a program",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Workout ID",
+                        "description": "Program ID",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Deleted workout ID",
+                        "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/models.ResponseProgramFollowers"
                         }
                     },
                     "400": {
@@ -729,8 +837,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -739,14 +847,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/library/programs/{id}/fork": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a specific workout's details for the authenticated user",
+                "description": "Copy a public program, or an unlisted one with its share token, into the user's account as a private program. The copy keeps the name and author of the original as attribution. The body is optional.",
                 "consumes": [
                     "application/json"
                 ],
@@ -754,30 +864,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Workouts"
+                    "Library"
                 ],
-                "summary": "Update an existing workout",
+                "summary": "Fork a library program",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Workout ID",
+                        "description": "Program ID",
                         "name": "id",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated workout information",
-                        "name": "workout",
+                        "description": "Name of the copy and share token",
+                        "name": "fork",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RequestCreateWorkout"
+                            "$ref": "#/definitions/models.RequestForkProgram"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Updated workout ID",
+                    "201": {
+                        "description": "Created Program ID",
                         "schema": {
                             "type": "integer"
                         }
@@ -802,218 +911,5101 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Exercise": {
-            "type": "object",
-            "properties": {
-                "difficulty": {
-                    "type": "string"
-                },
-                "equipment": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "instruction": {
-                    "type": "string"
-                },
-                "muscleGroup": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
+        },
+        "/library/shared/{token}": {
+            "get": {
+                "description": "Retrieve an unlisted or public program by the token of its share link",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Library"
+                ],
+                "summary": "Open a program share link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb)",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseLibraryProgram"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "models.ExerciseEntry": {
-            "type": "object",
-            "properties": {
-                "exerciseID": {
-                    "type": "integer"
-                },
+        "/metrics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get one entry by id, or the history between from and to (defaults to the last year)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Metrics"
+                ],
+                "summary": "Get body metrics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Metric ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "From date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "To date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb)",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BodyMetric"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a body metrics entry (weight, body fat %, waist, chest, arms, thighs, resting heart rate). Any subset may be sent",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Metrics"
+                ],
+                "summary": "Log body measurements",
+                "parameters": [
+                    {
+                        "description": "Measurements",
+                        "name": "metric",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestBodyMetric"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created metric ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Metrics"
+                ],
+                "summary": "Delete a body metrics entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Metric ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted metric ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the measurements of an entry. The entry keeps its measured_at unless the request sets a new one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Metrics"
+                ],
+                "summary": "Update a body metrics entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Metric ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Measurements",
+                        "name": "metric",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestBodyMetric"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated metric ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics/trend": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Trailing moving average of one metric over a window of days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Metrics"
+                ],
+                "summary": "Get a smoothed body metric trend",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "weight, body_fat, waist, chest, arms, thighs or resting_heart_rate (default weight)",
+                        "name": "metric",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window in days (default 7)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "From date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "To date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb)",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseBodyMetricTrend"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a user's specific program by program ID. Phases are always returned; the flat exercises list is filled for single-day programs. Percentage slots get a target_weight from the best estimated one-rep max in the user's history. Without id, returns the user's programs as an array of models.ProgramSummary (exercise count and the date of the last workout logged for each), most recently used first, optionally filtered by search.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Get a program by ID or list programs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive part of the program name, for the list",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestGetProgram"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a program either from a flat list of exercises or from phases made of weeks, days and ordered exercise slots. A slot's set scheme is given as a shorthand (\"5x5\", \"3x8-12\", \"5x3@85%\" of the estimated one-rep max) or through sets, reps, reps_max, weight and percent_e1rm. Exercises are done in the order listed; consecutive exercises sharing a group label form a superset (two exercises), giant set (three or more) or circuit, described in the day's groups with rounds and rest between rounds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Create a new workout program",
+                "parameters": [
+                    {
+                        "description": "Program information",
+                        "name": "program",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestCreateProgram"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created Program ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a user's program by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Delete a workout program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted Program ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace a program's name and structure by its ID; accepts the same body as POST /programs. The previous state stays available under /programs/{id}/versions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Update an existing workout program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated program information",
+                        "name": "program",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestCreateProgram"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated Program ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/generate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Build a balanced one-week program from the exercise catalog for a goal (strength, hypertrophy or endurance), 1-6 days per week and a session length. The split follows the days per week (full body, push/pull/legs, upper/lower), exercises are matched to the experience level (difficulty), the available equipment (from the request, otherwise from the equipment profile given by profile_id or the default profile) and the goal (exercise type), and endurance days are laid out as circuits. The same request with the same seed always yields the same program; the seed used is returned. With preview the program is returned without being saved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Generate a program",
+                "parameters": [
+                    {
+                        "description": "Generator settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestGenerateProgram"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preview",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGeneratedProgram"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseGeneratedProgram"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/progression": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the program's rules with the targets for the next session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Get the progression rules of a program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ResponseProgressionRule"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create or replace the rule that moves an exercise's targets after each workout logged for the program. linear adds increment once every set hits the target reps; double adds reps up to the top of the slot's rep range, then adds increment and drops back to the bottom; wave cycles through wave_percents of a training max and raises it by increment after each completed wave. Without increment, linear and double rules use the plate increment (barbell, EZ bar) or dumbbell increment (dumbbell, kettlebells) of the default equipment profile. After deload_after missed sessions in a row (0 disables it) the load drops by deload_percent (default 10). Saving a rule restarts its progression from start_weight, which defaults to the slot's weight or the estimated one-rep max.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Set the progression rule of a program exercise",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Progression rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestProgressionRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The exercise keeps its program targets; past history is kept",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Delete the progression rule of a program exercise",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exercise name",
+                        "name": "exercise",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/progression/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every evaluation after a workout, newest first, with the targets before and after and the reason for the change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Get the progression history of a program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this exercise",
+                        "name": "exercise",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ResponseProgressionEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/sharing": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set a program's visibility (private, unlisted or public) and its tags, such as strength, hypertrophy or beginner. Unlisted and public programs get a share link; switching back to private revokes it. Omitted fields are left unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Change who can see a program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Visibility and tags",
+                        "name": "sharing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestProgramSharing"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseProgramSharing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/{id}/clone": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copy one of the user's programs, with all phases, weeks, days and slots, into a new program. The body is optional.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Clone a workout program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Name of the copy",
+                        "name": "program",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestCloneProgram"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created Program ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/{id}/swap": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace an exercise with another one (see GET /exercises/{id}/alternatives) in every slot of the program, or only in the given phase, week and day. The slots keep their set schemes and remember the exercise they replaced (substituted_for); the change is stored as a new program version. Cardio and strength exercises can not replace each other. When the exercise leaves the program entirely, its progression rule and current target move to the replacement; the swap is rejected if the replacement already has a rule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Swap an exercise in a program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Exercise to replace and its replacement",
+                        "name": "swap",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestSwapProgramExercise"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSwapExercise"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Every create or update that changes a program's name or structure stores an immutable version. Versions are listed newest first with the number of workouts logged against each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "List the versions of a program",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProgramVersionSummary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/{id}/versions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the days and exercise slots that were added, removed or changed between two versions. Days are matched by phase, week and day position. By default the current version is compared with the one before it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Compare two program versions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Older version number",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Newer version number, defaults to the current version",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProgramVersionDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/{id}/versions/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a program as it was in the given version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Get a program version",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version number",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseProgramVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Planned, completed, skipped and missed program sessions between from and to (inclusive), merged with workouts logged outside the schedule. from defaults to today and to to four weeks later; the range may span at most a year. Missed sessions are rescheduled per the user's reschedule_policy by an hourly background job. Recurring sessions more than eight weeks ahead are listed without a session_id until they are created.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Get the training calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScheduleEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "List recurring schedules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ResponseScheduleRule"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedule a program on weekdays (mon..sun) from start_date (default today) until the optional end_date. Without day the sessions rotate through the program's days in order; with day every session is that day. Sessions are created eight weeks ahead and extended automatically.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Plan a program on recurring weekdays",
+                "parameters": [
+                    {
+                        "description": "Recurring schedule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestScheduleRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Planned sessions of the rule from today on are removed; past sessions stay in the calendar",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Stop a recurring schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/sessions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedule a single session of one of the user's programs. day is the 1-based training day across the program's phases and weeks and defaults to 1.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Plan a program day on a date",
+                "parameters": [
+                    {
+                        "description": "Session",
+                        "name": "session",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestScheduleSession"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Remove a scheduled session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/schedule/sessions/skip": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark a planned or missed session as skipped; skipped sessions are never rescheduled",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Skip a planned session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve user information using email address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user by email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User email",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb)",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedules the account for deletion after a grace period (ACCOUNT_DELETION_GRACE_DAYS, 14 days by default). The email must match the account as a confirmation. Until then the account keeps working and the deletion can be cancelled with POST /user/deletion/cancel; afterwards the account and all of its data are erased by a background worker.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Schedule account deletion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User email",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseAccountDeletion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Partially update the authenticated user's profile. Only fields present in the body are changed.\nChanging the email requires re-verification and returns a new token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update the user profile",
+                "parameters": [
+                    {
+                        "description": "Fields to update",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestUpdateUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseUpdateUser"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn 2FA off after re-authenticating with the password and a TOTP or recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Password and second factor",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestDisableTwoFactor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a TOTP secret and otpauth URI for an authenticator app. 2FA is enabled only after verification",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Start two-factor enrolment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseTwoFactorEnroll"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Confirm the TOTP secret with a code, enable 2FA and receive one-time recovery codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Verify two-factor enrolment",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestTwoFactorCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseRecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/calendar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tells whether the user has a calendar feed and when its URL was created",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Get the calendar feed",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CalendarFeed"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a secret iCalendar URL to subscribe to in Google or Apple Calendar. The URL is shown only once; calling this again replaces it and the old URL stops working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Create or regenerate the calendar feed URL",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseCalendarFeed"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The feed URL stops working immediately",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Revoke the calendar feed",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/data-export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Status of a \"download my data\" archive",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get a personal data archive",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Data export ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DataExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Builds a zip archive in the background with profile.json, workouts.json, programs.json, body_metrics.json (same columns as GET /export, in the user's unit), tokens.json (token metadata, never the secrets) and audit_log.json. Poll GET /user/data-export with the returned id and download it from GET /user/data-export/download once completed. Archives are kept for 7 days. While an archive is being built it is returned instead of starting another.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Request a copy of all personal data",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.DataExport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/data-export/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads a completed archive that hasn't expired",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Download a personal data archive",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Data export ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/deletion/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Keeps the account if its deletion grace period hasn't ended yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Cancel a scheduled account deletion",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/equipment-profiles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The user's equipment profiles, oldest first, with increments in the requested unit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "List equipment profiles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb)",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ResponseEquipmentProfile"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the name, equipment and increments of a profile. default makes it the default profile; to move the default away, make another profile the default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Update an equipment profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Profile ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Equipment profile",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestEquipmentProfile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated profile ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Save a named place to train (home gym, commercial gym, travel) with the catalog equipment available there and the smallest plate and dumbbell increments. Body weight exercises are always available. The first profile, or one created with default, becomes the default profile used by exercise search, alternatives, program generation and progression increments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Create an equipment profile",
+                "parameters": [
+                    {
+                        "description": "Equipment profile",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestEquipmentProfile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created profile ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "When the default profile is deleted, the oldest remaining profile becomes the default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Equipment"
+                ],
+                "summary": "Delete an equipment profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Profile ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted profile ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "User login to obtain JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Authenticate user and get token",
+                "parameters": [
+                    {
+                        "description": "User login credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestLoginUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT Token or two-factor challenge",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseLogin"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Account temporarily locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login/2fa": {
+            "post": {
+                "description": "Exchange the challenge token from /user/login and a TOTP or recovery code for a JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Complete a two-factor login",
+                "parameters": [
+                    {
+                        "description": "Challenge token and second factor",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestLoginTwoFactor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT Token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseLogin"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Account temporarily locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too many attempts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the password of the authenticated user. All other sessions are revoked and a new token is returned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestChangePassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New JWT Token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password/forgot": {
+            "post": {
+                "description": "Send a single-use reset token to the email address if an account exists. The response is the same either way and the email is sent in the background. Rate limited per IP and per email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestForgotPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password/reset": {
+            "post": {
+                "description": "Set a new password using a token from the reset email. The token is single-use and revokes all sessions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Reset password with a token",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "reset",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestResetPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/register": {
+            "post": {
+                "description": "Create a new user account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration information",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestCreateUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Registered user ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/verify": {
+            "get": {
+                "description": "Confirm the account email with the token from the verification email",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Verify email address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/verify/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a new verification email to the authenticated user. Rate limited",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Resend verification email",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a specific workout for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Get a workout by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Weight unit of the response (kg or lb), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestGetWorkout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new workout for the authenticated user. Exercises are stored in the order given; consecutive exercises sharing a group label form a superset, giant set or circuit described in groups.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Create a new workout",
+                "parameters": [
+                    {
+                        "description": "Workout information",
+                        "name": "workout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestCreateWorkout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created workout ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a specific workout for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Delete a workout by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted workout ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a specific workout's details for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Update an existing workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated workout information",
+                        "name": "workout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestCreateWorkout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated workout ID",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a workout with a cardio entry (distance, duration, elevation gain, heart rate, cadence and splits) and keeps the raw file. Strength sets of FIT files become exercise entries; sets whose FIT category has no catalog exercise are skipped and listed under unmapped. Uploading the same file again returns 409 with the existing workout id.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Import a workout from a GPX, TCX or FIT file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Activity file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Catalog exercise name; picked from the activity sport when omitted",
+                        "name": "exercise",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseImportWorkout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Weekly distance, duration and session count plus the best pace per distance (1k, 5k, 10k, half marathon, marathon) for each cardio exercise",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Get cardio workout statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From date (YYYY-MM-DD), defaults to three months ago",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "To date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Distance unit of the response (kg/km or lb/mi), defaults to the user's preference",
+                        "name": "unit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseWorkoutStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/swap": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace an exercise of a workout, e.g. when the equipment is busy, keeping the sets logged so far. The entry remembers the exercise it replaced (substituted_for), so the workout still matches its program. position picks one entry when the exercise appears more than once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Swap an exercise in a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Exercise to replace and its replacement",
+                        "name": "swap",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RequestSwapWorkoutExercise"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseSwapExercise"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/track": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the file exactly as it was uploaded",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Workouts"
+                ],
+                "summary": "Download the track file of an imported workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.BestPace": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "distance": {
+                    "type": "string"
+                },
+                "exercise": {
+                    "type": "string"
+                },
+                "pace": {
+                    "type": "string"
+                },
+                "workout_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BodyMetric": {
+            "type": "object",
+            "properties": {
+                "arms": {
+                    "type": "number"
+                },
+                "body_fat": {
+                    "type": "number"
+                },
+                "chest": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "measured_at": {
+                    "type": "string"
+                },
+                "resting_heart_rate": {
+                    "type": "integer"
+                },
+                "thighs": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "waist": {
+                    "type": "number"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BodyMetricTrendPoint": {
+            "type": "object",
+            "properties": {
+                "measured_at": {
+                    "type": "string"
+                },
+                "moving_average": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CalendarFeed": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DataExport": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Exercise": {
+            "type": "object",
+            "properties": {
+                "difficulty": {
+                    "type": "string"
+                },
+                "equipment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instruction": {
+                    "type": "string"
+                },
+                "muscleGroup": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExerciseAlternative": {
+            "type": "object",
+            "properties": {
+                "difficulty": {
+                    "type": "string"
+                },
+                "equipment": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "muscle_group": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExerciseGroup": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "example": "A"
+                },
+                "rest_seconds": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "rounds": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "type": {
+                    "type": "string",
+                    "example": "superset"
+                }
+            }
+        },
+        "models.ExerciseNameMapping": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "exercise_id": {
+                    "type": "integer"
+                },
+                "exercise_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "source_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExerciseRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "reps": {
+                    "type": "integer"
+                },
+                "sets": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ExerciseRequestEntry": {
+            "type": "object",
+            "properties": {
+                "avg_cadence": {
+                    "type": "integer"
+                },
+                "avg_heart_rate": {
+                    "type": "integer"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "30m0s"
+                },
+                "elevation_gain": {
+                    "type": "number"
+                },
+                "group": {
+                    "type": "string",
+                    "example": "A"
+                },
+                "intervals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RequestInterval"
+                    }
+                },
+                "max_heart_rate": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pace": {
+                    "type": "string"
+                },
+                "reps": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "sets": {
+                    "type": "integer"
+                },
+                "substituted_for": {
+                    "description": "SubstitutedFor names the exercise this entry was swapped in for.",
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.ImportJob": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_workouts": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "preview": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "processed_workouts": {
+                    "type": "integer"
+                },
+                "skipped_workouts": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_workouts": {
+                    "type": "integer"
+                },
+                "unmapped": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "models.LibraryProgramSummary": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "exercise_count": {
+                    "type": "integer"
+                },
+                "followers_count": {
+                    "type": "integer"
+                },
+                "forks_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ProgramAttribution": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "program_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgramDayDiff": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "string"
+                },
+                "day": {
+                    "type": "integer"
+                },
+                "exercises": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProgramSlotDiff"
+                    }
+                },
+                "fields": {
+                    "description": "Fields lists changed day attributes, currently only \"name\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phase": {
+                    "type": "integer"
+                },
+                "week": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgramNameChange": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProgramSlotDiff": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "string"
+                },
+                "exercise": {
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields lists the changed attributes, e.g. \"sets\", \"weight\" or \"position\".",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "$ref": "#/definitions/models.RequestProgramSlot"
+                },
+                "to": {
+                    "$ref": "#/definitions/models.RequestProgramSlot"
+                }
+            }
+        },
+        "models.ProgramVersionDiff": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProgramDayDiff"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "name": {
+                    "$ref": "#/definitions/models.ProgramNameChange"
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProgramVersionSummary": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "workout_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProgressionTarget": {
+            "type": "object",
+            "properties": {
+                "reps": {
+                    "type": "integer"
+                },
+                "sets": {
+                    "type": "integer"
+                },
+                "training_max": {
+                    "description": "TrainingMax and WaveStep are set for wave rules.",
+                    "type": "number"
+                },
+                "wave_step": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.RequestBodyMetric": {
+            "type": "object",
+            "properties": {
+                "arms": {
+                    "type": "number"
+                },
+                "body_fat": {
+                    "type": "number"
+                },
+                "chest": {
+                    "type": "number"
+                },
+                "measured_at": {
+                    "type": "string"
+                },
+                "resting_heart_rate": {
+                    "type": "integer"
+                },
+                "thighs": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                },
+                "waist": {
+                    "type": "number"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.RequestChangePassword": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestCloneProgram": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name defaults to the original name with \" (copy)\" appended.",
+                    "type": "string",
+                    "example": "5x5 (copy)"
+                }
+            }
+        },
+        "models.RequestCreateProgram": {
+            "type": "object",
+            "properties": {
+                "exercises": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseRequest"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RequestProgramPhase"
+                    }
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                }
+            }
+        },
+        "models.RequestCreateUser": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.RequestCreateWorkout": {
+            "type": "object",
+            "required": [
+                "duration"
+            ],
+            "properties": {
+                "calories": {
+                    "description": "Calories overrides the server estimate; omit it to use the estimate.",
+                    "type": "number"
+                },
+                "duration": {
+                    "type": "string"
+                },
+                "exercises": {
+                    "description": "Exercises are stored in the order given; Groups describes the supersets,\ngiant sets and circuits they refer to.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseRequestEntry"
+                    }
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseGroup"
+                    }
+                },
+                "program_id": {
+                    "type": "integer"
+                },
+                "program_name": {
+                    "description": "ProgramName is optional; workouts without a program are free sessions.\nProgramID takes precedence and picks one of several same-named programs.",
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                }
+            }
+        },
+        "models.RequestDisableTwoFactor": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestEquipmentProfile": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "Default makes this the active profile. The first profile always is.",
+                    "type": "boolean"
+                },
+                "dumbbell_increment": {
+                    "type": "number",
+                    "example": 2
+                },
+                "equipment": {
+                    "description": "Equipment lists catalog equipment values; body weight exercises are\nalways available.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "dumbbell",
+                        "bands"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Home gym"
+                },
+                "plate_increment": {
+                    "type": "number",
+                    "example": 2.5
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                }
+            }
+        },
+        "models.RequestExerciseNameMapping": {
+            "type": "object",
+            "required": [
+                "exercise",
+                "source_name"
+            ],
+            "properties": {
+                "exercise": {
+                    "type": "string",
+                    "example": "Barbell Bench Press"
+                },
+                "source_name": {
+                    "type": "string",
+                    "example": "Bench Press (Barbell)"
+                }
+            }
+        },
+        "models.RequestForgotPassword": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestForkProgram": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "share_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestGenerateProgram": {
+            "type": "object",
+            "properties": {
+                "days_per_week": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "equipment": {
+                    "description": "Equipment lists catalog equipment values; body weight exercises are\nalways allowed. Empty means the equipment of ProfileID, or of the\ndefault profile, or any equipment for users without profiles.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "barbell",
+                        "dumbbell"
+                    ]
+                },
+                "experience": {
+                    "description": "Experience defaults to beginner.",
+                    "type": "string",
+                    "example": "beginner"
+                },
+                "goal": {
+                    "type": "string",
+                    "example": "hypertrophy"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "My first program"
+                },
+                "preview": {
+                    "description": "Preview returns the program without saving it.",
+                    "type": "boolean"
+                },
+                "profile_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "seed": {
+                    "description": "Seed defaults to a random value, returned in the response.",
+                    "type": "integer",
+                    "example": 42
+                },
+                "session_minutes": {
+                    "description": "SessionMinutes defaults to 60.",
+                    "type": "integer",
+                    "example": 60
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                }
+            }
+        },
+        "models.RequestGetProgram": {
+            "type": "object",
+            "properties": {
+                "exercises": {
+                    "description": "Exercises is the flat form, filled for programs with a single day.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseRequest"
+                    }
+                },
+                "forked_from": {
+                    "$ref": "#/definitions/models.ProgramAttribution"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RequestProgramPhase"
+                    }
+                },
+                "share_url": {
+                    "description": "ShareURL is only shown to the owner of an unlisted or public program.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestGetWorkout": {
+            "type": "object",
+            "properties": {
+                "calories": {
+                    "type": "number"
+                },
+                "calories_estimated": {
+                    "type": "number"
+                },
+                "calories_override": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "string"
+                },
+                "exercises": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseRequestEntry"
+                    }
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseGroup"
+                    }
+                },
                 "id": {
                     "type": "integer"
                 },
+                "program_id": {
+                    "type": "integer"
+                },
+                "program_version": {
+                    "description": "ProgramVersion is the number of the program version the workout followed.",
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RequestInterval": {
+            "type": "object",
+            "properties": {
+                "avg_cadence": {
+                    "type": "integer"
+                },
+                "avg_heart_rate": {
+                    "type": "integer"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "4m0s"
+                },
+                "pace": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "work"
+                }
+            }
+        },
+        "models.RequestLoginTwoFactor": {
+            "type": "object",
+            "required": [
+                "challenge_token"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestLoginUser": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestProgramDay": {
+            "type": "object",
+            "properties": {
+                "exercises": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RequestProgramSlot"
+                    }
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseGroup"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Day A"
+                }
+            }
+        },
+        "models.RequestProgramPhase": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Accumulation"
+                },
+                "weeks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RequestProgramWeek"
+                    }
+                }
+            }
+        },
+        "models.RequestProgramSharing": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "visibility": {
+                    "type": "string",
+                    "example": "public"
+                }
+            }
+        },
+        "models.RequestProgramSlot": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "example": "A"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Barbell Squat"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "percent_e1rm": {
+                    "type": "number"
+                },
                 "reps": {
+                    "type": "integer"
+                },
+                "reps_max": {
+                    "type": "integer"
+                },
+                "rest_seconds": {
+                    "type": "integer"
+                },
+                "scheme": {
+                    "type": "string",
+                    "example": "3x8-12"
+                },
+                "sets": {
+                    "type": "integer"
+                },
+                "substituted_for": {
+                    "description": "SubstitutedFor names the exercise this one replaced in the program.",
+                    "type": "string"
+                },
+                "target_weight": {
+                    "description": "TargetWeight is response-only: PercentE1RM applied to the user's\ncurrent estimated one-rep max.",
+                    "type": "number"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.RequestProgramWeek": {
+            "type": "object",
+            "properties": {
+                "days": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/models.RequestProgramDay"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Week 1"
+                }
+            }
+        },
+        "models.RequestProgressionRule": {
+            "type": "object",
+            "properties": {
+                "deload_after": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "deload_percent": {
+                    "type": "number",
+                    "example": 10
+                },
+                "exercise": {
+                    "type": "string",
+                    "example": "Barbell Squat"
+                },
+                "increment": {
+                    "description": "Increment defaults for linear and double rules to the plate or\ndumbbell increment of the default equipment profile.",
+                    "type": "number",
+                    "example": 2.5
+                },
+                "start_weight": {
+                    "description": "StartWeight is the first target (the training max for wave rules).\nDefaults to the slot's weight or the estimated one-rep max.",
+                    "type": "number"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "linear"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "kg"
+                },
+                "wave_percents": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
+        "models.RequestResetPassword": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestScheduleRule": {
+            "type": "object",
+            "required": [
+                "program_id",
+                "weekdays"
+            ],
+            "properties": {
+                "day": {
+                    "description": "Day pins every date to one program day; omit it to rotate through all days.",
+                    "type": "integer"
+                },
+                "end_date": {
+                    "type": "string",
+                    "example": "2026-12-31"
+                },
+                "program_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2026-10-19"
+                },
+                "weekdays": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "mon",
+                        "wed",
+                        "fri"
+                    ]
+                }
+            }
+        },
+        "models.RequestScheduleSession": {
+            "type": "object",
+            "required": [
+                "date",
+                "program_id"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2026-10-19"
+                },
+                "day": {
+                    "description": "Day is the 1-based program day counted across phases and weeks.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "program_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RequestSwapProgramExercise": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "integer"
+                },
+                "exercise": {
+                    "type": "string",
+                    "example": "Barbell Bench Press"
+                },
+                "phase": {
+                    "type": "integer"
+                },
+                "replacement": {
+                    "type": "string",
+                    "example": "Dumbbell Bench Press"
+                },
+                "week": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RequestSwapWorkoutExercise": {
+            "type": "object",
+            "properties": {
+                "exercise": {
+                    "type": "string",
+                    "example": "Barbell Bench Press"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "replacement": {
+                    "type": "string",
+                    "example": "Dumbbell Bench Press"
+                }
+            }
+        },
+        "models.RequestTwoFactorCode": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RequestUpdateUser": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reschedule_policy": {
+                    "type": "string",
+                    "example": "next_free_day"
+                },
+                "unit": {
+                    "description": "Unit is the unit of Weight in this request; defaults to the preference.",
+                    "type": "string",
+                    "example": "kg"
+                },
+                "unit_system": {
+                    "description": "UnitSystem changes the stored preference (metric or imperial).",
+                    "type": "string"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.ResponseAccountDeletion": {
+            "type": "object",
+            "properties": {
+                "deletion_scheduled_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResponseBodyMetricTrend": {
+            "type": "object",
+            "properties": {
+                "metric": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BodyMetricTrendPoint"
+                    }
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "window_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseCalendarFeed": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResponseEquipmentProfile": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "default": {
+                    "type": "boolean"
+                },
+                "dumbbell_increment": {
+                    "type": "number"
+                },
+                "equipment": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 },
-                "sets": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "plate_increment": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResponseGeneratedProgram": {
+            "type": "object",
+            "properties": {
+                "equipment_profile": {
+                    "description": "EquipmentProfile names the profile whose equipment was used.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is omitted for previews.",
+                    "type": "integer"
+                },
+                "program": {
+                    "$ref": "#/definitions/models.RequestGetProgram"
+                },
+                "seed": {
                     "type": "integer"
                 },
-                "weight": {
+                "split": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResponseImportWorkout": {
+            "type": "object",
+            "properties": {
+                "exercise": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "sport": {
+                    "type": "string"
+                },
+                "unmapped": {
+                    "description": "Unmapped lists strength sets whose FIT category has no catalog exercise; they were skipped.",
                     "type": "array",
                     "items": {
-                        "type": "number"
+                        "$ref": "#/definitions/models.UnmappedItem"
                     }
                 },
-                "workoutID": {
+                "workout_id": {
                     "type": "integer"
                 }
             }
         },
-        "models.ExerciseProgramDB": {
+        "models.ResponseLibraryProgram": {
             "type": "object",
             "properties": {
-                "exerciseID": {
-                    "type": "integer"
+                "author": {
+                    "type": "string"
                 },
-                "id": {
+                "exercises": {
+                    "description": "Exercises is the flat form, filled for programs with a single day.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExerciseRequest"
+                    }
+                },
+                "followers_count": {
                     "type": "integer"
                 },
-                "programID": {
+                "forked_from": {
+                    "$ref": "#/definitions/models.ProgramAttribution"
+                },
+                "forks_count": {
                     "type": "integer"
                 },
-                "reps": {
+                "id": {
                     "type": "integer"
                 },
-                "sets": {
+                "name": {
+                    "type": "string"
+                },
+                "phases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RequestProgramPhase"
+                    }
+                },
+                "share_url": {
+                    "description": "ShareURL is only shown to the owner of an unlisted or public program.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
                 },
-                "weight": {
-                    "type": "number"
+                "visibility": {
+                    "type": "string"
                 }
             }
         },
-        "models.ExerciseRequest": {
+        "models.ResponseLogin": {
             "type": "object",
             "properties": {
-                "name": {
+                "challenge_token": {
                     "type": "string"
                 },
-                "reps": {
-                    "type": "integer"
+                "token": {
+                    "type": "string"
                 },
-                "sets": {
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ResponseProgramFollowers": {
+            "type": "object",
+            "properties": {
+                "followers_count": {
                     "type": "integer"
                 },
-                "weight": {
-                    "type": "number"
+                "following": {
+                    "type": "boolean"
                 }
             }
         },
-        "models.ExerciseRequestEntry": {
+        "models.ResponseProgramSharing": {
             "type": "object",
             "properties": {
-                "name": {
+                "share_url": {
                     "type": "string"
                 },
-                "reps": {
+                "tags": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "string"
                     }
                 },
-                "sets": {
-                    "type": "integer"
-                },
-                "weight": {
-                    "type": "array",
-                    "items": {
-                        "type": "number"
-                    }
+                "visibility": {
+                    "type": "string"
                 }
             }
         },
-        "models.Program": {
+        "models.ResponseProgramVersion": {
             "type": "object",
             "properties": {
                 "exercises": {
+                    "description": "Exercises is the flat form, filled for programs with a single day.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ExerciseProgramDB"
+                        "$ref": "#/definitions/models.ExerciseRequest"
                     }
                 },
+                "forked_from": {
+                    "$ref": "#/definitions/models.ProgramAttribution"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
+                "phases": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RequestProgramPhase"
+                    }
+                },
+                "share_url": {
+                    "description": "ShareURL is only shown to the owner of an unlisted or public program.",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "unit": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "version_created_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "type": "string"
                 }
             }
         },
-        "models.RequestCreateProgram": {
+        "models.ResponseProgressionEvent": {
             "type": "object",
             "properties": {
-                "exercises": {
+                "action": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "exercise": {
+                    "type": "string"
+                },
+                "new_reps": {
+                    "type": "integer"
+                },
+                "new_weight": {
+                    "type": "number"
+                },
+                "previous_reps": {
+                    "type": "integer"
+                },
+                "previous_weight": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "workout_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseProgressionRule": {
+            "type": "object",
+            "properties": {
+                "deload_after": {
+                    "type": "integer"
+                },
+                "deload_percent": {
+                    "type": "number"
+                },
+                "exercise": {
+                    "type": "string"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "increment": {
+                    "type": "number"
+                },
+                "next": {
+                    "$ref": "#/definitions/models.ProgressionTarget"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "wave_percents": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ExerciseRequest"
+                        "type": "number"
                     }
-                },
-                "name": {
-                    "type": "string"
                 }
             }
         },
-        "models.RequestCreateUser": {
+        "models.ResponseRecoveryCodes": {
             "type": "object",
             "properties": {
-                "age": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ResponseScheduleRule": {
+            "type": "object",
+            "properties": {
+                "day": {
                     "type": "integer"
                 },
-                "email": {
+                "end_date": {
                     "type": "string"
                 },
-                "gender": {
+                "id": {
+                    "type": "integer"
+                },
+                "program": {
                     "type": "string"
                 },
-                "height": {
+                "program_id": {
                     "type": "integer"
                 },
-                "name": {
+                "start_date": {
                     "type": "string"
                 },
-                "password": {
+                "weekdays": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ResponseSwapExercise": {
+            "type": "object",
+            "properties": {
+                "swapped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ResponseTwoFactorEnroll": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
                     "type": "string"
                 },
-                "weight": {
-                    "type": "number"
+                "secret": {
+                    "type": "string"
                 }
             }
         },
-        "models.RequestCreateWorkout": {
+        "models.ResponseUpdateUser": {
             "type": "object",
-            "required": [
-                "duration"
-            ],
             "properties": {
-                "calories": {
+                "token": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "models.ResponseWorkoutStats": {
+            "type": "object",
+            "properties": {
+                "best_paces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BestPace"
+                    }
+                },
+                "total_distance": {
                     "type": "number"
                 },
-                "duration": {
+                "unit": {
                     "type": "string"
                 },
-                "exercises": {
+                "weekly_distance": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.ExerciseRequestEntry"
+                        "$ref": "#/definitions/models.WeeklyDistance"
                     }
+                }
+            }
+        },
+        "models.ScheduleEntry": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
                 },
-                "program_name": {
+                "day": {
+                    "type": "integer"
+                },
+                "day_name": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "program": {
+                    "type": "string"
+                },
+                "program_id": {
+                    "type": "integer"
+                },
+                "rescheduled_from": {
                     "type": "string"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "workout_id": {
+                    "type": "integer"
                 }
             }
         },
-        "models.RequestLoginUser": {
+        "models.UnmappedItem": {
             "type": "object",
             "properties": {
-                "email": {
+                "category": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string"
+                "sets": {
+                    "type": "integer"
                 }
             }
         },
@@ -1023,9 +6015,16 @@ const docTemplate = `{
                 "age": {
                     "type": "integer"
                 },
+                "deletion_scheduled_at": {
+                    "description": "DeletionScheduledAt is set while the account is waiting to be erased.",
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
+                "email_verified": {
+                    "type": "boolean"
+                },
                 "gender": {
                     "type": "string"
                 },
@@ -1038,37 +6037,35 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "reschedule_policy": {
+                    "description": "ReschedulePolicy handles missed scheduled sessions: none, next_free_day or shift.",
+                    "type": "string"
+                },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                },
+                "unit_system": {
+                    "type": "string"
+                },
                 "weight": {
                     "type": "number"
                 }
             }
         },
-        "models.Workout": {
+        "models.WeeklyDistance": {
             "type": "object",
             "properties": {
-                "calories": {
+                "distance": {
                     "type": "number"
                 },
-                "date": {
-                    "type": "string"
-                },
                 "duration": {
-                    "type": "integer"
-                },
-                "exercises": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.ExerciseEntry"
-                    }
-                },
-                "id": {
-                    "type": "integer"
+                    "type": "string"
                 },
-                "program_id": {
+                "sessions": {
                     "type": "integer"
                 },
-                "user_id": {
-                    "type": "integer"
+                "week_start": {
+                    "type": "string"
                 }
             }
         }
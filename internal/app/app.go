@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 
 	_ "github.com/artembliss/go-fitness-tracker/docs"
 	"github.com/artembliss/go-fitness-tracker/internal/handlers"
@@ -13,6 +14,8 @@ import (
 	"github.com/artembliss/go-fitness-tracker/internal/repositories"
 	"github.com/artembliss/go-fitness-tracker/internal/services"
 	"github.com/artembliss/go-fitness-tracker/pkg/logger/sl"
+	"github.com/artembliss/go-fitness-tracker/pkg/mailer"
+	"github.com/artembliss/go-fitness-tracker/pkg/ratelimit"
 	"github.com/artembliss/go-fitness-tracker/pkg/storage/postgre"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -26,6 +29,7 @@ type App struct {
 	logger *slog.Logger
 	db     *postgre.Storage
 	cache  *redis.Client
+	mailer mailer.Mailer
 }
 
 func (a *App) InitConfig(){
@@ -71,22 +75,72 @@ func (a *App) InitRedis(){
 	a.cache = rdb
 }
 
+func (a *App) InitMailer(){
+	m, err := mailer.New()
+	if err != nil{
+		a.logger.Error("failed to init mailer", sl.Err(err))
+		os.Exit(1)
+	}
 
-func (a *App) InitRouters(storage *postgre.Storage, cache *redis.Client) {
+	a.mailer = m
+}
+
+func (a *App) InitRouters(storage *postgre.Storage, cache *redis.Client, mail mailer.Mailer) {
 	db := storage.GetDB()
 
 	userRepo := repositories.NewUserRepository(db)
 	exerciseRepo := repositories.NewExerciseRepository(db)
 	programRepo := repositories.NewProgramRepository(db)
 	workoutRepo := repositories.NewWorkoutRepository(db)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	passwordResetRepo := repositories.NewPasswordResetRepository(db)
+	verificationRepo := repositories.NewEmailVerificationRepository(db)
+	bodyMetricRepo := repositories.NewBodyMetricRepository(db)
+	importRepo := repositories.NewImportRepository(db)
+	exportRepo := repositories.NewExportRepository(db)
+	accountRepo := repositories.NewAccountRepository(db)
+	calendarRepo := repositories.NewCalendarRepository(db)
+	progressionRepo := repositories.NewProgressionRepository(db)
+	libraryRepo := repositories.NewLibraryRepository(db)
+	scheduleRepo := repositories.NewScheduleRepository(db)
+	equipmentRepo := repositories.NewEquipmentRepository(db)
+
+	attemptStore := ratelimit.NewFallbackStore(ratelimit.NewRedisStore(cache, "ratelimit:"), ratelimit.NewMemoryStore())
+	loginGuard := services.NewLoginGuard(attemptStore, auditRepo, services.DefaultLoginGuardConfig())
 
-	userService := services.NewUserService(userRepo)
-	authService := services.NewAuthService(userRepo)
-	exerciseService := services.NewExerciseService(exerciseRepo, cache)
-	programService := services.NewProgramService(programRepo)
-	workoutService := services.NewWorkoutService(workoutRepo)
+	verificationService := services.NewVerificationService(userRepo, verificationRepo, mail, attemptStore)
+	userService := services.NewUserService(userRepo, bodyMetricRepo, verificationService)
+	authService := services.NewAuthService(userRepo, twoFactorRepo, loginGuard)
+	equipmentService := services.NewEquipmentService(equipmentRepo, exerciseRepo)
+	exerciseService := services.NewExerciseService(exerciseRepo, cache, equipmentService)
+	programService := services.NewProgramService(programRepo, progressionRepo)
+	generatorService := services.NewProgramGeneratorService(exerciseRepo, programService, equipmentService)
+	libraryService := services.NewLibraryService(libraryRepo, programRepo, programService)
+	progressionService := services.NewProgressionService(progressionRepo, programRepo, equipmentService)
+	scheduleService := services.NewScheduleService(scheduleRepo, programRepo, userRepo)
+	workoutService := services.NewWorkoutService(workoutRepo, userRepo, progressionService, scheduleService)
+	passwordService := services.NewPasswordService(userRepo, passwordResetRepo, mail, attemptStore)
+	bodyMetricService := services.NewBodyMetricService(bodyMetricRepo)
+	importService := services.NewImportService(importRepo, workoutService)
+	exportService := services.NewExportService(exportRepo)
+	accountService := services.NewAccountService(accountRepo, userRepo, exportService, mail)
+	calendarService := services.NewCalendarService(calendarRepo, exportRepo, userRepo, scheduleService)
+
+	// imports run in this process, so anything unfinished was cut off by a restart
+	if err := importRepo.FailUnfinishedJobs(); err != nil{
+		a.logger.Error("failed to clean up interrupted imports", sl.Err(err))
+	}
+	if err := accountRepo.FailUnfinishedDataExports(); err != nil{
+		a.logger.Error("failed to clean up interrupted data exports", sl.Err(err))
+	}
+	go accountService.RunWorker(context.Background())
+	go scheduleService.RunWorker(context.Background())
 
 	authMiddleware := middleware.JWTMiddleware(userService)
+	verifiedMiddleware := middleware.EmailVerificationMiddleware(middleware.VerificationPolicyFromEnv(),
+		"/user/verify/resend", "/user/password", "/user", "/user/data-export", "/user/data-export/download",
+		"/user/deletion/cancel")
 
 	if exerciseRepo.CheckExercisesExist() {
 		a.logger.Info("Exercises exist")
@@ -99,40 +153,133 @@ func (a *App) InitRouters(storage *postgre.Storage, cache *redis.Client) {
 	}
 
 	router := gin.Default()
+	// login throttling is keyed on ClientIP, so X-Forwarded-For is only
+	// honoured when the request comes through one of the configured proxies
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil{
+		a.logger.Error("invalid TRUSTED_PROXIES", sl.Err(err))
+		os.Exit(1)
+	}
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	router.POST("/user/register", handlers.RegisterUserHandler(userService))
 	router.POST("/user/login", handlers.LoginUserHandler(authService))
+	router.POST("/user/login/2fa", handlers.LoginTwoFactorHandler(authService))
+	router.POST("/user/password/forgot", handlers.ForgotPasswordHandler(passwordService))
+	router.POST("/user/password/reset", handlers.ResetPasswordHandler(passwordService))
+	router.GET("/user/verify", handlers.VerifyEmailHandler(verificationService))
 
 	router.GET("/exercises", handlers.GetAllExercisesHandler(exerciseService))
 	router.GET("/exercises/search", handlers.GetExerciseByParamHandler(exerciseService))
 
-	protected := router.Group("/", authMiddleware)
+	router.GET("/calendar/:token", handlers.CalendarFeedHandler(calendarService))
+
+	router.GET("/library/programs", handlers.ListLibraryProgramsHandler(libraryService))
+	router.GET("/library/programs/:id", handlers.GetLibraryProgramHandler(libraryService))
+	router.GET("/library/shared/:token", handlers.GetSharedProgramHandler(libraryService))
+
+	protected := router.Group("/", authMiddleware, verifiedMiddleware)
 	{
 		protected.GET("/user", handlers.GetUserHandler(userService))
-		protected.DELETE("/user", handlers.DeleteUserHandler(userService))
+		protected.PATCH("/user", handlers.UpdateUserHandler(userService))
+		protected.DELETE("/user", handlers.DeleteUserHandler(accountService))
+		protected.POST("/user/deletion/cancel", handlers.CancelAccountDeletionHandler(accountService))
+		protected.POST("/user/data-export", handlers.RequestDataExportHandler(accountService))
+		protected.GET("/user/data-export", handlers.GetDataExportHandler(accountService))
+		protected.GET("/user/data-export/download", handlers.DownloadDataExportHandler(accountService))
+
+		protected.POST("/user/password", handlers.ChangePasswordHandler(passwordService))
+		protected.POST("/user/verify/resend", handlers.ResendVerificationHandler(verificationService))
+
+		protected.POST("/user/2fa/enroll", handlers.EnrollTwoFactorHandler(authService))
+		protected.POST("/user/2fa/verify", handlers.VerifyTwoFactorHandler(authService))
+		protected.POST("/user/2fa/disable", handlers.DisableTwoFactorHandler(authService))
 
 		protected.POST("/programs", handlers.CreateProgramHandler(programService))
 		protected.GET("/programs", handlers.GetProgramHandler(programService))
 		protected.DELETE("/programs", handlers.DeleteProgramHandler(programService))
 		protected.PATCH("/programs", handlers.UpdateProgramHandler(programService))
+		protected.POST("/programs/generate", handlers.GenerateProgramHandler(generatorService))
+		protected.POST("/programs/:id/clone", handlers.CloneProgramHandler(programService))
+		protected.POST("/programs/:id/swap", handlers.SwapProgramExerciseHandler(programService))
+		protected.GET("/programs/:id/versions", handlers.ListProgramVersionsHandler(programService))
+		protected.GET("/programs/:id/versions/diff", handlers.DiffProgramVersionsHandler(programService))
+		protected.GET("/programs/:id/versions/:version", handlers.GetProgramVersionHandler(programService))
+		protected.PATCH("/programs/sharing", handlers.UpdateProgramSharingHandler(libraryService))
+		protected.POST("/library/programs/:id/fork", handlers.ForkProgramHandler(libraryService))
+		protected.POST("/library/programs/:id/follow", handlers.FollowProgramHandler(libraryService))
+		protected.DELETE("/library/programs/:id/follow", handlers.UnfollowProgramHandler(libraryService))
+		protected.PUT("/programs/progression", handlers.SaveProgressionRuleHandler(progressionService))
+		protected.GET("/programs/progression", handlers.GetProgressionRulesHandler(progressionService))
+		protected.DELETE("/programs/progression", handlers.DeleteProgressionRuleHandler(progressionService))
+		protected.GET("/programs/progression/history", handlers.GetProgressionHistoryHandler(progressionService))
+
+		protected.GET("/schedule", handlers.GetScheduleHandler(scheduleService))
+		protected.POST("/schedule/sessions", handlers.ScheduleSessionHandler(scheduleService))
+		protected.POST("/schedule/sessions/skip", handlers.SkipSessionHandler(scheduleService))
+		protected.DELETE("/schedule/sessions", handlers.DeleteSessionHandler(scheduleService))
+		protected.POST("/schedule/rules", handlers.CreateScheduleRuleHandler(scheduleService))
+		protected.GET("/schedule/rules", handlers.ListScheduleRulesHandler(scheduleService))
+		protected.DELETE("/schedule/rules", handlers.DeleteScheduleRuleHandler(scheduleService))
+
+		protected.POST("/metrics", handlers.CreateBodyMetricHandler(bodyMetricService))
+		protected.GET("/metrics", handlers.GetBodyMetricsHandler(bodyMetricService))
+		protected.GET("/metrics/trend", handlers.GetBodyMetricTrendHandler(bodyMetricService))
+		protected.PATCH("/metrics", handlers.UpdateBodyMetricHandler(bodyMetricService))
+		protected.DELETE("/metrics", handlers.DeleteBodyMetricHandler(bodyMetricService))
 
 		protected.POST("/workouts", handlers.CreateWorkoutHandler(workoutService))
 		protected.GET("/workouts", handlers.GetWorkoutHandler(workoutService))
+		protected.GET("/workouts/stats", handlers.GetWorkoutStatsHandler(workoutService))
+		protected.POST("/workouts/import", handlers.ImportWorkoutHandler(workoutService))
+		protected.GET("/workouts/track", handlers.GetWorkoutTrackHandler(workoutService))
 		protected.DELETE("/workouts", handlers.DeleteWorkoutHandler(workoutService))
 		protected.PATCH("/workouts", handlers.UpdateWorkoutHandler(workoutService))
+		protected.POST("/workouts/swap", handlers.SwapWorkoutExerciseHandler(workoutService))
+
+		protected.GET("/exercises/available", handlers.GetAvailableExercisesHandler(exerciseService))
+		protected.GET("/exercises/:id/alternatives", handlers.GetExerciseAlternativesHandler(exerciseService))
+
+		protected.POST("/user/equipment-profiles", handlers.CreateEquipmentProfileHandler(equipmentService))
+		protected.GET("/user/equipment-profiles", handlers.GetEquipmentProfilesHandler(equipmentService))
+		protected.PUT("/user/equipment-profiles", handlers.UpdateEquipmentProfileHandler(equipmentService))
+		protected.DELETE("/user/equipment-profiles", handlers.DeleteEquipmentProfileHandler(equipmentService))
+
+		protected.POST("/imports", handlers.StartImportHandler(importService))
+		protected.GET("/imports", handlers.GetImportHandler(importService))
+		protected.GET("/imports/mappings", handlers.GetExerciseMappingsHandler(importService))
+		protected.PUT("/imports/mappings", handlers.SaveExerciseMappingHandler(importService))
+		protected.DELETE("/imports/mappings", handlers.DeleteExerciseMappingHandler(importService))
+
+		protected.GET("/export", handlers.ExportHandler(exportService))
+
+		protected.POST("/user/calendar", handlers.CreateCalendarFeedHandler(calendarService))
+		protected.GET("/user/calendar", handlers.GetCalendarFeedHandler(calendarService))
+		protected.DELETE("/user/calendar", handlers.RevokeCalendarFeedHandler(calendarService))
 	}
 
 	a.router = router
 }
 
+// trustedProxies reads the comma-separated TRUSTED_PROXIES addresses or CIDR
+// ranges. Without it no proxy is trusted and ClientIP is the remote address.
+func trustedProxies() []string{
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ","){
+		if proxy = strings.TrimSpace(proxy); proxy != ""{
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 func (a *App) Start() {
 	a.InitConfig()
 	a.InitLogger()
 	a.InitDB()
 	a.InitRedis()
-	a.InitRouters(a.db, a.cache)
+	a.InitMailer()
+	a.InitRouters(a.db, a.cache, a.mailer)
 
 	if err := a.router.Run(":8080"); err != nil {
 		a.logger.Error("Failed to start server:", sl.Err(err))
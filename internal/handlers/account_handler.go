@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestDataExportHandler godoc
+// @Summary Request a copy of all personal data
+// @Description Builds a zip archive in the background with profile.json, workouts.json, programs.json, body_metrics.json (same columns as GET /export, in the user's unit), tokens.json (token metadata, never the secrets) and audit_log.json. Poll GET /user/data-export with the returned id and download it from GET /user/data-export/download once completed. Archives are kept for 7 days. While an archive is being built it is returned instead of starting another.
+// @Security BearerAuth
+// @Tags Users
+// @Produce json
+// @Success 202 {object} models.DataExport
+// @Failure 500 {object} map[string]string
+// @Router /user/data-export [post]
+func RequestDataExportHandler(s *services.AccountService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		dataExport, err := s.RequestDataExport(userID)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, dataExport)
+	}
+}
+
+// GetDataExportHandler godoc
+// @Summary Get a personal data archive
+// @Description Status of a "download my data" archive
+// @Security BearerAuth
+// @Tags Users
+// @Produce json
+// @Param id query int true "Data export ID"
+// @Success 200 {object} models.DataExport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/data-export [get]
+func GetDataExportHandler(s *services.AccountService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		exportID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data export id"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		dataExport, err := s.GetDataExport(exportID, userID)
+		if err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "data export not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dataExport)
+	}
+}
+
+// DownloadDataExportHandler godoc
+// @Summary Download a personal data archive
+// @Description Downloads a completed archive that hasn't expired
+// @Security BearerAuth
+// @Tags Users
+// @Produce application/zip
+// @Param id query int true "Data export ID"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/data-export/download [get]
+func DownloadDataExportHandler(s *services.AccountService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		exportID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid data export id"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		path, err := s.GetDataExportFile(exportID, userID)
+		if err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.FileAttachment(path, fmt.Sprintf("fitness-data-%d.zip", exportID))
+	}
+}
+
+// CancelAccountDeletionHandler godoc
+// @Summary Cancel a scheduled account deletion
+// @Description Keeps the account if its deletion grace period hasn't ended yet
+// @Security BearerAuth
+// @Tags Users
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /user/deletion/cancel [post]
+func CancelAccountDeletionHandler(s *services.AccountService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		if err := s.CancelDeletion(userID); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "account deletion cancelled"})
+	}
+}
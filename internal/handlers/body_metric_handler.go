@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBodyMetricHandler godoc
+// @Summary Log body measurements
+// @Description Add a body metrics entry (weight, body fat %, waist, chest, arms, thighs, resting heart rate). Any subset may be sent
+// @Security BearerAuth
+// @Tags Metrics
+// @Accept json
+// @Produce json
+// @Param metric body models.RequestBodyMetric true "Measurements"
+// @Success 200 {integer} int "Created metric ID"
+// @Failure 400 {object} map[string]string
+// @Router /metrics [post]
+func CreateBodyMetricHandler(s *services.BodyMetricService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestBodyMetric
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		unit, err := resolveUnit(ctx, req.Unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		metricID, err := s.CreateMetric(userID, req, unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, metricID)
+	}
+}
+
+// GetBodyMetricsHandler godoc
+// @Summary Get body metrics
+// @Description Get one entry by id, or the history between from and to (defaults to the last year)
+// @Security BearerAuth
+// @Tags Metrics
+// @Produce json
+// @Param id query int false "Metric ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param unit query string false "Weight unit of the response (kg or lb)"
+// @Success 200 {array} models.BodyMetric
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /metrics [get]
+func GetBodyMetricsHandler(s *services.BodyMetricService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if idStr := ctx.Query("id"); idStr != ""{
+			metricID, err := strconv.Atoi(idStr)
+			if err != nil{
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid metric id"})
+				return
+			}
+			metric, err := s.GetMetric(metricID, userID, unit)
+			if err != nil{
+				ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusOK, metric)
+			return
+		}
+
+		from, to, err := parseDateRange(ctx, time.Now().AddDate(-1, 0, 0))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		metrics, err := s.GetMetrics(userID, from, to, unit)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, metrics)
+	}
+}
+
+// GetBodyMetricTrendHandler godoc
+// @Summary Get a smoothed body metric trend
+// @Description Trailing moving average of one metric over a window of days
+// @Security BearerAuth
+// @Tags Metrics
+// @Produce json
+// @Param metric query string false "weight, body_fat, waist, chest, arms, thighs or resting_heart_rate (default weight)"
+// @Param window query int false "Window in days (default 7)"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param unit query string false "Weight unit of the response (kg or lb)"
+// @Success 200 {object} models.ResponseBodyMetricTrend
+// @Failure 400 {object} map[string]string
+// @Router /metrics/trend [get]
+func GetBodyMetricTrendHandler(s *services.BodyMetricService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		metric := ctx.DefaultQuery("metric", "weight")
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		window := 0
+		if windowStr := ctx.Query("window"); windowStr != ""{
+			parsed, err := strconv.Atoi(windowStr)
+			if err != nil || parsed <= 0{
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid window"})
+				return
+			}
+			window = parsed
+		}
+
+		from, to, err := parseDateRange(ctx, time.Now().AddDate(0, -3, 0))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		trend, err := s.GetTrend(userID, metric, window, from, to, unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, trend)
+	}
+}
+
+// UpdateBodyMetricHandler godoc
+// @Summary Update a body metrics entry
+// @Description Replace the measurements of an entry. The entry keeps its measured_at unless the request sets a new one.
+// @Security BearerAuth
+// @Tags Metrics
+// @Accept json
+// @Produce json
+// @Param id query int true "Metric ID"
+// @Param metric body models.RequestBodyMetric true "Measurements"
+// @Success 200 {integer} int "Updated metric ID"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /metrics [patch]
+func UpdateBodyMetricHandler(s *services.BodyMetricService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestBodyMetric
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		metricID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid metric id"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		unit, err := resolveUnit(ctx, req.Unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		updatedID, err := s.UpdateMetric(metricID, userID, req, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "metric not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, updatedID)
+	}
+}
+
+// DeleteBodyMetricHandler godoc
+// @Summary Delete a body metrics entry
+// @Security BearerAuth
+// @Tags Metrics
+// @Produce json
+// @Param id query int true "Metric ID"
+// @Success 200 {integer} int "Deleted metric ID"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /metrics [delete]
+func DeleteBodyMetricHandler(s *services.BodyMetricService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		metricID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid metric id"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		deletedID, err := s.DeleteMetric(metricID, userID)
+		if err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, deletedID)
+	}
+}
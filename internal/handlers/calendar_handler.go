@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/artembliss/go-fitness-tracker/pkg/ical"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateCalendarFeedHandler godoc
+// @Summary Create or regenerate the calendar feed URL
+// @Description Returns a secret iCalendar URL to subscribe to in Google or Apple Calendar. The URL is shown only once; calling this again replaces it and the old URL stops working.
+// @Security BearerAuth
+// @Tags Calendar
+// @Produce json
+// @Success 200 {object} models.ResponseCalendarFeed
+// @Failure 500 {object} map[string]string
+// @Router /user/calendar [post]
+func CreateCalendarFeedHandler(s *services.CalendarService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		resp, err := s.CreateFeed(userID)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// GetCalendarFeedHandler godoc
+// @Summary Get the calendar feed
+// @Description Tells whether the user has a calendar feed and when its URL was created
+// @Security BearerAuth
+// @Tags Calendar
+// @Produce json
+// @Success 200 {object} models.CalendarFeed
+// @Failure 404 {object} map[string]string
+// @Router /user/calendar [get]
+func GetCalendarFeedHandler(s *services.CalendarService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		feed, err := s.GetFeed(userID)
+		if err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "calendar feed not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, feed)
+	}
+}
+
+// RevokeCalendarFeedHandler godoc
+// @Summary Revoke the calendar feed
+// @Description The feed URL stops working immediately
+// @Security BearerAuth
+// @Tags Calendar
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/calendar [delete]
+func RevokeCalendarFeedHandler(s *services.CalendarService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		if err := s.RevokeFeed(userID); err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "calendar feed revoked"})
+	}
+}
+
+// CalendarFeedHandler godoc
+// @Summary iCalendar feed
+// @Description Public feed behind the secret URL from POST /user/calendar. Each completed workout of the last year is an all-day event whose summary lists the program and exercises.
+// @Tags Calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token followed by .ics"
+// @Success 200 {string} string
+// @Failure 404 {object} map[string]string
+// @Router /calendar/{token} [get]
+func CalendarFeedHandler(s *services.CalendarService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		token := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+		cal, err := s.BuildFeed(token)
+		if err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "calendar feed not found"})
+			return
+		}
+
+		ctx.Header("Content-Type", "text/calendar; charset=utf-8")
+		ctx.Header("Cache-Control", "private, max-age=900")
+		ctx.Status(http.StatusOK)
+		if err := ical.Write(ctx.Writer, *cal); err != nil{
+			_ = ctx.Error(err)
+		}
+	}
+}
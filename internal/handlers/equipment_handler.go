@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateEquipmentProfileHandler godoc
+// @Summary Create an equipment profile
+// @Description Save a named place to train (home gym, commercial gym, travel) with the catalog equipment available there and the smallest plate and dumbbell increments. Body weight exercises are always available. The first profile, or one created with default, becomes the default profile used by exercise search, alternatives, program generation and progression increments.
+// @Security BearerAuth
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param profile body models.RequestEquipmentProfile true "Equipment profile"
+// @Success 201 {integer} int "Created profile ID"
+// @Failure 400 {object} map[string]string
+// @Router /user/equipment-profiles [post]
+func CreateEquipmentProfileHandler(s *services.EquipmentService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestEquipmentProfile
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, req.Unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		profileID, err := s.CreateProfile(ctx.GetInt("userID"), req, unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, profileID)
+	}
+}
+
+// GetEquipmentProfilesHandler godoc
+// @Summary List equipment profiles
+// @Description The user's equipment profiles, oldest first, with increments in the requested unit
+// @Security BearerAuth
+// @Tags Equipment
+// @Produce json
+// @Param unit query string false "Weight unit of the response (kg or lb)"
+// @Success 200 {array} models.ResponseEquipmentProfile
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /user/equipment-profiles [get]
+func GetEquipmentProfilesHandler(s *services.EquipmentService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		profiles, err := s.ListProfiles(ctx.GetInt("userID"), unit)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, profiles)
+	}
+}
+
+// UpdateEquipmentProfileHandler godoc
+// @Summary Update an equipment profile
+// @Description Replace the name, equipment and increments of a profile. default makes it the default profile; to move the default away, make another profile the default
+// @Security BearerAuth
+// @Tags Equipment
+// @Accept json
+// @Produce json
+// @Param id query int true "Profile ID"
+// @Param profile body models.RequestEquipmentProfile true "Equipment profile"
+// @Success 200 {integer} int "Updated profile ID"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/equipment-profiles [put]
+func UpdateEquipmentProfileHandler(s *services.EquipmentService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestEquipmentProfile
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		profileID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, req.Unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		updatedID, err := s.UpdateProfile(profileID, ctx.GetInt("userID"), req, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "equipment profile not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, updatedID)
+	}
+}
+
+// DeleteEquipmentProfileHandler godoc
+// @Summary Delete an equipment profile
+// @Description When the default profile is deleted, the oldest remaining profile becomes the default
+// @Security BearerAuth
+// @Tags Equipment
+// @Produce json
+// @Param id query int true "Profile ID"
+// @Success 200 {integer} int "Deleted profile ID"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /user/equipment-profiles [delete]
+func DeleteEquipmentProfileHandler(s *services.EquipmentService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		profileID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		deletedID, err := s.DeleteProfile(profileID, ctx.GetInt("userID"))
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "equipment profile not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, deletedID)
+	}
+}
@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
+const defaultAlternativesLimit = 10
+
 // GetAllExercisesHandler godoc
 // @Summary Get all exercises
 // @Description Retrieve a list of all available exercises
@@ -85,3 +92,100 @@ func GetExerciseByParamHandler(s *services.ExerciseService) gin.HandlerFunc{
 	}
 }
 
+
+// GetExerciseAlternativesHandler godoc
+// @Summary Suggest substitutes for an exercise
+// @Description Exercises training the same muscle group, best match first: the score adds 3 for the same equipment (2 for the same kind, e.g. barbell and dumbbell), 2 for the same type (1 for another strength type) and 2 for the same difficulty (1 for one level off). Only exercises using the available equipment or body weight are suggested: the equipment parameter if set, otherwise the equipment profile given by profile or the user's default profile.
+// @Security BearerAuth
+// @Tags Exercises
+// @Produce json
+// @Param id path int true "Exercise ID"
+// @Param equipment query string false "Comma-separated available equipment, e.g. dumbbell,bands"
+// @Param profile query int false "Equipment profile ID, defaults to the user's default profile"
+// @Param limit query int false "Number of suggestions, 1 to 50 (default 10)"
+// @Success 200 {array} models.ExerciseAlternative
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /exercises/{id}/alternatives [get]
+func GetExerciseAlternativesHandler(s *services.ExerciseService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		exerciseID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultAlternativesLimit)))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+
+		profileID, err := parseProfileID(ctx)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var equipment []string
+		if raw := ctx.Query("equipment"); raw != ""{
+			equipment = strings.Split(raw, ",")
+		}
+
+		alternatives, err := s.GetAlternatives(ctx, ctx.GetInt("userID"), exerciseID, equipment, profileID, limit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "exercise or equipment profile not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, alternatives)
+	}
+}
+
+
+// GetAvailableExercisesHandler godoc
+// @Summary Search exercises available with an equipment profile
+// @Description Catalog exercises that need only the equipment of the profile given by profile, or of the user's default profile, or body weight. Without profiles the whole catalog is searched. name matches any part of the exercise name; the other filters match exactly. Filters combine.
+// @Security BearerAuth
+// @Tags Exercises
+// @Produce json
+// @Param profile query int false "Equipment profile ID, defaults to the user's default profile"
+// @Param name query string false "Part of the exercise name"
+// @Param type query string false "Exercise type"
+// @Param muscle query string false "Target muscle group"
+// @Param difficulty query string false "Exercise difficulty level"
+// @Success 200 {array} models.Exercise
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /exercises/available [get]
+func GetAvailableExercisesHandler(s *services.ExerciseService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		profileID, err := parseProfileID(ctx)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		search := models.ExerciseSearch{
+			Name: ctx.Query("name"),
+			Type: ctx.Query("type"),
+			MuscleGroup: ctx.Query("muscle"),
+			Difficulty: ctx.Query("difficulty"),
+		}
+
+		exercises, err := s.GetAvailableExercises(ctx, ctx.GetInt("userID"), profileID, search)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "equipment profile not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, exercises)
+	}
+}
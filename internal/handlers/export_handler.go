@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/artembliss/go-fitness-tracker/pkg/export"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler godoc
+// @Summary Export workouts, programs and body metrics
+// @Description Streams the user's data as a download. Workouts and body metrics are limited to from/to (defaults to all history); programs are always exported in full. Weights use the requested unit, distances km or mi, elevation m or ft, lengths cm.
+// @Description
+// @Description Sections and their columns, in this order:
+// @Description workouts: workout_id, date, program, workout_duration_seconds, calories, exercise, exercise_type, set_number, reps, weight, weight_unit, distance, distance_unit, duration_seconds, elevation_gain, elevation_unit, avg_heart_rate, max_heart_rate (one row per set; cardio entries and empty workouts take one row)
+// @Description programs: program_id, program, created_at, exercise, sets, reps, weight, weight_unit, phase, week, day, reps_max, percent_e1rm (one row per exercise slot)
+// @Description body_metrics: measured_at, body_weight, weight_unit, body_fat, waist, chest, arms, thighs, resting_heart_rate
+// @Description
+// @Description csv is a single table: a record_type column with the section name followed by the union of the columns above. json is an object with one array per section. xlsx has one sheet per section.
+// @Security BearerAuth
+// @Tags Export
+// @Produce octet-stream
+// @Param format query string true "csv, json or xlsx"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param unit query string false "Weight unit (kg or lb), defaults to the user's preference"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Router /export [get]
+func ExportHandler(s *services.ExportService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		format := ctx.Query("format")
+		if err := export.ValidateFormat(format); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		from, to, err := parseDateRange(ctx, time.Time{})
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		filename := fmt.Sprintf("fitness-export-%s.%s", time.Now().Format(dateLayout), format)
+		ctx.Header("Content-Type", export.ContentType(format))
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		ctx.Status(http.StatusOK)
+
+		// the status line is already sent, so a failure can only cut the download short
+		if err := s.Export(ctx.Writer, format, userID, from, to, unit); err != nil{
+			_ = ctx.Error(err)
+		}
+	}
+}
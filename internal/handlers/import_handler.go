@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const maxHistoryFileSize = 50 << 20
+
+// StartImportHandler godoc
+// @Summary Import workout history from Strong or Hevy
+// @Description Upload a Strong or Hevy CSV export. The file is checked right away and imported in the background; poll GET /imports with the returned id for progress. With dry_run nothing is saved and the finished job contains a preview of the workouts that would be created. Exercise names are matched against the user's mappings first, then the catalog (case-insensitive); unmatched ones are listed under unmapped.
+// @Security BearerAuth
+// @Tags Imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV export"
+// @Param dry_run formData bool false "Preview without saving"
+// @Param unit formData string false "Weight unit of exports that don't state it (kg or lb), defaults to the user's preference"
+// @Success 202 {object} models.ImportJob
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 413 {object} map[string]string
+// @Router /imports [post]
+func StartImportHandler(s *services.ImportService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		data, status, err := readUploadedFile(ctx, "file", maxHistoryFileSize)
+		if err != nil{
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		dryRun := false
+		if value := ctx.PostForm("dry_run"); value != ""{
+			dryRun, err = strconv.ParseBool(value)
+			if err != nil{
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+				return
+			}
+		}
+
+		unit, err := resolveUnit(ctx, ctx.PostForm("unit"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		job, err := s.StartImport(userID, data, dryRun, unit)
+		if err != nil{
+			var dupErr *services.DuplicateHistoryImportError
+			if errors.As(err, &dupErr){
+				ctx.JSON(http.StatusConflict, gin.H{"error": dupErr.Error(), "import_id": dupErr.JobID})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, job)
+	}
+}
+
+// GetImportHandler godoc
+// @Summary Get an import job
+// @Description Status, progress and results of a history import
+// @Security BearerAuth
+// @Tags Imports
+// @Produce json
+// @Param id query int true "Import ID"
+// @Success 200 {object} models.ImportJob
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /imports [get]
+func GetImportHandler(s *services.ImportService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		jobID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid import id"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		job, err := s.GetJob(jobID, userID)
+		if err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "import not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, job)
+	}
+}
+
+// GetExerciseMappingsHandler godoc
+// @Summary List exercise name mappings
+// @Description Names from other apps and the catalog exercises they are imported as
+// @Security BearerAuth
+// @Tags Imports
+// @Produce json
+// @Success 200 {array} models.ExerciseNameMapping
+// @Failure 500 {object} map[string]string
+// @Router /imports/mappings [get]
+func GetExerciseMappingsHandler(s *services.ImportService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		mappings, err := s.GetMappings(userID)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, mappings)
+	}
+}
+
+// SaveExerciseMappingHandler godoc
+// @Summary Map an exercise name to a catalog exercise
+// @Description Creates or replaces the mapping for a name (case-insensitive); used by later imports
+// @Security BearerAuth
+// @Tags Imports
+// @Accept json
+// @Produce json
+// @Param mapping body models.RequestExerciseNameMapping true "Mapping"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /imports/mappings [put]
+func SaveExerciseMappingHandler(s *services.ImportService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestExerciseNameMapping
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		if err := s.SaveMapping(userID, req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "mapping saved"})
+	}
+}
+
+// DeleteExerciseMappingHandler godoc
+// @Summary Delete an exercise name mapping
+// @Security BearerAuth
+// @Tags Imports
+// @Produce json
+// @Param source_name query string true "Name used by the other app"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /imports/mappings [delete]
+func DeleteExerciseMappingHandler(s *services.ImportService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		sourceName := ctx.Query("source_name")
+		if sourceName == ""{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "source_name is not set"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		if err := s.DeleteMapping(userID, sourceName); err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "mapping deleted"})
+	}
+}
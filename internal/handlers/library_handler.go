@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultLibraryLimit = 20
+
+// UpdateProgramSharingHandler godoc
+// @Summary Change who can see a program
+// @Description Set a program's visibility (private, unlisted or public) and its tags, such as strength, hypertrophy or beginner. Unlisted and public programs get a share link; switching back to private revokes it. Omitted fields are left unchanged.
+// @Security BearerAuth
+// @Tags Programs
+// @Accept json
+// @Produce json
+// @Param id query int true "Program ID"
+// @Param sharing body models.RequestProgramSharing true "Visibility and tags"
+// @Success 200 {object} models.ResponseProgramSharing
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/sharing [patch]
+func UpdateProgramSharingHandler(s *services.LibraryService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestProgramSharing
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		programID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		sharing, err := s.SetSharing(ctx.GetInt("userID"), programID, req)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, sharing)
+	}
+}
+
+// ListLibraryProgramsHandler godoc
+// @Summary Browse public programs
+// @Description Search the public program library, most followed first. Programs must carry every tag given.
+// @Tags Library
+// @Produce json
+// @Param search query string false "Case-insensitive part of the program name"
+// @Param tags query string false "Comma-separated tags, e.g. strength,beginner"
+// @Param limit query int false "Page size, 1 to 100 (default 20)"
+// @Param offset query int false "Number of programs to skip"
+// @Success 200 {array} models.LibraryProgramSummary
+// @Failure 400 {object} map[string]string
+// @Router /library/programs [get]
+func ListLibraryProgramsHandler(s *services.LibraryService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultLibraryLimit)))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offset"})
+			return
+		}
+
+		var tags []string
+		if raw := ctx.Query("tags"); raw != ""{
+			tags = strings.Split(raw, ",")
+		}
+
+		programs, err := s.ListPublicPrograms(ctx.Query("search"), tags, limit, offset)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, programs)
+	}
+}
+
+// GetLibraryProgramHandler godoc
+// @Summary Get a public program
+// @Description Retrieve a public program with its author, followers and forks count
+// @Tags Library
+// @Produce json
+// @Param id path int true "Program ID"
+// @Param unit query string false "Weight unit of the response (kg or lb)"
+// @Success 200 {object} models.ResponseLibraryProgram
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /library/programs/{id} [get]
+func GetLibraryProgramHandler(s *services.LibraryService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		program, err := s.GetPublicProgram(ctx.GetInt("userID"), programID, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, program)
+	}
+}
+
+// GetSharedProgramHandler godoc
+// @Summary Open a program share link
+// @Description Retrieve an unlisted or public program by the token of its share link
+// @Tags Library
+// @Produce json
+// @Param token path string true "Share token"
+// @Param unit query string false "Weight unit of the response (kg or lb)"
+// @Success 200 {object} models.ResponseLibraryProgram
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /library/shared/{token} [get]
+func GetSharedProgramHandler(s *services.LibraryService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		program, err := s.GetSharedProgram(ctx.GetInt("userID"), ctx.Param("token"), unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, program)
+	}
+}
+
+// ForkProgramHandler godoc
+// @Summary Fork a library program
+// @Description Copy a public program, or an unlisted one with its share token, into the user's account as a private program. The copy keeps the name and author of the original as attribution. The body is optional.
+// @Security BearerAuth
+// @Tags Library
+// @Accept json
+// @Produce json
+// @Param id path int true "Program ID"
+// @Param fork body models.RequestForkProgram false "Name of the copy and share token"
+// @Success 201 {integer} int "Created Program ID"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /library/programs/{id}/fork [post]
+func ForkProgramHandler(s *services.LibraryService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		var req models.RequestForkProgram
+		if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF){
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		createdID, err := s.ForkProgram(ctx.GetInt("userID"), programID, req)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, createdID)
+	}
+}
+
+// FollowProgramHandler godoc
+// @Summary Follow a public program
+// @Security BearerAuth
+// @Tags Library
+// @Produce json
+// @Param id path int true "Program ID"
+// @Success 200 {object} models.ResponseProgramFollowers
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /library/programs/{id}/follow [post]
+func FollowProgramHandler(s *services.LibraryService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		followers, err := s.Follow(ctx.GetInt("userID"), programID)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, followers)
+	}
+}
+
+// UnfollowProgramHandler godoc
+// @Summary Stop following a program
+// @Security BearerAuth
+// @Tags Library
+// @Produce json
+// @Param id path int true "Program ID"
+// @Success 200 {object} models.ResponseProgramFollowers
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /library/programs/{id}/follow [delete]
+func UnfollowProgramHandler(s *services.LibraryService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		followers, err := s.Unfollow(ctx.GetInt("userID"), programID)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, followers)
+	}
+}
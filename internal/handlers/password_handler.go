@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ChangePasswordHandler godoc
+// @Summary Change password
+// @Description Change the password of the authenticated user. All other sessions are revoked and a new token is returned
+// @Security BearerAuth
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param password body models.RequestChangePassword true "Current and new password"
+// @Success 200 {object} map[string]string "New JWT Token"
+// @Failure 400 {object} map[string]string
+// @Router /user/password [post]
+func ChangePasswordHandler(s *services.PasswordService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestChangePassword
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		token, err := s.ChangePassword(userID, req)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"token": token})
+	}
+}
+
+// ForgotPasswordHandler godoc
+// @Summary Request a password reset
+// @Description Send a single-use reset token to the email address if an account exists. The response is the same either way and the email is sent in the background. Rate limited per IP and per email
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param email body models.RequestForgotPassword true "Account email"
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /user/password/forgot [post]
+func ForgotPasswordHandler(s *services.PasswordService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestForgotPassword
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if err := s.RequestPasswordReset(ctx, req.Email, ctx.ClientIP()); err != nil{
+			var limited *services.ResetLimitedError
+			if errors.As(err, &limited){
+				retryAfter := int(limited.RetryAfter.Seconds()) + 1
+				ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+				ctx.JSON(http.StatusTooManyRequests, gin.H{"error": limited.Error(), "retry_after": retryAfter})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reset email"})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, gin.H{"status": "If the account exists, a reset email has been sent"})
+	}
+}
+
+// ResetPasswordHandler godoc
+// @Summary Reset password with a token
+// @Description Set a new password using a token from the reset email. The token is single-use and revokes all sessions
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param reset body models.RequestResetPassword true "Reset token and new password"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /user/password/reset [post]
+func ResetPasswordHandler(s *services.PasswordService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestResetPassword
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if err := s.ResetPassword(req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "Password has been reset"})
+	}
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -11,7 +14,7 @@ import (
 
 // CreateProgramHandler godoc
 // @Summary Create a new workout program
-// @Description Create a program for a user with a list of exercises
+// @Description Create a program either from a flat list of exercises or from phases made of weeks, days and ordered exercise slots. A slot's set scheme is given as a shorthand ("5x5", "3x8-12", "5x3@85%" of the estimated one-rep max) or through sets, reps, reps_max, weight and percent_e1rm. Exercises are done in the order listed; consecutive exercises sharing a group label form a superset (two exercises), giant set (three or more) or circuit, described in the day's groups with rounds and rest between rounds.
 // @Security BearerAuth
 // @Tags Programs
 // @Accept json
@@ -30,43 +33,78 @@ func CreateProgramHandler(s *services.ProgramService) gin.HandlerFunc{
 			return	
 		}
 
-		nameToID, err := s.GetNameToID(programCreate.Exercises)
+		unit, err := resolveUnit(ctx, programCreate.Unit)
 		if err != nil{
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to find exercises in storage"})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		exercisesToSave, notFound := s.MapToDBExercises(programCreate.Exercises, nameToID)
-		if len(notFound) > 0 {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "some exercises not found"})		
+		createdID, err := s.CreateProgram(ctx.GetInt("userID"), programCreate, unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})		
 			return
 		}
+		
+		ctx.JSON(http.StatusOK, createdID)
+	}
+}
 
-		program := models.Program{
-			UserID: ctx.GetInt("userID"),
-			Name: programCreate.Name,
-			Exercises: exercisesToSave,	
+// GenerateProgramHandler godoc
+// @Summary Generate a program
+// @Description Build a balanced one-week program from the exercise catalog for a goal (strength, hypertrophy or endurance), 1-6 days per week and a session length. The split follows the days per week (full body, push/pull/legs, upper/lower), exercises are matched to the experience level (difficulty), the available equipment (from the request, otherwise from the equipment profile given by profile_id or the default profile) and the goal (exercise type), and endurance days are laid out as circuits. The same request with the same seed always yields the same program; the seed used is returned. With preview the program is returned without being saved.
+// @Security BearerAuth
+// @Tags Programs
+// @Accept json
+// @Produce json
+// @Param request body models.RequestGenerateProgram true "Generator settings"
+// @Success 201 {object} models.ResponseGeneratedProgram
+// @Success 200 {object} models.ResponseGeneratedProgram "Preview"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/generate [post]
+func GenerateProgramHandler(s *services.ProgramGeneratorService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestGenerateProgram
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
 		}
 
-		createdID, err := s.CreateProgram(program)
+		unit, err := resolveUnit(ctx, req.Unit)
 		if err != nil{
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})		
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		
-		ctx.JSON(http.StatusOK, createdID)
+
+		generated, err := s.GenerateProgram(ctx.GetInt("userID"), req, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "equipment profile not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if req.Preview{
+			ctx.JSON(http.StatusOK, generated)
+			return
+		}
+		ctx.JSON(http.StatusCreated, generated)
 	}
 }
 
 // GetProgramHandler godoc
-// @Summary Get a program by ID
-// @Description Retrieve a user's specific program by program ID
+// @Summary Get a program by ID or list programs
+// @Description Retrieve a user's specific program by program ID. Phases are always returned; the flat exercises list is filled for single-day programs. Percentage slots get a target_weight from the best estimated one-rep max in the user's history. Without id, returns the user's programs as an array of models.ProgramSummary (exercise count and the date of the last workout logged for each), most recently used first, optionally filtered by search.
 // @Security BearerAuth
 // @Tags Programs
 // @Accept json
 // @Produce json
-// @Param id query int true "Program ID"
-// @Success 200 {object} models.Program
+// @Param id query int false "Program ID"
+// @Param search query string false "Case-insensitive part of the program name, for the list"
+// @Param unit query string false "Weight unit of the response (kg or lb), defaults to the user's preference"
+// @Success 200 {object} models.RequestGetProgram
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /programs [get]
@@ -76,7 +114,12 @@ func GetProgramHandler(s *services.ProgramService) gin.HandlerFunc{
 
 		programIdStr := ctx.Query("id")
 		if len(programIdStr) == 0{
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "program id not set"})
+			programs, err := s.ListPrograms(userID, ctx.Query("search"))
+			if err != nil{
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			ctx.JSON(http.StatusOK, programs)
 			return
 		}
 
@@ -86,7 +129,13 @@ func GetProgramHandler(s *services.ProgramService) gin.HandlerFunc{
 			return
 		}
 
-		programs, err := s.GetProgram(programID, userID)
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		programs, err := s.GetProgram(programID, userID, unit)
 		if err != nil{
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -98,7 +147,7 @@ func GetProgramHandler(s *services.ProgramService) gin.HandlerFunc{
 
 // UpdateProgramHandler godoc
 // @Summary Update an existing workout program
-// @Description Update a program's name and exercises by its ID
+// @Description Replace a program's name and structure by its ID; accepts the same body as POST /programs. The previous state stays available under /programs/{id}/versions.
 // @Security BearerAuth
 // @Tags Programs
 // @Accept json
@@ -125,34 +174,238 @@ func UpdateProgramHandler(s *services.ProgramService) gin.HandlerFunc{
 			return
 		}
 
-		nameToID, err := s.GetNameToID(programUpdate.Exercises)
+		unit, err := resolveUnit(ctx, programUpdate.Unit)
 		if err != nil{
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to find exercises in storage"})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		exercisesToSave, notFound := s.MapToDBExercises(programUpdate.Exercises, nameToID)
-		if len(notFound) > 0 {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "some exercises not found"})		
+		ID, err := s.UpdateProgram(ctx.GetInt("userID"), programID, programUpdate, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})		
 			return
 		}
+		
+		ctx.JSON(http.StatusOK, ID)
+	}
+}
 
-		program := models.Program{
-			UserID: ctx.GetInt("userID"),
-			Name: programUpdate.Name,
-			Exercises: exercisesToSave,	
+// CloneProgramHandler godoc
+// @Summary Clone a workout program
+// @Description Copy one of the user's programs, with all phases, weeks, days and slots, into a new program. The body is optional.
+// @Security BearerAuth
+// @Tags Programs
+// @Accept json
+// @Produce json
+// @Param id path int true "Program ID"
+// @Param program body models.RequestCloneProgram false "Name of the copy"
+// @Success 201 {integer} int "Created Program ID"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/{id}/clone [post]
+func CloneProgramHandler(s *services.ProgramService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		var req models.RequestCloneProgram
+		if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF){
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
 		}
 
-		ID, err := s.UpdateProgram(program, programID)
+		createdID, err := s.CloneProgram(ctx.GetInt("userID"), programID, req.Name)
 		if err != nil{
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})		
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		
-		ctx.JSON(http.StatusOK, ID)
+
+		ctx.JSON(http.StatusCreated, createdID)
+	}
+}
+
+// SwapProgramExerciseHandler godoc
+// @Summary Swap an exercise in a program
+// @Description Replace an exercise with another one (see GET /exercises/{id}/alternatives) in every slot of the program, or only in the given phase, week and day. The slots keep their set schemes and remember the exercise they replaced (substituted_for); the change is stored as a new program version. Cardio and strength exercises can not replace each other. When the exercise leaves the program entirely, its progression rule and current target move to the replacement; the swap is rejected if the replacement already has a rule.
+// @Security BearerAuth
+// @Tags Programs
+// @Accept json
+// @Produce json
+// @Param id path int true "Program ID"
+// @Param swap body models.RequestSwapProgramExercise true "Exercise to replace and its replacement"
+// @Success 200 {object} models.ResponseSwapExercise
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/{id}/swap [post]
+func SwapProgramExerciseHandler(s *services.ProgramService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		var req models.RequestSwapProgramExercise
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		swapped, err := s.SwapExercise(ctx.GetInt("userID"), programID, req)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, models.ResponseSwapExercise{Swapped: swapped})
+	}
+}
+
+// ListProgramVersionsHandler godoc
+// @Summary List the versions of a program
+// @Description Every create or update that changes a program's name or structure stores an immutable version. Versions are listed newest first with the number of workouts logged against each.
+// @Security BearerAuth
+// @Tags Programs
+// @Produce json
+// @Param id path int true "Program ID"
+// @Success 200 {array} models.ProgramVersionSummary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/{id}/versions [get]
+func ListProgramVersionsHandler(s *services.ProgramService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		versions, err := s.ListVersions(ctx.GetInt("userID"), programID)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, versions)
 	}
 }
 
+// GetProgramVersionHandler godoc
+// @Summary Get a program version
+// @Description Retrieve a program as it was in the given version
+// @Security BearerAuth
+// @Tags Programs
+// @Produce json
+// @Param id path int true "Program ID"
+// @Param version path int true "Version number"
+// @Param unit query string false "Weight unit of the response (kg or lb), defaults to the user's preference"
+// @Success 200 {object} models.ResponseProgramVersion
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/{id}/versions/{version} [get]
+func GetProgramVersionHandler(s *services.ProgramService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+		version, err := strconv.Atoi(ctx.Param("version"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		programVersion, err := s.GetVersion(ctx.GetInt("userID"), programID, version, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program version not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, programVersion)
+	}
+}
+
+// DiffProgramVersionsHandler godoc
+// @Summary Compare two program versions
+// @Description List the days and exercise slots that were added, removed or changed between two versions. Days are matched by phase, week and day position. By default the current version is compared with the one before it.
+// @Security BearerAuth
+// @Tags Programs
+// @Produce json
+// @Param id path int true "Program ID"
+// @Param from query int false "Older version number"
+// @Param to query int false "Newer version number, defaults to the current version"
+// @Param unit query string false "Weight unit of the response (kg or lb), defaults to the user's preference"
+// @Success 200 {object} models.ProgramVersionDiff
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/{id}/versions/diff [get]
+func DiffProgramVersionsHandler(s *services.ProgramService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+		from, err := strconv.Atoi(ctx.DefaultQuery("from", "0"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid from version"})
+			return
+		}
+		to, err := strconv.Atoi(ctx.DefaultQuery("to", "0"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid to version"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		diff, err := s.DiffVersions(ctx.GetInt("userID"), programID, from, to, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program version not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, diff)
+	}
+}
 
 // DeleteProgramHandler godoc
 // @Summary Delete a workout program
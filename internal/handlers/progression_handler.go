@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SaveProgressionRuleHandler godoc
+// @Summary Set the progression rule of a program exercise
+// @Description Create or replace the rule that moves an exercise's targets after each workout logged for the program. linear adds increment once every set hits the target reps; double adds reps up to the top of the slot's rep range, then adds increment and drops back to the bottom; wave cycles through wave_percents of a training max and raises it by increment after each completed wave. Without increment, linear and double rules use the plate increment (barbell, EZ bar) or dumbbell increment (dumbbell, kettlebells) of the default equipment profile. After deload_after missed sessions in a row (0 disables it) the load drops by deload_percent (default 10). Saving a rule restarts its progression from start_weight, which defaults to the slot's weight or the estimated one-rep max.
+// @Security BearerAuth
+// @Tags Programs
+// @Accept json
+// @Produce json
+// @Param id query int true "Program ID"
+// @Param rule body models.RequestProgressionRule true "Progression rule"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/progression [put]
+func SaveProgressionRuleHandler(s *services.ProgressionService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestProgressionRule
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		programID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, req.Unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := s.SaveRule(ctx.GetInt("userID"), programID, req, unit); err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "progression rule saved"})
+	}
+}
+
+// GetProgressionRulesHandler godoc
+// @Summary Get the progression rules of a program
+// @Description List the program's rules with the targets for the next session
+// @Security BearerAuth
+// @Tags Programs
+// @Produce json
+// @Param id query int true "Program ID"
+// @Param unit query string false "Weight unit of the response (kg or lb), defaults to the user's preference"
+// @Success 200 {array} models.ResponseProgressionRule
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/progression [get]
+func GetProgressionRulesHandler(s *services.ProgressionService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		rules, err := s.GetRules(ctx.GetInt("userID"), programID, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rules)
+	}
+}
+
+// DeleteProgressionRuleHandler godoc
+// @Summary Delete the progression rule of a program exercise
+// @Description The exercise keeps its program targets; past history is kept
+// @Security BearerAuth
+// @Tags Programs
+// @Produce json
+// @Param id query int true "Program ID"
+// @Param exercise query string true "Exercise name"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/progression [delete]
+func DeleteProgressionRuleHandler(s *services.ProgressionService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+		exercise := ctx.Query("exercise")
+		if exercise == ""{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "exercise is not set"})
+			return
+		}
+
+		deleted, err := s.DeleteRule(ctx.GetInt("userID"), programID, exercise)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !deleted{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "progression rule not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "progression rule deleted"})
+	}
+}
+
+// GetProgressionHistoryHandler godoc
+// @Summary Get the progression history of a program
+// @Description Every evaluation after a workout, newest first, with the targets before and after and the reason for the change
+// @Security BearerAuth
+// @Tags Programs
+// @Produce json
+// @Param id query int true "Program ID"
+// @Param exercise query string false "Only this exercise"
+// @Param unit query string false "Weight unit of the response (kg or lb), defaults to the user's preference"
+// @Success 200 {array} models.ResponseProgressionEvent
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /programs/progression/history [get]
+func GetProgressionHistoryHandler(s *services.ProgressionService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		programID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		history, err := s.GetHistory(ctx.GetInt("userID"), programID, ctx.Query("exercise"), unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, history)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseDateRange reads the optional from/to query parameters (YYYY-MM-DD).
+// The range is half-open: to is moved to the start of the following day.
+func parseDateRange(ctx *gin.Context, defaultFrom time.Time) (time.Time, time.Time, error){
+	from := defaultFrom
+	to := time.Now().AddDate(0, 0, 1)
+
+	if fromStr := ctx.Query("from"); fromStr != ""{
+		parsed, err := time.Parse(dateLayout, fromStr)
+		if err != nil{
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+		}
+		from = parsed
+	}
+	if toStr := ctx.Query("to"); toStr != ""{
+		parsed, err := time.Parse(dateLayout, toStr)
+		if err != nil{
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+	if !from.Before(to){
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+
+	return from, to, nil
+}
+
+// parseProfileID reads the optional profile query parameter, the equipment
+// profile to use instead of the user's default.
+func parseProfileID(ctx *gin.Context) (*int, error){
+	raw := ctx.Query("profile")
+	if raw == ""{
+		return nil, nil
+	}
+	profileID, err := strconv.Atoi(raw)
+	if err != nil{
+		return nil, fmt.Errorf("invalid profile id")
+	}
+	return &profileID, nil
+}
+
+// resolveUnit picks the unit of weights for a request: the unit field of the
+// body, then the unit query parameter, then the user's preference.
+func resolveUnit(ctx *gin.Context, bodyUnit string) (units.System, error){
+	return units.Resolve(bodyUnit, ctx.Query("unit"), ctx.GetString("unitSystem"))
+}
+
+// readUploadedFile reads a multipart file field, capping the request body at
+// maxSize. The returned status is meant for the error response.
+func readUploadedFile(ctx *gin.Context, field string, maxSize int64) ([]byte, int, error){
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize)
+
+	fileHeader, err := ctx.FormFile(field)
+	if err != nil{
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr){
+			return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("file is too large")
+		}
+		return nil, http.StatusBadRequest, fmt.Errorf("%s is required", field)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil{
+		return nil, http.StatusBadRequest, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil{
+		return nil, http.StatusBadRequest, err
+	}
+	return data, http.StatusOK, nil
+}
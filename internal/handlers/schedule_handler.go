@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetScheduleHandler godoc
+// @Summary Get the training calendar
+// @Description Planned, completed, skipped and missed program sessions between from and to (inclusive), merged with workouts logged outside the schedule. from defaults to today and to to four weeks later; the range may span at most a year. Missed sessions are rescheduled per the user's reschedule_policy by an hourly background job. Recurring sessions more than eight weeks ahead are listed without a session_id until they are created.
+// @Security BearerAuth
+// @Tags Schedule
+// @Produce json
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date (YYYY-MM-DD)"
+// @Success 200 {array} models.ScheduleEntry
+// @Failure 400 {object} map[string]string
+// @Router /schedule [get]
+func GetScheduleHandler(s *services.ScheduleService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		now := time.Now().UTC()
+		from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if fromStr := ctx.Query("from"); fromStr != ""{
+			parsed, err := time.Parse(dateLayout, fromStr)
+			if err != nil{
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+				return
+			}
+			from = parsed
+		}
+		to := from.AddDate(0, 0, 28)
+		if toStr := ctx.Query("to"); toStr != ""{
+			parsed, err := time.Parse(dateLayout, toStr)
+			if err != nil{
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+				return
+			}
+			to = parsed
+		}
+
+		entries, err := s.GetSchedule(ctx.GetInt("userID"), from, to)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, entries)
+	}
+}
+
+// ScheduleSessionHandler godoc
+// @Summary Plan a program day on a date
+// @Description Schedule a single session of one of the user's programs. day is the 1-based training day across the program's phases and weeks and defaults to 1.
+// @Security BearerAuth
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param session body models.RequestScheduleSession true "Session"
+// @Success 201 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedule/sessions [post]
+func ScheduleSessionHandler(s *services.ScheduleService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestScheduleSession
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		id, err := s.ScheduleSession(ctx.GetInt("userID"), req)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"id": id})
+	}
+}
+
+// SkipSessionHandler godoc
+// @Summary Skip a planned session
+// @Description Mark a planned or missed session as skipped; skipped sessions are never rescheduled
+// @Security BearerAuth
+// @Tags Schedule
+// @Produce json
+// @Param id query int true "Session ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedule/sessions/skip [post]
+func SkipSessionHandler(s *services.ScheduleService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		skipped, err := s.SkipSession(ctx.GetInt("userID"), id)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !skipped{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "no session to skip"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "session skipped"})
+	}
+}
+
+// DeleteSessionHandler godoc
+// @Summary Remove a scheduled session
+// @Security BearerAuth
+// @Tags Schedule
+// @Produce json
+// @Param id query int true "Session ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedule/sessions [delete]
+func DeleteSessionHandler(s *services.ScheduleService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		deleted, err := s.DeleteSession(ctx.GetInt("userID"), id)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !deleted{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "session deleted"})
+	}
+}
+
+// CreateScheduleRuleHandler godoc
+// @Summary Plan a program on recurring weekdays
+// @Description Schedule a program on weekdays (mon..sun) from start_date (default today) until the optional end_date. Without day the sessions rotate through the program's days in order; with day every session is that day. Sessions are created eight weeks ahead and extended automatically.
+// @Security BearerAuth
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param rule body models.RequestScheduleRule true "Recurring schedule"
+// @Success 201 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedule/rules [post]
+func CreateScheduleRuleHandler(s *services.ScheduleService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestScheduleRule
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		id, err := s.CreateRule(ctx.GetInt("userID"), req)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "program not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"id": id})
+	}
+}
+
+// ListScheduleRulesHandler godoc
+// @Summary List recurring schedules
+// @Security BearerAuth
+// @Tags Schedule
+// @Produce json
+// @Success 200 {array} models.ResponseScheduleRule
+// @Failure 500 {object} map[string]string
+// @Router /schedule/rules [get]
+func ListScheduleRulesHandler(s *services.ScheduleService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		rules, err := s.ListRules(ctx.GetInt("userID"))
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rules)
+	}
+}
+
+// DeleteScheduleRuleHandler godoc
+// @Summary Stop a recurring schedule
+// @Description Planned sessions of the rule from today on are removed; past sessions stay in the calendar
+// @Security BearerAuth
+// @Tags Schedule
+// @Produce json
+// @Param id query int true "Rule ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /schedule/rules [delete]
+func DeleteScheduleRuleHandler(s *services.ScheduleService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+			return
+		}
+
+		deleted, err := s.DeleteRule(ctx.GetInt("userID"), id)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !deleted{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "schedule rule not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "schedule rule deleted"})
+	}
+}
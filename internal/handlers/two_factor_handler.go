@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTwoFactorHandler godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token from /user/login and a TOTP or recovery code for a JWT token
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param login body models.RequestLoginTwoFactor true "Challenge token and second factor"
+// @Success 200 {object} models.ResponseLogin "JWT Token"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 423 {object} map[string]string "Account temporarily locked"
+// @Failure 429 {object} map[string]string "Too many attempts"
+// @Router /user/login/2fa [post]
+func LoginTwoFactorHandler(s *services.AuthService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestLoginTwoFactor
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		resp, err := s.CompleteTwoFactorLogin(ctx, req, ctx.ClientIP())
+		if err != nil{
+			if respondLoginBlocked(ctx, err){
+				return
+			}
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired two-factor challenge"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// EnrollTwoFactorHandler godoc
+// @Summary Start two-factor enrolment
+// @Description Generate a TOTP secret and otpauth URI for an authenticator app. 2FA is enabled only after verification
+// @Security BearerAuth
+// @Tags Users
+// @Produce json
+// @Success 200 {object} models.ResponseTwoFactorEnroll
+// @Failure 400 {object} map[string]string
+// @Router /user/2fa/enroll [post]
+func EnrollTwoFactorHandler(s *services.AuthService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		resp, err := s.EnrollTwoFactor(userID)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// VerifyTwoFactorHandler godoc
+// @Summary Verify two-factor enrolment
+// @Description Confirm the TOTP secret with a code, enable 2FA and receive one-time recovery codes
+// @Security BearerAuth
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param code body models.RequestTwoFactorCode true "TOTP code"
+// @Success 200 {object} models.ResponseRecoveryCodes
+// @Failure 400 {object} map[string]string
+// @Router /user/2fa/verify [post]
+func VerifyTwoFactorHandler(s *services.AuthService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestTwoFactorCode
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		resp, err := s.VerifyTwoFactor(userID, req.Code)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// DisableTwoFactorHandler godoc
+// @Summary Disable two-factor authentication
+// @Description Turn 2FA off after re-authenticating with the password and a TOTP or recovery code
+// @Security BearerAuth
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param credentials body models.RequestDisableTwoFactor true "Password and second factor"
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /user/2fa/disable [post]
+func DisableTwoFactorHandler(s *services.AuthService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var req models.RequestDisableTwoFactor
+
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		if err := s.DisableTwoFactor(userID, req); err != nil{
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"two_factor_enabled": false})
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,7 +29,7 @@ func RegisterUserHandler(s *services.UserService) gin.HandlerFunc{
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 			return
 		}
-		user, err := s.RegisterUserService(&reqUser)
+		user, err := s.RegisterUserService(ctx, &reqUser)
 		if err != nil{
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -42,9 +45,11 @@ func RegisterUserHandler(s *services.UserService) gin.HandlerFunc{
 // @Accept json
 // @Produce json
 // @Param user body models.RequestLoginUser true "User login credentials"
-// @Success 200 {object} map[string]string "JWT Token"
+// @Success 200 {object} models.ResponseLogin "JWT Token or two-factor challenge"
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 423 {object} map[string]string "Account temporarily locked"
+// @Failure 429 {object} map[string]string "Too many attempts"
 // @Router /user/login [post]
 func LoginUserHandler(s *services.AuthService) gin.HandlerFunc{
 	return func(ctx *gin.Context) {
@@ -55,13 +60,16 @@ func LoginUserHandler(s *services.AuthService) gin.HandlerFunc{
 			return
 		}
 
-		token, err := s.AuthenticateUserService(userLogin.Email, userLogin.Password)
+		resp, err := s.AuthenticateUserService(ctx, userLogin.Email, userLogin.Password, ctx.ClientIP())
 		if err != nil {
+			if respondLoginBlocked(ctx, err){
+				return
+			}
             ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
             return
         }
 
-		ctx.JSON(http.StatusOK, gin.H{"token": token})
+		ctx.JSON(http.StatusOK, resp)
 	}
 }
 
@@ -73,6 +81,7 @@ func LoginUserHandler(s *services.AuthService) gin.HandlerFunc{
 // @Accept json
 // @Produce json
 // @Param email query string true "User email"
+// @Param unit query string false "Weight unit of the response (kg or lb)"
 // @Success 200 {object} models.User
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -86,29 +95,68 @@ func GetUserHandler(s *services.UserService) gin.HandlerFunc{
 			return
 		}
 
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		user, err := s.GetUserByEmail(email)
 		if err != nil{
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		user.Weight = units.FromKilograms(user.Weight, unit)
 
 		ctx.JSON(http.StatusOK, user)
 	}
 }
 
+// UpdateUserHandler godoc
+// @Summary Update the user profile
+// @Description Partially update the authenticated user's profile. Only fields present in the body are changed.
+// @Description Changing the email requires re-verification and returns a new token
+// @Security BearerAuth
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param user body models.RequestUpdateUser true "Fields to update"
+// @Success 200 {object} models.ResponseUpdateUser
+// @Failure 400 {object} map[string]string
+// @Router /user [patch]
+func UpdateUserHandler(s *services.UserService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		var reqUser models.RequestUpdateUser
+		if err := ctx.ShouldBindJSON(&reqUser); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		resp, err := s.UpdateUser(ctx, userID, reqUser)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		resp.User.Weight = units.FromKilograms(resp.User.Weight, units.System(resp.User.UnitSystem))
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
 // DeleteUserHandler godoc
-// @Summary Delete user by email
-// @Description Delete a user account using their email address
+// @Summary Schedule account deletion
+// @Description Schedules the account for deletion after a grace period (ACCOUNT_DELETION_GRACE_DAYS, 14 days by default). The email must match the account as a confirmation. Until then the account keeps working and the deletion can be cancelled with POST /user/deletion/cancel; afterwards the account and all of its data are erased by a background worker.
 // @Security BearerAuth
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param email query string true "User email"
-// @Success 200 {integer} int "Deleted user ID"
+// @Success 202 {object} models.ResponseAccountDeletion
 // @Failure 400 {object} map[string]string
-// @Failure 404 {object} map[string]string
 // @Router /user [delete]
-func DeleteUserHandler(s *services.UserService) gin.HandlerFunc{
+func DeleteUserHandler(s *services.AccountService) gin.HandlerFunc{
 	return func(ctx *gin.Context) {
 		email := ctx.Query("email")
 
@@ -119,12 +167,34 @@ func DeleteUserHandler(s *services.UserService) gin.HandlerFunc{
 
 		userID := ctx.GetInt("userID") 
 
-		deletedID, err := s.DeleteUser(email, userID)
+		resp, err := s.ScheduleDeletion(ctx, email, userID)
 		if err != nil{
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, deletedID)
+		ctx.JSON(http.StatusAccepted, resp)
 	}
-}
\ No newline at end of file
+}
+
+// respondLoginBlocked writes 423 for a locked account or 429 for throttling,
+// with a Retry-After header, and reports whether err was a block.
+func respondLoginBlocked(ctx *gin.Context, err error) bool{
+	var blocked *services.LoginBlockedError
+	if !errors.As(err, &blocked){
+		return false
+	}
+
+	retryAfter := int(blocked.RetryAfter.Seconds())
+	if retryAfter < 1{
+		retryAfter = 1
+	}
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	status := http.StatusTooManyRequests
+	if blocked.Locked{
+		status = http.StatusLocked
+	}
+	ctx.JSON(status, gin.H{"error": blocked.Error(), "retry_after": retryAfter})
+	return true
+}
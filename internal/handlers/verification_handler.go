@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/artembliss/go-fitness-tracker/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyEmailHandler godoc
+// @Summary Verify email address
+// @Description Confirm the account email with the token from the verification email
+// @Tags Users
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} map[string]string
+// @Router /user/verify [get]
+func VerifyEmailHandler(s *services.VerificationService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		token := ctx.Query("token")
+		if len(token) == 0{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "token is not set"})
+			return
+		}
+
+		if err := s.VerifyEmail(token); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"email_verified": true})
+	}
+}
+
+// ResendVerificationHandler godoc
+// @Summary Resend verification email
+// @Description Send a new verification email to the authenticated user. Rate limited
+// @Security BearerAuth
+// @Tags Users
+// @Produce json
+// @Success 202 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /user/verify/resend [post]
+func ResendVerificationHandler(s *services.VerificationService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		if err := s.ResendVerification(ctx, userID); err != nil{
+			var limited *services.ResendLimitedError
+			if errors.As(err, &limited){
+				retryAfter := int(limited.RetryAfter.Seconds()) + 1
+				ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+				ctx.JSON(http.StatusTooManyRequests, gin.H{"error": limited.Error(), "retry_after": retryAfter})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, gin.H{"status": "Verification email sent"})
+	}
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/services"
@@ -11,7 +14,7 @@ import (
 
 // CreateWorkoutHandler godoc
 // @Summary Create a new workout
-// @Description Create a new workout for the authenticated user
+// @Description Create a new workout for the authenticated user. Exercises are stored in the order given; consecutive exercises sharing a group label form a superset, giant set or circuit described in groups.
 // @Security BearerAuth
 // @Tags Workouts
 // @Accept json
@@ -43,7 +46,13 @@ func CreateWorkoutHandler(s *services.WorkoutService) gin.HandlerFunc{
 			return
 		}
 
-		workoutID, err := s.CreateWorkout(userID, workoutCreate)
+		unit, err := resolveUnit(ctx, workoutCreate.Unit)
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		workoutID, err := s.CreateWorkout(userID, workoutCreate, unit)
 		if err != nil{
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -61,7 +70,8 @@ func CreateWorkoutHandler(s *services.WorkoutService) gin.HandlerFunc{
 // @Accept json
 // @Produce json
 // @Param id query int true "Workout ID"
-// @Success 200 {object} models.Workout
+// @Param unit query string false "Weight unit of the response (kg or lb), defaults to the user's preference"
+// @Success 200 {object} models.RequestGetWorkout
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Router /workouts [get]
@@ -81,8 +91,14 @@ func GetWorkoutHandler(s *services.WorkoutService) gin.HandlerFunc{
 		}
 
 		userID := ctx.GetInt("userID")
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		
-		workout, err := s.GetWorkout(workoutID, userID)
+		workout, err := s.GetWorkout(workoutID, userID, unit)
 		if err != nil{
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -92,6 +108,127 @@ func GetWorkoutHandler(s *services.WorkoutService) gin.HandlerFunc{
 	}
 }
 
+// GetWorkoutStatsHandler godoc
+// @Summary Get cardio workout statistics
+// @Description Weekly distance, duration and session count plus the best pace per distance (1k, 5k, 10k, half marathon, marathon) for each cardio exercise
+// @Security BearerAuth
+// @Tags Workouts
+// @Produce json
+// @Param from query string false "From date (YYYY-MM-DD), defaults to three months ago"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Param unit query string false "Distance unit of the response (kg/km or lb/mi), defaults to the user's preference"
+// @Success 200 {object} models.ResponseWorkoutStats
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /workouts/stats [get]
+func GetWorkoutStatsHandler(s *services.WorkoutService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		unit, err := resolveUnit(ctx, "")
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		from, to, err := parseDateRange(ctx, time.Now().AddDate(0, -3, 0))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		stats, err := s.GetStats(userID, from, to, unit)
+		if err != nil{
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, stats)
+	}
+}
+
+const maxTrackFileSize = 20 << 20
+
+// ImportWorkoutHandler godoc
+// @Summary Import a workout from a GPX, TCX or FIT file
+// @Description Creates a workout with a cardio entry (distance, duration, elevation gain, heart rate, cadence and splits) and keeps the raw file. Strength sets of FIT files become exercise entries; sets whose FIT category has no catalog exercise are skipped and listed under unmapped. Uploading the same file again returns 409 with the existing workout id.
+// @Security BearerAuth
+// @Tags Workouts
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Activity file"
+// @Param exercise formData string false "Catalog exercise name; picked from the activity sport when omitted"
+// @Success 201 {object} models.ResponseImportWorkout
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 413 {object} map[string]string
+// @Router /workouts/import [post]
+func ImportWorkoutHandler(s *services.WorkoutService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt("userID")
+
+		data, status, err := readUploadedFile(ctx, "file", maxTrackFileSize)
+		if err != nil{
+			ctx.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := s.ImportTrack(userID, data, ctx.PostForm("exercise"))
+		if err != nil{
+			var dupErr *services.DuplicateImportError
+			if errors.As(err, &dupErr){
+				ctx.JSON(http.StatusConflict, gin.H{"error": dupErr.Error(), "workout_id": dupErr.WorkoutID})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, result)
+	}
+}
+
+// GetWorkoutTrackHandler godoc
+// @Summary Download the track file of an imported workout
+// @Description Returns the file exactly as it was uploaded
+// @Security BearerAuth
+// @Tags Workouts
+// @Produce octet-stream
+// @Param id query int true "Workout ID"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /workouts/track [get]
+func GetWorkoutTrackHandler(s *services.WorkoutService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		workoutID, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid workout id"})
+			return
+		}
+
+		userID := ctx.GetInt("userID")
+
+		track, err := s.GetTrack(workoutID, userID)
+		if err != nil{
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "track not found"})
+			return
+		}
+
+		contentType := "application/octet-stream"
+		switch track.Format{
+		case "gpx":
+			contentType = "application/gpx+xml"
+		case "tcx":
+			contentType = "application/vnd.garmin.tcx+xml"
+		case "fit":
+			contentType = "application/vnd.ant.fit"
+		}
+		ctx.Header("Content-Disposition", "attachment; filename=\"workout-" + strconv.Itoa(workoutID) + "." + track.Format + "\"")
+		ctx.Data(http.StatusOK, contentType, track.Data)
+	}
+}
+
 // DeleteWorkoutHandler godoc
 // @Summary Delete a workout by ID
 // @Description Delete a specific workout for the authenticated user
@@ -130,6 +267,47 @@ func DeleteWorkoutHandler(s *services.WorkoutService) gin.HandlerFunc{
 	}
 }
 
+// SwapWorkoutExerciseHandler godoc
+// @Summary Swap an exercise in a workout
+// @Description Replace an exercise of a workout, e.g. when the equipment is busy, keeping the sets logged so far. The entry remembers the exercise it replaced (substituted_for), so the workout still matches its program. position picks one entry when the exercise appears more than once.
+// @Security BearerAuth
+// @Tags Workouts
+// @Accept json
+// @Produce json
+// @Param id query int true "Workout ID"
+// @Param swap body models.RequestSwapWorkoutExercise true "Exercise to replace and its replacement"
+// @Success 200 {object} models.ResponseSwapExercise
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /workouts/swap [post]
+func SwapWorkoutExerciseHandler(s *services.WorkoutService) gin.HandlerFunc{
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Query("id"))
+		if err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid workout id"})
+			return
+		}
+
+		var req models.RequestSwapWorkoutExercise
+		if err := ctx.ShouldBindJSON(&req); err != nil{
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		swapped, err := s.SwapExercise(ctx.GetInt("userID"), id, req)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, models.ResponseSwapExercise{Swapped: swapped})
+	}
+}
+
 // UpdateWorkoutHandler godoc
 // @Summary Update an existing workout
 // @Description Update a specific workout's details for the authenticated user
@@ -166,9 +344,19 @@ func UpdateWorkoutHandler(s *services.WorkoutService) gin.HandlerFunc{
 
 		userID := ctx.GetInt("userID") 
 
-		updatedID, err := s.UpdateWorkout(id, userID, workoutUpdate)
+		unit, err := resolveUnit(ctx, workoutUpdate.Unit)
 		if err != nil{
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			ctx.JSON(http.StatusBadRequest,  gin.H{"error": err.Error()})
+			return
+		}
+
+		updatedID, err := s.UpdateWorkout(id, userID, workoutUpdate, unit)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				ctx.JSON(http.StatusNotFound, gin.H{"error": "workout not found"})
+				return
+			}
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
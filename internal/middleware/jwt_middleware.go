@@ -38,8 +38,15 @@ func JWTMiddleware(s *services.UserService) gin.HandlerFunc{
             ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
             ctx.Abort()
             return
+        }
+        if claims.TokenVersion != user.TokenVersion {
+            ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+            ctx.Abort()
+            return
         }
 		ctx.Set("userID", user.ID)
+		ctx.Set("emailVerified", user.EmailVerified)
+		ctx.Set("unitSystem", user.UnitSystem)
 
         ctx.Next()
 	}
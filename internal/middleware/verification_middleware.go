@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerificationPolicy string
+
+const (
+	// PolicyOff lets unverified accounts use every endpoint.
+	PolicyOff VerificationPolicy = "off"
+	// PolicyReadOnly lets unverified accounts read their data but not change it.
+	PolicyReadOnly VerificationPolicy = "read_only"
+	// PolicyBlock rejects unverified accounts on all protected endpoints.
+	PolicyBlock VerificationPolicy = "block"
+)
+
+func VerificationPolicyFromEnv() VerificationPolicy{
+	switch policy := VerificationPolicy(os.Getenv("EMAIL_VERIFICATION_POLICY")); policy{
+	case PolicyOff, PolicyBlock:
+		return policy
+	default:
+		return PolicyReadOnly
+	}
+}
+
+// EmailVerificationMiddleware must run after JWTMiddleware. Routes listed in
+// allowed (gin full paths) stay reachable regardless of the policy.
+func EmailVerificationMiddleware(policy VerificationPolicy, allowed ...string) gin.HandlerFunc{
+	allowedPaths := make(map[string]bool, len(allowed))
+	for _, path := range allowed{
+		allowedPaths[path] = true
+	}
+
+	return func(ctx *gin.Context) {
+		if policy == PolicyOff || ctx.GetBool("emailVerified") || allowedPaths[ctx.FullPath()]{
+			ctx.Next()
+			return
+		}
+
+		readOnly := ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead
+		if policy == PolicyReadOnly && readOnly{
+			ctx.Next()
+			return
+		}
+
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
+		ctx.Abort()
+	}
+}
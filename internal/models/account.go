@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	DataExportStatusPending   = "pending"
+	DataExportStatusRunning   = "running"
+	DataExportStatusCompleted = "completed"
+	DataExportStatusFailed    = "failed"
+	DataExportStatusExpired   = "expired"
+)
+
+// DataExport is a "download my data" archive. The file is built in the
+// background and removed once ExpiresAt has passed.
+type DataExport struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	Status     string     `json:"status" db:"status"`
+	FilePath   string     `json:"-" db:"file_path"`
+	SizeBytes  int64      `json:"size_bytes" db:"size_bytes"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// TokenMetadata describes a password reset, email verification or 2FA
+// recovery token without its secret.
+type TokenMetadata struct {
+	Kind      string     `json:"kind" db:"kind"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+}
+
+type ResponseAccountDeletion struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+const (
+	AuditLoginAccountLocked = "login.account_locked"
+	AuditLoginIPBlocked     = "login.ip_blocked"
+)
+
+type AuditEntry struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    *int      `json:"user_id" db:"user_id"`
+	Event     string    `json:"event" db:"event"`
+	IP        string    `json:"ip" db:"ip"`
+	Details   string    `json:"details" db:"details"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
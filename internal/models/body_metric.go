@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// BodyMetric is one measurement session. Every measurement is optional so a
+// log entry can hold just the weight or a full set of tape measurements.
+// Lengths are in centimetres, weight in kilograms.
+type BodyMetric struct {
+	ID               int       `json:"id" db:"id"`
+	UserID           int       `json:"user_id" db:"user_id"`
+	MeasuredAt       time.Time `json:"measured_at" db:"measured_at"`
+	Weight           *float64  `json:"weight,omitempty" db:"weight"`
+	BodyFat          *float64  `json:"body_fat,omitempty" db:"body_fat"`
+	Waist            *float64  `json:"waist,omitempty" db:"waist"`
+	Chest            *float64  `json:"chest,omitempty" db:"chest"`
+	Arms             *float64  `json:"arms,omitempty" db:"arms"`
+	Thighs           *float64  `json:"thighs,omitempty" db:"thighs"`
+	RestingHeartRate *int      `json:"resting_heart_rate,omitempty" db:"resting_heart_rate"`
+	Unit             string    `json:"unit,omitempty" db:"-"`
+	CreatedAt        time.Time `json:"-" db:"created_at"`
+}
+
+type RequestBodyMetric struct {
+	MeasuredAt       *time.Time `json:"measured_at"`
+	Weight           *float64   `json:"weight"`
+	BodyFat          *float64   `json:"body_fat"`
+	Waist            *float64   `json:"waist"`
+	Chest            *float64   `json:"chest"`
+	Arms             *float64   `json:"arms"`
+	Thighs           *float64   `json:"thighs"`
+	RestingHeartRate *int       `json:"resting_heart_rate"`
+	Unit             string     `json:"unit" example:"kg"`
+}
+
+type BodyMetricTrendPoint struct {
+	MeasuredAt    time.Time `json:"measured_at"`
+	Value         float64   `json:"value"`
+	MovingAverage float64   `json:"moving_average"`
+}
+
+type ResponseBodyMetricTrend struct {
+	Metric     string                 `json:"metric"`
+	Unit       string                 `json:"unit,omitempty"`
+	WindowDays int                    `json:"window_days"`
+	Points     []BodyMetricTrendPoint `json:"points"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CalendarFeed is a user's iCalendar subscription. Only a hash of the secret
+// token is stored; the feed URL is shown once when the token is created.
+type CalendarFeed struct {
+	ID        int       `json:"-" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type ResponseCalendarFeed struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// EquipmentProfile is a named place to train (home gym, commercial gym,
+// travel) with the catalog equipment available there. Increments are the
+// smallest load steps in kilograms: PlateIncrement for bars, DumbbellIncrement
+// for dumbbells and kettlebells. A user has at most one default profile.
+type EquipmentProfile struct {
+	ID                int            `db:"id"`
+	UserID            int            `db:"user_id"`
+	Name              string         `db:"name"`
+	Equipment         pq.StringArray `db:"equipment"`
+	PlateIncrement    *float64       `db:"plate_increment"`
+	DumbbellIncrement *float64       `db:"dumbbell_increment"`
+	IsDefault         bool           `db:"is_default"`
+	CreatedAt         time.Time      `db:"created_at"`
+}
+
+// RequestEquipmentProfile uses the request's unit for the increments.
+type RequestEquipmentProfile struct {
+	Name              string   `json:"name" example:"Home gym"`
+	// Equipment lists catalog equipment values; body weight exercises are
+	// always available.
+	Equipment         []string `json:"equipment" example:"dumbbell,bands"`
+	PlateIncrement    *float64 `json:"plate_increment,omitempty" example:"2.5"`
+	DumbbellIncrement *float64 `json:"dumbbell_increment,omitempty" example:"2"`
+	// Default makes this the active profile. The first profile always is.
+	Default           bool     `json:"default,omitempty"`
+	Unit              string   `json:"unit,omitempty" example:"kg"`
+}
+
+type ResponseEquipmentProfile struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	Equipment         []string  `json:"equipment"`
+	PlateIncrement    *float64  `json:"plate_increment,omitempty"`
+	DumbbellIncrement *float64  `json:"dumbbell_increment,omitempty"`
+	Default           bool      `json:"default"`
+	Unit              string    `json:"unit"`
+	CreatedAt         time.Time `json:"created_at"`
+}
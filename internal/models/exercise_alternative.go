@@ -0,0 +1,38 @@
+package models
+
+// ExerciseAlternative is a ranked substitute for an exercise. Reasons lists
+// what the substitute shares with the original.
+type ExerciseAlternative struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	MuscleGroup string   `json:"muscle_group"`
+	Equipment   string   `json:"equipment"`
+	Difficulty  string   `json:"difficulty"`
+	Score       int      `json:"score"`
+	Reasons     []string `json:"reasons"`
+}
+
+// RequestSwapProgramExercise replaces an exercise of a program. Phase, Week
+// and Day (1-based) narrow the swap down; left out, every slot of the
+// exercise is swapped.
+type RequestSwapProgramExercise struct {
+	Exercise    string `json:"exercise" example:"Barbell Bench Press"`
+	Replacement string `json:"replacement" example:"Dumbbell Bench Press"`
+	Phase       int    `json:"phase,omitempty"`
+	Week        int    `json:"week,omitempty"`
+	Day         int    `json:"day,omitempty"`
+}
+
+// RequestSwapWorkoutExercise replaces an exercise of a workout, keeping its
+// sets. Position (1-based) picks one entry when the exercise appears more
+// than once.
+type RequestSwapWorkoutExercise struct {
+	Exercise    string `json:"exercise" example:"Barbell Bench Press"`
+	Replacement string `json:"replacement" example:"Dumbbell Bench Press"`
+	Position    int    `json:"position,omitempty"`
+}
+
+type ResponseSwapExercise struct {
+	Swapped int `json:"swapped"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// ExerciseGroupSuperset pairs two exercises done back to back.
+	ExerciseGroupSuperset = "superset"
+	// ExerciseGroupGiantSet chains three or more exercises done back to back.
+	ExerciseGroupGiantSet = "giant_set"
+	// ExerciseGroupCircuit repeats its exercises for a number of rounds.
+	ExerciseGroupCircuit  = "circuit"
+)
+
+// ExerciseGroup joins consecutive exercises of a program day or workout that
+// share its Label in their group field. Rounds is how many times the group
+// is gone through and RestSeconds the rest after each round.
+type ExerciseGroup struct {
+	Label       string `json:"label" example:"A"`
+	Type        string `json:"type,omitempty" example:"superset"`
+	Rounds      *int   `json:"rounds,omitempty" example:"3"`
+	RestSeconds *int   `json:"rest_seconds,omitempty" example:"90"`
+}
+
+// ExerciseGroups is stored as a JSONB column; an empty list is stored as NULL.
+type ExerciseGroups []ExerciseGroup
+
+func (g ExerciseGroups) Value() (driver.Value, error){
+	if len(g) == 0{
+		return nil, nil
+	}
+	return json.Marshal(g)
+}
+
+func (g *ExerciseGroups) Scan(src interface{}) error{
+	switch v := src.(type){
+	case nil:
+		*g = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, g)
+	case string:
+		return json.Unmarshal([]byte(v), g)
+	default:
+		return fmt.Errorf("unsupported type %T for exercise groups", src)
+	}
+}
@@ -1,6 +1,14 @@
 package models
 
-import "github.com/lib/pq"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+const ExerciseTypeCardio = "cardio"
 
 type Exercise struct {
 	ID          int    `db:"id"`
@@ -16,16 +24,86 @@ type ExerciseEntry struct {
 	ID         int             `db:"id"`
 	WorkoutID  int             `db:"workout_id"`
 	ExerciseID int             `db:"exercise_id"`
+	// Position orders the entries of a workout, starting at 1.
+	Position   int             `db:"position"`
+	Group      *string         `db:"group_label"`
+	// SubstitutedForID is the exercise that was planned before it was swapped.
+	SubstitutedForID *int      `db:"substituted_for_id"`
 	Sets       int             `db:"sets"`
 	Reps       pq.Int64Array   `db:"reps" swaggertype:"array,integer"`
 	Weight     pq.Float64Array `db:"weight" swaggertype:"array,number"`
+	// Cardio metrics, stored in metres and seconds.
+	Distance        *float64  `db:"distance"`
+	DurationSeconds *int      `db:"duration_seconds"`
+	ElevationGain   *float64  `db:"elevation_gain"`
+	AvgHeartRate    *int      `db:"avg_heart_rate"`
+	MaxHeartRate    *int      `db:"max_heart_rate"`
+	AvgCadence      *int      `db:"avg_cadence"`
+	Intervals       Intervals `db:"intervals" swaggertype:"array,object"`
+}
+
+// Interval is one lap, split or work/rest block of a cardio entry.
+type Interval struct {
+	Type            string  `json:"type,omitempty"`
+	Distance        float64 `json:"distance,omitempty"`
+	DurationSeconds int     `json:"duration_seconds"`
+	AvgHeartRate    int     `json:"avg_heart_rate,omitempty"`
+	AvgCadence      int     `json:"avg_cadence,omitempty"`
+}
+
+// Intervals is stored as a JSONB column.
+type Intervals []Interval
+
+func (i Intervals) Value() (driver.Value, error){
+	if i == nil{
+		return nil, nil
+	}
+	return json.Marshal(i)
+}
+
+func (i *Intervals) Scan(src interface{}) error{
+	switch v := src.(type){
+	case nil:
+		*i = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, i)
+	case string:
+		return json.Unmarshal([]byte(v), i)
+	default:
+		return fmt.Errorf("unsupported type %T for intervals", src)
+	}
+}
+
+// RequestInterval uses the request's unit for distance and a Go duration string.
+type RequestInterval struct {
+	Type         string   `json:"type,omitempty" example:"work"`
+	Distance     *float64 `json:"distance,omitempty"`
+	Duration     string   `json:"duration" example:"4m0s"`
+	Pace         string   `json:"pace,omitempty"`
+	AvgHeartRate *int     `json:"avg_heart_rate,omitempty"`
+	AvgCadence   *int     `json:"avg_cadence,omitempty"`
 }
 
+// ExerciseRequestEntry carries either strength data (sets, reps, weight) or,
+// for cardio exercises, distance (km or mi), duration and heart rate.
+// Pace is derived and only present in responses.
 type ExerciseRequestEntry struct {
 	Name   string    `json:"name"`
 	Sets   int       `json:"sets"`
 	Reps   []int     `json:"reps"`
 	Weight []float64 `json:"weight"`
+	Distance      *float64          `json:"distance,omitempty"`
+	Duration      string            `json:"duration,omitempty" example:"30m0s"`
+	Pace          string            `json:"pace,omitempty"`
+	ElevationGain *float64          `json:"elevation_gain,omitempty"`
+	AvgHeartRate  *int              `json:"avg_heart_rate,omitempty"`
+	MaxHeartRate  *int              `json:"max_heart_rate,omitempty"`
+	AvgCadence    *int              `json:"avg_cadence,omitempty"`
+	Intervals     []RequestInterval `json:"intervals,omitempty"`
+	Group         string            `json:"group,omitempty" example:"A"`
+	// SubstitutedFor names the exercise this entry was swapped in for.
+	SubstitutedFor string           `json:"substituted_for,omitempty"`
 }
 
 type ExerciseRequest struct {
@@ -35,13 +113,12 @@ type ExerciseRequest struct {
 	Weight float64 `json:"weight"`
 }
 
-type ExerciseProgramDB struct {
-	ID         int     `db:"id"`
-	ProgramID  int     `db:"program_id"`
-	ExerciseID int     `db:"exercise_id"`
-	Sets       int     `db:"sets"`
-	Reps       int     `db:"reps"`
-	Weight     float64 `db:"weight"`
+// ExerciseSearch filters the catalog; empty fields match everything.
+type ExerciseSearch struct {
+	Name        string
+	Type        string
+	MuscleGroup string
+	Difficulty  string
 }
 
 type ExerciseAPI struct {
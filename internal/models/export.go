@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ExportWorkoutRow is one exercise entry joined with its workout. Workouts
+// without entries come back once with the entry fields empty.
+type ExportWorkoutRow struct {
+	WorkoutID       int             `db:"workout_id"`
+	Date            time.Time       `db:"date"`
+	ProgramName     *string         `db:"program_name"`
+	Duration        time.Duration   `db:"duration"`
+	Calories        float64         `db:"calories"`
+	ExerciseName    *string         `db:"exercise_name"`
+	ExerciseType    *string         `db:"exercise_type"`
+	Sets            *int            `db:"sets"`
+	Reps            pq.Int64Array   `db:"reps"`
+	Weight          pq.Float64Array `db:"weight"`
+	Distance        *float64        `db:"distance"`
+	DurationSeconds *int            `db:"duration_seconds"`
+	ElevationGain   *float64        `db:"elevation_gain"`
+	AvgHeartRate    *int            `db:"avg_heart_rate"`
+	MaxHeartRate    *int            `db:"max_heart_rate"`
+}
+
+// ExportProgramRow is one exercise slot joined with its day, week, phase and program.
+type ExportProgramRow struct {
+	ProgramID    int       `db:"program_id"`
+	ProgramName  string    `db:"program_name"`
+	CreatedAt    time.Time `db:"created_at"`
+	Phase        *string   `db:"phase"`
+	Week         *int      `db:"week"`
+	Day          *string   `db:"day"`
+	ExerciseName *string   `db:"exercise_name"`
+	Sets         *int      `db:"sets"`
+	Reps         *int      `db:"reps"`
+	RepsMax      *int      `db:"reps_max"`
+	Weight       *float64  `db:"weight"`
+	PercentE1RM  *float64  `db:"percent_e1rm"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// ImportJob tracks a history import from another app. In a dry run nothing is
+// written; Preview shows the workouts that would be created.
+type ImportJob struct {
+	ID                int                  `json:"id" db:"id"`
+	UserID            int                  `json:"-" db:"user_id"`
+	Source            string               `json:"source" db:"source"`
+	Status            string               `json:"status" db:"status"`
+	DryRun            bool                 `json:"dry_run" db:"dry_run"`
+	ContentHash       string               `json:"-" db:"content_hash"`
+	TotalWorkouts     int                  `json:"total_workouts" db:"total_workouts"`
+	ProcessedWorkouts int                  `json:"processed_workouts" db:"processed_workouts"`
+	CreatedWorkouts   int                  `json:"created_workouts" db:"created_workouts"`
+	SkippedWorkouts   int                  `json:"skipped_workouts" db:"skipped_workouts"`
+	Unmapped          ImportUnmappedList   `json:"unmapped" db:"unmapped" swaggertype:"array,object"`
+	Preview           ImportPreview        `json:"preview,omitempty" db:"preview" swaggertype:"array,object"`
+	Error             string               `json:"error,omitempty" db:"error"`
+	CreatedAt         time.Time            `json:"created_at" db:"created_at"`
+	FinishedAt        *time.Time           `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+// ImportUnmapped is an exercise of the export whose sets were not imported,
+// either because no catalog exercise matched or because its data was invalid.
+type ImportUnmapped struct {
+	Name   string `json:"name"`
+	Sets   int    `json:"sets"`
+	Reason string `json:"reason"`
+}
+
+type ImportUnmappedList []ImportUnmapped
+
+func (l ImportUnmappedList) Value() (driver.Value, error){
+	return json.Marshal(l)
+}
+
+func (l *ImportUnmappedList) Scan(src interface{}) error{
+	return scanJSON(src, l)
+}
+
+type ImportPreviewExercise struct {
+	Name     string `json:"name"`
+	MappedTo string `json:"mapped_to"`
+	Sets     int    `json:"sets"`
+}
+
+type ImportPreviewWorkout struct {
+	Title     string                  `json:"title"`
+	Date      time.Time               `json:"date"`
+	Duration  string                  `json:"duration"`
+	Exercises []ImportPreviewExercise `json:"exercises"`
+}
+
+type ImportPreview []ImportPreviewWorkout
+
+func (p ImportPreview) Value() (driver.Value, error){
+	if p == nil{
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *ImportPreview) Scan(src interface{}) error{
+	return scanJSON(src, p)
+}
+
+// ExerciseNameMapping tells the importer which catalog exercise a name from
+// another app means for this user.
+type ExerciseNameMapping struct {
+	ID           int       `json:"id" db:"id"`
+	UserID       int       `json:"-" db:"user_id"`
+	SourceName   string    `json:"source_name" db:"source_name"`
+	ExerciseID   int       `json:"exercise_id" db:"exercise_id"`
+	ExerciseName string    `json:"exercise_name" db:"exercise_name"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+type RequestExerciseNameMapping struct {
+	SourceName string `json:"source_name" binding:"required" example:"Bench Press (Barbell)"`
+	Exercise   string `json:"exercise" binding:"required" example:"Barbell Bench Press"`
+}
+
+func scanJSON(src interface{}, dest interface{}) error{
+	switch v := src.(type){
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("unsupported type %T for JSON column", src)
+	}
+}
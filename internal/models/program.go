@@ -2,14 +2,32 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	ProgramVisibilityPrivate  = "private"
+	// ProgramVisibilityUnlisted programs can be opened by anyone with the share link.
+	ProgramVisibilityUnlisted = "unlisted"
+	ProgramVisibilityPublic   = "public"
 )
 
+// Program is split into phases, weeks and days; each day holds ordered
+// exercise slots. Flat programs are stored as a single phase, week and day.
 type Program struct {
 	ID        int          `json:"id" db:"id"`
 	UserID    int          `json:"user_id" db:"user_id"`
 	Name      string          `json:"name" db:"name"`
-	Exercises []ExerciseProgramDB `json:"exercises" db:"exercises"`
+	Phases    []ProgramPhase  `json:"phases" db:"-"`
 	CreatedAt time.Time       `json:"-" db:"created_at"`
+	Visibility string         `json:"visibility" db:"visibility"`
+	ShareToken *string        `json:"-" db:"share_token"`
+	Tags       pq.StringArray `json:"tags" db:"tags"`
+	// ForkedFrom* keep the attribution of a fork after the original is deleted.
+	ForkedFromID     *int    `json:"forked_from_id" db:"forked_from_id"`
+	ForkedFromName   *string `json:"forked_from_name" db:"forked_from_name"`
+	ForkedFromAuthor *string `json:"forked_from_author" db:"forked_from_author"`
 }
 
 type ProgramDB struct {
@@ -17,17 +35,193 @@ type ProgramDB struct {
 	UserID    int          `db:"user_id"`
 	Name      string       `db:"name"`
 	CreatedAt time.Time    `db:"created_at"`
+	Visibility       string         `db:"visibility"`
+	ShareToken       *string        `db:"share_token"`
+	Tags             pq.StringArray `db:"tags"`
+	ForkedFromID     *int           `db:"forked_from_id"`
+	ForkedFromName   *string        `db:"forked_from_name"`
+	ForkedFromAuthor *string        `db:"forked_from_author"`
+}
+
+// ProgramSummary is a row of the program list. LastUsedAt is the date of the
+// latest workout logged for the program.
+type ProgramSummary struct {
+	ID            int        `json:"id" db:"id"`
+	Name          string     `json:"name" db:"name"`
+	Visibility    string     `json:"visibility" db:"visibility"`
+	ExerciseCount int        `json:"exercise_count" db:"exercise_count"`
+	LastUsedAt    *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type RequestCloneProgram struct {
+	// Name defaults to the original name with " (copy)" appended.
+	Name string `json:"name" example:"5x5 (copy)"`
+}
+
+// The json tags of ProgramPhase, ProgramWeek, ProgramDay and ProgramSlot
+// describe the snapshot stored in program_versions.structure.
+type ProgramPhase struct {
+	ID        int           `json:"-" db:"id"`
+	ProgramID int           `json:"-" db:"program_id"`
+	Position  int           `json:"-" db:"position"`
+	Name      string        `json:"name" db:"name"`
+	Weeks     []ProgramWeek `json:"weeks" db:"-"`
+}
+
+type ProgramWeek struct {
+	ID       int          `json:"-" db:"id"`
+	PhaseID  int          `json:"-" db:"phase_id"`
+	Position int          `json:"-" db:"position"`
+	Name     string       `json:"name" db:"name"`
+	Days     []ProgramDay `json:"days" db:"-"`
+}
+
+type ProgramDay struct {
+	ID       int            `json:"-" db:"id"`
+	WeekID   int            `json:"-" db:"week_id"`
+	Position int            `json:"-" db:"position"`
+	Name     string         `json:"name" db:"name"`
+	Groups   ExerciseGroups `json:"groups,omitempty" db:"groups"`
+	Slots    []ProgramSlot  `json:"slots" db:"-"`
+}
+
+// ProgramSlot is one exercise of a training day with its set scheme: Sets x
+// Reps, or Sets x Reps-RepsMax for a rep range, at a fixed Weight (kg) or a
+// percentage of the estimated one-rep max.
+type ProgramSlot struct {
+	ID          int      `json:"-" db:"id"`
+	DayID       int      `json:"-" db:"day_id"`
+	Position    int      `json:"-" db:"position"`
+	ExerciseID  int      `json:"exercise_id" db:"exercise_id"`
+	Sets        int      `json:"sets" db:"sets"`
+	Reps        int      `json:"reps" db:"reps"`
+	RepsMax     *int     `json:"reps_max" db:"reps_max"`
+	Weight      *float64 `json:"weight" db:"weight"`
+	PercentE1RM *float64 `json:"percent_e1rm" db:"percent_e1rm"`
+	RestSeconds *int     `json:"rest_seconds" db:"rest_seconds"`
+	Notes       string   `json:"notes" db:"notes"`
+	// Group is the label of the day's exercise group the slot belongs to.
+	Group       *string  `json:"group,omitempty" db:"group_label"`
+	// SubstitutedForID is the exercise the slot originally had before it was swapped.
+	SubstitutedForID *int `json:"substituted_for_id,omitempty" db:"substituted_for_id"`
 }
 
 type RequestGetProgram struct {
 	ID        int                     `json:"id"`
 	UserID    int                     `json:"user_id"`
 	Name      string                  `json:"name"`
-	Exercises []ExerciseRequest       `json:"exercises"`
+	// Exercises is the flat form, filled for programs with a single day.
+	Exercises []ExerciseRequest       `json:"exercises,omitempty"`
+	Phases    []RequestProgramPhase   `json:"phases"`
+	Unit      string                  `json:"unit"`
+	Visibility string                 `json:"visibility"`
+	Tags      []string                `json:"tags"`
+	// ShareURL is only shown to the owner of an unlisted or public program.
+	ShareURL  string                  `json:"share_url,omitempty"`
+	ForkedFrom *ProgramAttribution    `json:"forked_from,omitempty"`
 	CreatedAt time.Time               `json:"-"`
 }
 
+// ProgramAttribution names the program a fork was made from. ProgramID is
+// nil once the original has been deleted.
+type ProgramAttribution struct {
+	ProgramID *int   `json:"program_id"`
+	Name      string `json:"name"`
+	Author    string `json:"author"`
+}
+
+// RequestProgramSharing has partial-update semantics like RequestUpdateUser.
+type RequestProgramSharing struct {
+	Visibility *string   `json:"visibility" example:"public"`
+	Tags       *[]string `json:"tags"`
+}
+
+type ResponseProgramSharing struct {
+	Visibility string   `json:"visibility"`
+	Tags       []string `json:"tags"`
+	ShareURL   string   `json:"share_url,omitempty"`
+}
+
+// LibraryProgramSummary is a row of the public program library.
+type LibraryProgramSummary struct {
+	ID             int            `json:"id" db:"id"`
+	Name           string         `json:"name" db:"name"`
+	Author         string         `json:"author" db:"author"`
+	Tags           pq.StringArray `json:"tags" db:"tags" swaggertype:"array,string"`
+	ExerciseCount  int            `json:"exercise_count" db:"exercise_count"`
+	FollowersCount int            `json:"followers_count" db:"followers_count"`
+	ForksCount     int            `json:"forks_count" db:"forks_count"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+}
+
+type LibraryProgramDB struct {
+	ProgramDB
+	Author         string `db:"author"`
+	FollowersCount int    `db:"followers_count"`
+	ForksCount     int    `db:"forks_count"`
+}
+
+type ResponseLibraryProgram struct {
+	RequestGetProgram
+	Author         string `json:"author"`
+	FollowersCount int    `json:"followers_count"`
+	ForksCount     int    `json:"forks_count"`
+}
+
+// RequestForkProgram needs ShareToken to fork an unlisted program.
+type RequestForkProgram struct {
+	Name       string `json:"name"`
+	ShareToken string `json:"share_token,omitempty"`
+}
+
+type ResponseProgramFollowers struct {
+	Following      bool `json:"following"`
+	FollowersCount int  `json:"followers_count"`
+}
+
+// RequestCreateProgram takes either the flat exercise list or phases.
 type RequestCreateProgram struct {
 	Name      string          `json:"name"`
 	Exercises []ExerciseRequest `json:"exercises"`
+	Phases    []RequestProgramPhase `json:"phases"`
+	Unit      string          `json:"unit" example:"kg"`
+}
+
+type RequestProgramPhase struct {
+	Name  string               `json:"name" example:"Accumulation"`
+	Weeks []RequestProgramWeek `json:"weeks"`
+}
+
+type RequestProgramWeek struct {
+	Name string              `json:"name,omitempty" example:"Week 1"`
+	Days []RequestProgramDay `json:"days"`
+}
+
+// RequestProgramDay lists exercises in the order they are done. Groups
+// describes the supersets, giant sets and circuits the exercises refer to.
+type RequestProgramDay struct {
+	Name      string               `json:"name" example:"Day A"`
+	Exercises []RequestProgramSlot `json:"exercises"`
+	Groups    []ExerciseGroup      `json:"groups,omitempty"`
+}
+
+// RequestProgramSlot sets the scheme either with Scheme ("5x5", "3x8-12",
+// "5x3@85%") or with the individual fields.
+type RequestProgramSlot struct {
+	Name        string   `json:"name" example:"Barbell Squat"`
+	Scheme      string   `json:"scheme,omitempty" example:"3x8-12"`
+	Sets        int      `json:"sets,omitempty"`
+	Reps        int      `json:"reps,omitempty"`
+	RepsMax     *int     `json:"reps_max,omitempty"`
+	Weight      *float64 `json:"weight,omitempty"`
+	PercentE1RM *float64 `json:"percent_e1rm,omitempty"`
+	RestSeconds *int     `json:"rest_seconds,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+	Group       string   `json:"group,omitempty" example:"A"`
+	// SubstitutedFor names the exercise this one replaced in the program.
+	SubstitutedFor string `json:"substituted_for,omitempty"`
+	// TargetWeight is response-only: PercentE1RM applied to the user's
+	// current estimated one-rep max.
+	TargetWeight *float64 `json:"target_weight,omitempty"`
 }
@@ -0,0 +1,46 @@
+package models
+
+const (
+	GeneratorGoalStrength    = "strength"
+	GeneratorGoalHypertrophy = "hypertrophy"
+	GeneratorGoalEndurance   = "endurance"
+)
+
+// Experience levels match the difficulty values of the exercise catalog.
+const (
+	ExperienceBeginner     = "beginner"
+	ExperienceIntermediate = "intermediate"
+	ExperienceExpert       = "expert"
+)
+
+// RequestGenerateProgram describes the program to generate. The same request
+// with the same Seed always yields the same program for the same catalog.
+type RequestGenerateProgram struct {
+	Name           string   `json:"name,omitempty" example:"My first program"`
+	Goal           string   `json:"goal" example:"hypertrophy"`
+	DaysPerWeek    int      `json:"days_per_week" example:"3"`
+	// SessionMinutes defaults to 60.
+	SessionMinutes int      `json:"session_minutes,omitempty" example:"60"`
+	// Experience defaults to beginner.
+	Experience     string   `json:"experience,omitempty" example:"beginner"`
+	// Equipment lists catalog equipment values; body weight exercises are
+	// always allowed. Empty means the equipment of ProfileID, or of the
+	// default profile, or any equipment for users without profiles.
+	Equipment      []string `json:"equipment,omitempty" example:"barbell,dumbbell"`
+	ProfileID      *int     `json:"profile_id,omitempty" example:"1"`
+	// Seed defaults to a random value, returned in the response.
+	Seed           *int64   `json:"seed,omitempty" example:"42"`
+	// Preview returns the program without saving it.
+	Preview        bool     `json:"preview,omitempty"`
+	Unit           string   `json:"unit,omitempty" example:"kg"`
+}
+
+type ResponseGeneratedProgram struct {
+	// ID is omitted for previews.
+	ID      *int              `json:"id,omitempty"`
+	Seed    int64             `json:"seed"`
+	Split   string            `json:"split"`
+	// EquipmentProfile names the profile whose equipment was used.
+	EquipmentProfile string   `json:"equipment_profile,omitempty"`
+	Program RequestGetProgram `json:"program"`
+}
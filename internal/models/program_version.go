@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ProgramStructure is the JSONB snapshot of a program's phases.
+type ProgramStructure []ProgramPhase
+
+func (p *ProgramStructure) Scan(src interface{}) error{
+	switch v := src.(type){
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("unsupported type %T for program structure", src)
+	}
+}
+
+// ProgramVersion is an immutable snapshot taken whenever a program is
+// created or its name or structure changes. Versions are numbered from 1.
+type ProgramVersion struct {
+	ID        int              `db:"id"`
+	ProgramID int              `db:"program_id"`
+	Version   int              `db:"version"`
+	Name      string           `db:"name"`
+	Structure ProgramStructure `db:"structure"`
+	CreatedAt time.Time        `db:"created_at"`
+}
+
+type ProgramVersionSummary struct {
+	Version      int       `json:"version" db:"version"`
+	Name         string    `json:"name" db:"name"`
+	WorkoutCount int       `json:"workout_count" db:"workout_count"`
+	Current      bool      `json:"current" db:"current"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+type ResponseProgramVersion struct {
+	RequestGetProgram
+	Version          int       `json:"version"`
+	VersionCreatedAt time.Time `json:"version_created_at"`
+}
+
+const (
+	ProgramChangeAdded   = "added"
+	ProgramChangeRemoved = "removed"
+	ProgramChangeChanged = "changed"
+)
+
+// ProgramVersionDiff lists what changed between two versions, day by day.
+// Days are matched by their phase, week and day positions; exercises within
+// a day by name and occurrence.
+type ProgramVersionDiff struct {
+	From int                `json:"from"`
+	To   int                `json:"to"`
+	Name *ProgramNameChange `json:"name,omitempty"`
+	Days []ProgramDayDiff   `json:"days"`
+	Unit string             `json:"unit"`
+}
+
+type ProgramNameChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type ProgramDayDiff struct {
+	Phase     int               `json:"phase"`
+	Week      int               `json:"week"`
+	Day       int               `json:"day"`
+	Name      string            `json:"name"`
+	Change    string            `json:"change"`
+	// Fields lists changed day attributes, currently only "name".
+	Fields    []string          `json:"fields,omitempty"`
+	Exercises []ProgramSlotDiff `json:"exercises,omitempty"`
+}
+
+type ProgramSlotDiff struct {
+	Exercise string              `json:"exercise"`
+	Change   string              `json:"change"`
+	// Fields lists the changed attributes, e.g. "sets", "weight" or "position".
+	Fields   []string            `json:"fields,omitempty"`
+	From     *RequestProgramSlot `json:"from,omitempty"`
+	To       *RequestProgramSlot `json:"to,omitempty"`
+}
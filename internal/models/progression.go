@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// ProgressionLinear adds Increment after a session where every set hit the target.
+	ProgressionLinear = "linear"
+	// ProgressionDouble adds reps up to the top of the slot's rep range, then
+	// adds Increment and starts again at the bottom.
+	ProgressionDouble = "double"
+	// ProgressionWave cycles through WavePercents of a training max and raises
+	// the training max by Increment after each completed cycle.
+	ProgressionWave = "wave"
+)
+
+const (
+	ProgressionActionIncrease = "increase"
+	ProgressionActionAddReps  = "add_reps"
+	ProgressionActionAdvance  = "advance"
+	ProgressionActionHold     = "hold"
+	ProgressionActionDeload   = "deload"
+)
+
+// ProgressionRule belongs to one exercise of a program. After DeloadAfter
+// failed sessions in a row (0 disables it) the load drops by DeloadPercent.
+type ProgressionRule struct {
+	ID            int             `db:"id"`
+	ProgramID     int             `db:"program_id"`
+	ExerciseID    int             `db:"exercise_id"`
+	Type          string          `db:"type"`
+	Increment     float64         `db:"increment"`
+	DeloadAfter   int             `db:"deload_after"`
+	DeloadPercent float64         `db:"deload_percent"`
+	WavePercents  pq.Float64Array `db:"wave_percents"`
+	CreatedAt     time.Time       `db:"created_at"`
+}
+
+// ProgressionState is the target for the next session. Weight is the working
+// weight in kilograms, or the training max for wave rules.
+type ProgressionState struct {
+	ProgramID  int       `db:"program_id"`
+	ExerciseID int       `db:"exercise_id"`
+	Weight     float64   `db:"weight"`
+	Reps       int       `db:"reps"`
+	WaveStep   int       `db:"wave_step"`
+	Failures   int       `db:"failures"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// ProgressionEvent records one evaluation and why the target changed.
+type ProgressionEvent struct {
+	ID             int       `db:"id"`
+	ProgramID      int       `db:"program_id"`
+	ExerciseID     int       `db:"exercise_id"`
+	WorkoutID      *int      `db:"workout_id"`
+	Action         string    `db:"action"`
+	PreviousWeight float64   `db:"previous_weight"`
+	NewWeight      float64   `db:"new_weight"`
+	PreviousReps   int       `db:"previous_reps"`
+	NewReps        int       `db:"new_reps"`
+	Reason         string    `db:"reason"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+type RequestProgressionRule struct {
+	Exercise      string    `json:"exercise" example:"Barbell Squat"`
+	Type          string    `json:"type" example:"linear"`
+	// Increment defaults for linear and double rules to the plate or
+	// dumbbell increment of the default equipment profile.
+	Increment     float64   `json:"increment" example:"2.5"`
+	DeloadAfter   int       `json:"deload_after" example:"3"`
+	DeloadPercent *float64  `json:"deload_percent,omitempty" example:"10"`
+	WavePercents  []float64 `json:"wave_percents,omitempty"`
+	// StartWeight is the first target (the training max for wave rules).
+	// Defaults to the slot's weight or the estimated one-rep max.
+	StartWeight   *float64  `json:"start_weight,omitempty"`
+	Unit          string    `json:"unit" example:"kg"`
+}
+
+type ProgressionTarget struct {
+	Sets    int     `json:"sets"`
+	Reps    int     `json:"reps"`
+	Weight  float64 `json:"weight"`
+	// TrainingMax and WaveStep are set for wave rules.
+	TrainingMax *float64 `json:"training_max,omitempty"`
+	WaveStep    *int     `json:"wave_step,omitempty"`
+}
+
+type ResponseProgressionRule struct {
+	Exercise      string            `json:"exercise"`
+	Type          string            `json:"type"`
+	Increment     float64           `json:"increment"`
+	DeloadAfter   int               `json:"deload_after"`
+	DeloadPercent float64           `json:"deload_percent"`
+	WavePercents  []float64         `json:"wave_percents,omitempty"`
+	Next          ProgressionTarget `json:"next"`
+	Failures      int               `json:"failures"`
+	Unit          string            `json:"unit"`
+}
+
+type ResponseProgressionEvent struct {
+	Exercise       string    `json:"exercise"`
+	WorkoutID      *int      `json:"workout_id"`
+	Action         string    `json:"action"`
+	PreviousWeight float64   `json:"previous_weight"`
+	NewWeight      float64   `json:"new_weight"`
+	PreviousReps   int       `json:"previous_reps"`
+	NewReps        int       `json:"new_reps"`
+	Reason         string    `json:"reason"`
+	Unit           string    `json:"unit"`
+	CreatedAt      time.Time `json:"created_at"`
+}
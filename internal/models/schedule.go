@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	SessionStatusPlanned   = "planned"
+	SessionStatusCompleted = "completed"
+	SessionStatusSkipped   = "skipped"
+	SessionStatusMissed    = "missed"
+)
+
+// Reschedule policies decide what happens to a planned session whose date
+// has passed without a workout.
+const (
+	// ReschedulePolicyNone marks the session missed.
+	ReschedulePolicyNone = "none"
+	// ReschedulePolicyNextFreeDay moves it to the next day without a session,
+	// up to a week after its original date.
+	ReschedulePolicyNextFreeDay = "next_free_day"
+	// ReschedulePolicyShift marks it missed and carries its program day over
+	// to the next date of the recurring schedule, pushing the rotation back.
+	// One-off sessions are moved as with next_free_day.
+	ReschedulePolicyShift = "shift"
+)
+
+// ScheduleRule plans program days on the given weekdays (0 is Sunday). With
+// DayIndex nil the dates rotate through all program days in order; otherwise
+// every date gets that day. IndexOffset counts sessions shifted by the
+// shift policy.
+type ScheduleRule struct {
+	ID                int           `db:"id"`
+	UserID            int           `db:"user_id"`
+	ProgramID         int           `db:"program_id"`
+	Weekdays          pq.Int64Array `db:"weekdays"`
+	StartDate         time.Time     `db:"start_date"`
+	EndDate           *time.Time    `db:"end_date"`
+	DayIndex          *int          `db:"day_index"`
+	IndexOffset       int           `db:"index_offset"`
+	MaterializedUntil *time.Time    `db:"materialized_until"`
+	CreatedAt         time.Time     `db:"created_at"`
+}
+
+// ScheduledSession is one planned training day. DayIndex is the 1-based
+// position of the program day counted across phases and weeks. Occurrence
+// numbers the dates of a rule from 0.
+type ScheduledSession struct {
+	ID              int        `db:"id"`
+	UserID          int        `db:"user_id"`
+	ProgramID       int        `db:"program_id"`
+	RuleID          *int       `db:"rule_id"`
+	Occurrence      *int       `db:"occurrence"`
+	DayIndex        int        `db:"day_index"`
+	Date            time.Time  `db:"date"`
+	Status          string     `db:"status"`
+	WorkoutID       *int       `db:"workout_id"`
+	RescheduledFrom *time.Time `db:"rescheduled_from"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
+}
+
+type RequestScheduleSession struct {
+	ProgramID int    `json:"program_id" binding:"required"`
+	// Day is the 1-based program day counted across phases and weeks.
+	Day       int    `json:"day" example:"1"`
+	Date      string `json:"date" binding:"required" example:"2026-10-19"`
+}
+
+type RequestScheduleRule struct {
+	ProgramID int      `json:"program_id" binding:"required"`
+	Weekdays  []string `json:"weekdays" binding:"required" example:"mon,wed,fri"`
+	StartDate string   `json:"start_date" example:"2026-10-19"`
+	EndDate   string   `json:"end_date,omitempty" example:"2026-12-31"`
+	// Day pins every date to one program day; omit it to rotate through all days.
+	Day       *int     `json:"day,omitempty"`
+}
+
+type ResponseScheduleRule struct {
+	ID        int        `json:"id"`
+	ProgramID int        `json:"program_id"`
+	Program   string     `json:"program"`
+	Weekdays  []string   `json:"weekdays"`
+	StartDate time.Time  `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+	Day       *int       `json:"day,omitempty"`
+}
+
+const (
+	ScheduleEntrySession = "session"
+	// ScheduleEntryWorkout is a completed workout not linked to a session.
+	ScheduleEntryWorkout = "workout"
+)
+
+type ScheduleEntry struct {
+	Date            time.Time  `json:"date"`
+	Kind            string     `json:"kind"`
+	Status          string     `json:"status"`
+	SessionID       *int       `json:"session_id,omitempty"`
+	RuleID          *int       `json:"rule_id,omitempty"`
+	ProgramID       *int       `json:"program_id,omitempty"`
+	Program         string     `json:"program,omitempty"`
+	Day             *int       `json:"day,omitempty"`
+	DayName         string     `json:"day_name,omitempty"`
+	WorkoutID       *int       `json:"workout_id,omitempty"`
+	RescheduledFrom *time.Time `json:"rescheduled_from,omitempty"`
+}
+
+// ScheduleSessionRow is a session joined with its program name.
+type ScheduleSessionRow struct {
+	ScheduledSession
+	ProgramName string `db:"program_name"`
+}
+
+// ScheduleWorkoutRow is a workout in the schedule range without a session.
+type ScheduleWorkoutRow struct {
+	ID          int       `db:"id"`
+	Date        time.Time `db:"date"`
+	ProgramID   *int      `db:"program_id"`
+	ProgramName *string   `db:"program_name"`
+}
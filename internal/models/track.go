@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// WorkoutTrack keeps the uploaded activity file so the route can be rendered later.
+type WorkoutTrack struct {
+	ID          int       `db:"id"`
+	UserID      int       `db:"user_id"`
+	WorkoutID   int       `db:"workout_id"`
+	Format      string    `db:"format"`
+	ContentHash string    `db:"content_hash"`
+	Data        []byte    `db:"data"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+type ResponseImportWorkout struct {
+	WorkoutID int    `json:"workout_id"`
+	Format    string `json:"format"`
+	Sport     string `json:"sport"`
+	Exercise  string `json:"exercise,omitempty"`
+	// Unmapped lists strength sets whose FIT category has no catalog exercise; they were skipped.
+	Unmapped  []UnmappedItem `json:"unmapped,omitempty"`
+}
+
+type UnmappedItem struct {
+	Category string `json:"category"`
+	Sets     int    `json:"sets"`
+}
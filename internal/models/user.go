@@ -11,6 +11,17 @@ type User struct {
 	Gender       string    `json:"gender" db:"gender"`
 	Height       int       `json:"height" db:"height"`
 	Weight       float64   `json:"weight" db:"weight"`
+	TOTPSecret   string    `json:"-" db:"totp_secret"`
+	TOTPEnabled  bool      `json:"two_factor_enabled" db:"totp_enabled"`
+	// TOTPLastStep is the period of the last accepted code; older codes are rejected.
+	TOTPLastStep int64     `json:"-" db:"totp_last_step"`
+	TokenVersion int       `json:"-" db:"token_version"`
+	EmailVerified bool     `json:"email_verified" db:"email_verified"`
+	UnitSystem   string    `json:"unit_system" db:"unit_system"`
+	// ReschedulePolicy handles missed scheduled sessions: none, next_free_day or shift.
+	ReschedulePolicy string `json:"reschedule_policy" db:"reschedule_policy"`
+	// DeletionScheduledAt is set while the account is waiting to be erased.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" db:"deletion_scheduled_at"`
 	CreatedAt    time.Time `json:"-" db:"created_at"`
 }
 
@@ -22,16 +33,28 @@ type RequestCreateUser struct {
 	Gender   string `json:"gender"`
 	Height   int    `json:"height"`
 	Weight   float64 `json:"weight"`
+	Unit     string  `json:"unit" example:"kg"`
 }
 
+// RequestUpdateUser has partial-update semantics: only fields present in the
+// request body are changed.
 type RequestUpdateUser struct {
-	ID       int  `json:"id"`
-	Name     string  `json:"name"`
-	Email    string  `json:"email"`
-	Age      int     `json:"age"`
-	Gender   string  `json:"gender"`
-	Height   int     `json:"height"`
-	Weight   float64 `json:"weight"`
+	Name     *string  `json:"name"`
+	Email    *string  `json:"email"`
+	Age      *int     `json:"age"`
+	Gender   *string  `json:"gender"`
+	Height   *int     `json:"height"`
+	Weight   *float64 `json:"weight"`
+	// UnitSystem changes the stored preference (metric or imperial).
+	UnitSystem *string `json:"unit_system"`
+	ReschedulePolicy *string `json:"reschedule_policy" example:"next_free_day"`
+	// Unit is the unit of Weight in this request; defaults to the preference.
+	Unit     string   `json:"unit" example:"kg"`
+}
+
+type ResponseUpdateUser struct {
+	User  User   `json:"user"`
+	Token string `json:"token,omitempty"`
 }
 
 type RequestLoginUser struct{
@@ -39,3 +62,74 @@ type RequestLoginUser struct{
 	Password string  `json:"password"`
 }
 
+type ResponseLogin struct{
+	Token             string `json:"token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type RequestLoginTwoFactor struct{
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type RequestTwoFactorCode struct{
+	Code string `json:"code" binding:"required"`
+}
+
+type RequestDisableTwoFactor struct{
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type ResponseTwoFactorEnroll struct{
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type ResponseRecoveryCodes struct{
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type RecoveryCode struct{
+	ID        int        `db:"id"`
+	UserID    int        `db:"user_id"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type RequestChangePassword struct{
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type RequestForgotPassword struct{
+	Email string `json:"email" binding:"required"`
+}
+
+type RequestResetPassword struct{
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type PasswordResetToken struct{
+	ID        int        `db:"id"`
+	UserID    int        `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type EmailVerificationToken struct{
+	ID        int        `db:"id"`
+	UserID    int        `db:"user_id"`
+	Email     string     `db:"email"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -7,28 +7,77 @@ import (
 type Workout struct {
 	ID        int          `json:"id" db:"id"`
 	UserID    int          `json:"user_id" db:"user_id"`
-	ProgramID int          `json:"program_id" db:"program_id"`
+	ProgramID *int         `json:"program_id" db:"program_id"`
 	Date      time.Time       `json:"date" db:"date"`
 	Exercises []ExerciseEntry `json:"exercises" db:"exercises"`
 	Duration  time.Duration   `json:"duration" db:"duration" swaggertype:"integer"`
 	Calories  float64         `json:"calories" db:"calories"`
+	CaloriesEstimated *float64 `json:"calories_estimated" db:"calories_estimated"`
+	CaloriesOverride  *float64 `json:"calories_override" db:"calories_override"`
 	CreatedAt time.Time       `json:"-" db:"created_at"`
+	// ProgramVersionID is the program version current when the workout was logged.
+	ProgramVersionID *int     `json:"program_version_id" db:"program_version_id"`
+	ExerciseGroups   ExerciseGroups `json:"exercise_groups" db:"exercise_groups"`
 }
 
 type RequestCreateWorkout struct {
+	// ProgramName is optional; workouts without a program are free sessions.
+	// ProgramID takes precedence and picks one of several same-named programs.
 	ProgramName string                 `json:"program_name"`
+	ProgramID   *int                   `json:"program_id,omitempty"`
+	// Exercises are stored in the order given; Groups describes the supersets,
+	// giant sets and circuits they refer to.
 	Exercises   []ExerciseRequestEntry `json:"exercises"`
+	Groups      []ExerciseGroup        `json:"groups,omitempty"`
 	Duration    string                 `json:"duration" binding:"required"`
-	Calories    float64                `json:"calories"`
+	// Calories overrides the server estimate; omit it to use the estimate.
+	Calories    *float64               `json:"calories"`
+	Unit        string                 `json:"unit" example:"kg"`
 }
 
 type RequestGetWorkout struct {
 	ID        int                    `json:"id"`
 	UserID    int                    `json:"user_id"`
-	ProgramID int                    `json:"program_id"`
+	ProgramID *int                   `json:"program_id"`
+	// ProgramVersion is the number of the program version the workout followed.
+	ProgramVersion *int              `json:"program_version,omitempty"`
 	Date      time.Time              `json:"date"`
 	Exercises []ExerciseRequestEntry `json:"exercises"`
+	Groups    []ExerciseGroup        `json:"groups,omitempty"`
 	Duration  string                 `json:"duration"`
 	Calories  float64                `json:"calories"`
+	CaloriesEstimated *float64       `json:"calories_estimated"`
+	CaloriesOverride  *float64       `json:"calories_override"`
+	Unit      string                 `json:"unit"`
 	CreatedAt time.Time              `json:"-"`
-}
\ No newline at end of file
+}
+type WeeklyDistance struct {
+	WeekStart time.Time `json:"week_start"`
+	Distance  float64   `json:"distance"`
+	Duration  string    `json:"duration"`
+	Sessions  int       `json:"sessions"`
+}
+
+type BestPace struct {
+	Exercise  string    `json:"exercise"`
+	Distance  string    `json:"distance"`
+	Pace      string    `json:"pace"`
+	WorkoutID int       `json:"workout_id"`
+	Date      time.Time `json:"date"`
+}
+
+type ResponseWorkoutStats struct {
+	Unit           string           `json:"unit"`
+	TotalDistance  float64          `json:"total_distance"`
+	WeeklyDistance []WeeklyDistance `json:"weekly_distance"`
+	BestPaces      []BestPace       `json:"best_paces"`
+}
+
+// CardioEntryStat is a cardio exercise entry joined with its workout and exercise name.
+type CardioEntryStat struct {
+	WorkoutID       int       `db:"workout_id"`
+	Date            time.Time `db:"date"`
+	Exercise        string    `db:"name"`
+	Distance        float64   `db:"distance"`
+	DurationSeconds *int      `db:"duration_seconds"`
+}
@@ -0,0 +1,208 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// AccountRepository holds personal data archives and scheduled account deletions.
+type AccountRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccountRepository(db *sqlx.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+func (r *AccountRepository) CreateDataExport(userID int) (int, error){
+	const op = "repositories.account_repository.CreateDataExport"
+	var exportID int
+
+	query := `INSERT INTO data_exports (user_id, status, created_at) VALUES ($1, $2, NOW()) RETURNING id`
+	if err := r.db.QueryRow(query, userID, models.DataExportStatusPending).Scan(&exportID); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return exportID, nil
+}
+
+func (r *AccountRepository) GetDataExport(exportID int, userID int) (*models.DataExport, error){
+	const op = "repositories.account_repository.GetDataExport"
+	var export models.DataExport
+
+	query := `SELECT * FROM data_exports WHERE id = $1 AND user_id = $2`
+	if err := r.db.Get(&export, query, exportID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &export, nil
+}
+
+// FindActiveDataExport returns an archive of the user that is still being built.
+func (r *AccountRepository) FindActiveDataExport(userID int) (*models.DataExport, bool, error){
+	const op = "repositories.account_repository.FindActiveDataExport"
+	var export models.DataExport
+
+	query := `SELECT * FROM data_exports WHERE user_id = $1 AND status IN ($2, $3) ORDER BY id DESC LIMIT 1`
+	if err := r.db.Get(&export, query, userID, models.DataExportStatusPending, models.DataExportStatusRunning); err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return &export, true, nil
+}
+
+func (r *AccountRepository) UpdateDataExportStatus(exportID int, status string) error{
+	const op = "repositories.account_repository.UpdateDataExportStatus"
+
+	query := `UPDATE data_exports SET status = $1 WHERE id = $2`
+	if _, err := r.db.Exec(query, status, exportID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *AccountRepository) FinishDataExport(export models.DataExport) error{
+	const op = "repositories.account_repository.FinishDataExport"
+
+	query := `UPDATE data_exports SET status = $1, file_path = $2, size_bytes = $3, error = $4, expires_at = $5,
+		finished_at = NOW() WHERE id = $6`
+	if _, err := r.db.Exec(query, export.Status, export.FilePath, export.SizeBytes, export.Error, export.ExpiresAt,
+		export.ID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// FailUnfinishedDataExports marks archives left pending or running by a previous process.
+func (r *AccountRepository) FailUnfinishedDataExports() error{
+	const op = "repositories.account_repository.FailUnfinishedDataExports"
+
+	query := `UPDATE data_exports SET status = $1, error = 'interrupted by a server restart', finished_at = NOW()
+		WHERE status IN ($2, $3)`
+	if _, err := r.db.Exec(query, models.DataExportStatusFailed, models.DataExportStatusPending,
+		models.DataExportStatusRunning); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *AccountRepository) GetExpiredDataExports(now time.Time) ([]models.DataExport, error){
+	const op = "repositories.account_repository.GetExpiredDataExports"
+	var exports []models.DataExport
+
+	query := `SELECT * FROM data_exports WHERE status = $1 AND expires_at <= $2`
+	if err := r.db.Select(&exports, query, models.DataExportStatusCompleted, now); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return exports, nil
+}
+
+// ExpireDataExport is called after the archive file has been removed.
+func (r *AccountRepository) ExpireDataExport(exportID int) error{
+	const op = "repositories.account_repository.ExpireDataExport"
+
+	query := `UPDATE data_exports SET status = $1, file_path = '' WHERE id = $2`
+	if _, err := r.db.Exec(query, models.DataExportStatusExpired, exportID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *AccountRepository) GetDataExportFiles(userID int) ([]string, error){
+	const op = "repositories.account_repository.GetDataExportFiles"
+	var paths []string
+
+	query := `SELECT file_path FROM data_exports WHERE user_id = $1 AND file_path <> ''`
+	if err := r.db.Select(&paths, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return paths, nil
+}
+
+func (r *AccountRepository) GetTokenMetadata(userID int) ([]models.TokenMetadata, error){
+	const op = "repositories.account_repository.GetTokenMetadata"
+	tokens := []models.TokenMetadata{}
+
+	query := `SELECT 'password_reset' AS kind, created_at, expires_at, used_at FROM password_reset_tokens WHERE user_id = $1
+		UNION ALL
+		SELECT 'email_verification', created_at, expires_at, used_at FROM email_verification_tokens WHERE user_id = $1
+		UNION ALL
+		SELECT 'recovery_code', created_at, NULL, used_at FROM recovery_codes WHERE user_id = $1
+		ORDER BY created_at`
+	if err := r.db.Select(&tokens, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return tokens, nil
+}
+
+func (r *AccountRepository) GetAuditEntries(userID int) ([]models.AuditEntry, error){
+	const op = "repositories.account_repository.GetAuditEntries"
+	entries := []models.AuditEntry{}
+
+	query := `SELECT id, user_id, event, COALESCE(ip, '') AS ip, COALESCE(details, '') AS details, created_at
+		FROM audit_log WHERE user_id = $1 ORDER BY created_at`
+	if err := r.db.Select(&entries, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return entries, nil
+}
+
+func (r *AccountRepository) ScheduleDeletion(userID int, at time.Time) error{
+	const op = "repositories.account_repository.ScheduleDeletion"
+
+	query := `UPDATE users SET deletion_scheduled_at = $1 WHERE id = $2`
+	if _, err := r.db.Exec(query, at, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// CancelDeletion reports whether a deletion was pending.
+func (r *AccountRepository) CancelDeletion(userID int) (bool, error){
+	const op = "repositories.account_repository.CancelDeletion"
+
+	query := `UPDATE users SET deletion_scheduled_at = NULL WHERE id = $1 AND deletion_scheduled_at IS NOT NULL`
+	res, err := r.db.Exec(query, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n > 0, nil
+}
+
+func (r *AccountRepository) GetUsersDueForDeletion(now time.Time) ([]int, error){
+	const op = "repositories.account_repository.GetUsersDueForDeletion"
+	var userIDs []int
+
+	query := `SELECT id FROM users WHERE deletion_scheduled_at <= $1 ORDER BY deletion_scheduled_at`
+	if err := r.db.Select(&userIDs, query, now); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return userIDs, nil
+}
+
+// DeleteUser erases the account; everything else goes with it through
+// ON DELETE CASCADE. The schedule is checked again so a deletion cancelled
+// after the user was picked up is not carried out.
+func (r *AccountRepository) DeleteUser(userID int, now time.Time) (bool, error){
+	const op = "repositories.account_repository.DeleteUser"
+
+	query := `DELETE FROM users WHERE id = $1 AND deletion_scheduled_at <= $2`
+	res, err := r.db.Exec(query, userID, now)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) SaveEntry(entry models.AuditEntry) error{
+	const op = "internal.repositories.SaveEntry"
+
+	query := `INSERT INTO audit_log (user_id, event, ip, details, created_at)
+	        VALUES ($1, $2, $3, $4, NOW())`
+	if _, err := r.db.Exec(query, entry.UserID, entry.Event, entry.IP, entry.Details); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
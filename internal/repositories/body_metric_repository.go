@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type BodyMetricRepository struct {
+	db *sqlx.DB
+}
+
+func NewBodyMetricRepository(db *sqlx.DB) *BodyMetricRepository {
+	return &BodyMetricRepository{db: db}
+}
+
+func (r *BodyMetricRepository) SaveMetric(metric models.BodyMetric) (int, error){
+	const op = "internal.repositories.SaveMetric"
+	var metricID int
+
+	query := `INSERT INTO body_metrics (user_id, measured_at, weight, body_fat, waist, chest, arms, thighs, resting_heart_rate, created_at)
+	        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW()) RETURNING id`
+
+	if err := r.db.QueryRow(query, metric.UserID, metric.MeasuredAt, metric.Weight, metric.BodyFat, metric.Waist,
+		metric.Chest, metric.Arms, metric.Thighs, metric.RestingHeartRate).Scan(&metricID); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := r.SyncUserWeight(metric.UserID); err != nil{
+		return metricID, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return metricID, nil
+}
+
+func (r *BodyMetricRepository) UpdateMetric(metric models.BodyMetric, metricID int) (int, error){
+	const op = "internal.repositories.UpdateMetric"
+
+	query := `UPDATE body_metrics SET measured_at = $1, weight = $2, body_fat = $3, waist = $4, chest = $5,
+	        arms = $6, thighs = $7, resting_heart_rate = $8
+	        WHERE id = $9 AND user_id = $10 RETURNING id`
+
+	if err := r.db.QueryRow(query, metric.MeasuredAt, metric.Weight, metric.BodyFat, metric.Waist, metric.Chest,
+		metric.Arms, metric.Thighs, metric.RestingHeartRate, metricID, metric.UserID).Scan(&metricID); err != nil{
+		return 0, fmt.Errorf("%s: failed to update metric: %w", op, err)
+	}
+
+	if err := r.SyncUserWeight(metric.UserID); err != nil{
+		return metricID, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return metricID, nil
+}
+
+func (r *BodyMetricRepository) GetMetricByID(metricID int, userID int) (*models.BodyMetric, error){
+	const op = "internal.repositories.GetMetricByID"
+	var metric models.BodyMetric
+
+	query := `SELECT * FROM body_metrics WHERE id = $1 AND user_id = $2`
+	if err := r.db.Get(&metric, query, metricID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &metric, nil
+}
+
+func (r *BodyMetricRepository) GetMetrics(userID int, from, to time.Time) ([]models.BodyMetric, error){
+	const op = "internal.repositories.GetMetrics"
+	var metrics []models.BodyMetric
+
+	query := `SELECT * FROM body_metrics WHERE user_id = $1 AND measured_at >= $2 AND measured_at < $3
+	          ORDER BY measured_at`
+	if err := r.db.Select(&metrics, query, userID, from, to); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return metrics, nil
+}
+
+func (r *BodyMetricRepository) DeleteMetric(metricID int, userID int) (int, error){
+	const op = "internal.repositories.DeleteMetric"
+	var deletedID int
+
+	query := `DELETE FROM body_metrics WHERE id = $1 AND user_id = $2 RETURNING id`
+	if err := r.db.Get(&deletedID, query, metricID, userID); err != nil{
+		return 0, fmt.Errorf("%s: failed to delete metric or unauthorized access: %w", op, err)
+	}
+
+	if err := r.SyncUserWeight(userID); err != nil{
+		return deletedID, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deletedID, nil
+}
+
+// SyncUserWeight copies the most recent logged weight into users.weight so
+// User.Weight always reflects the latest entry. Users without entries keep their value.
+func (r *BodyMetricRepository) SyncUserWeight(userID int) error{
+	const op = "internal.repositories.SyncUserWeight"
+
+	query := `UPDATE users SET weight = latest.weight
+	          FROM (SELECT weight FROM body_metrics
+	                WHERE user_id = $1 AND weight IS NOT NULL
+	                ORDER BY measured_at DESC, id DESC LIMIT 1) AS latest
+	          WHERE users.id = $1`
+	if _, err := r.db.Exec(query, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
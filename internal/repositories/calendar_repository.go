@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type CalendarRepository struct {
+	db *sqlx.DB
+}
+
+func NewCalendarRepository(db *sqlx.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// SaveFeed replaces the user's feed token, so the previous URL stops working.
+func (r *CalendarRepository) SaveFeed(userID int, tokenHash string) (*models.CalendarFeed, error){
+	const op = "repositories.calendar_repository.SaveFeed"
+	var feed models.CalendarFeed
+
+	query := `INSERT INTO calendar_feeds (user_id, token_hash, created_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at
+		RETURNING *`
+	if err := r.db.Get(&feed, query, userID, tokenHash); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &feed, nil
+}
+
+func (r *CalendarRepository) GetFeed(userID int) (*models.CalendarFeed, error){
+	const op = "repositories.calendar_repository.GetFeed"
+	var feed models.CalendarFeed
+
+	query := `SELECT * FROM calendar_feeds WHERE user_id = $1`
+	if err := r.db.Get(&feed, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &feed, nil
+}
+
+func (r *CalendarRepository) GetFeedByTokenHash(tokenHash string) (*models.CalendarFeed, error){
+	const op = "repositories.calendar_repository.GetFeedByTokenHash"
+	var feed models.CalendarFeed
+
+	query := `SELECT * FROM calendar_feeds WHERE token_hash = $1`
+	if err := r.db.Get(&feed, query, tokenHash); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &feed, nil
+}
+
+// DeleteFeed reports whether the user had a feed.
+func (r *CalendarRepository) DeleteFeed(userID int) (bool, error){
+	const op = "repositories.calendar_repository.DeleteFeed"
+
+	res, err := r.db.Exec(`DELETE FROM calendar_feeds WHERE user_id = $1`, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type EmailVerificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewEmailVerificationRepository(db *sqlx.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// SaveToken replaces any outstanding token of the user so only the latest email link works.
+func (r *EmailVerificationRepository) SaveToken(userID int, email, tokenHash string, expiresAt time.Time) error{
+	const op = "internal.repositories.email_verification.SaveToken"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM email_verification_tokens WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := `INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at, created_at)
+	        VALUES ($1, $2, $3, $4, NOW())`
+	if _, err := tx.Exec(query, userID, email, tokenHash, expiresAt); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *EmailVerificationRepository) ConsumeToken(tokenHash string) (*models.EmailVerificationToken, error){
+	const op = "internal.repositories.email_verification.ConsumeToken"
+	var token models.EmailVerificationToken
+
+	query := `UPDATE email_verification_tokens SET used_at = NOW()
+	          WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	          RETURNING *`
+	if err := r.db.Get(&token, query, tokenHash); err != nil{
+		return nil, fmt.Errorf("%s: invalid or expired token: %w", op, err)
+	}
+	return &token, nil
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type EquipmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewEquipmentRepository(db *sqlx.DB) *EquipmentRepository{
+	return &EquipmentRepository{db: db}
+}
+
+// CreateProfile saves a profile. It becomes the default when asked to or
+// when the user has no default profile yet.
+func (r *EquipmentRepository) CreateProfile(profile models.EquipmentProfile) (int, error){
+	const op = "internal.repositories.CreateProfile"
+	var id int
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if profile.IsDefault{
+		if _, err := tx.Exec(`UPDATE equipment_profiles SET is_default = false WHERE user_id = $1 AND is_default`,
+			profile.UserID); err != nil{
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	query := `INSERT INTO equipment_profiles (user_id, name, equipment, plate_increment, dumbbell_increment, is_default, created_at)
+		VALUES ($1, $2, $3, $4, $5,
+		$6 OR NOT EXISTS (SELECT 1 FROM equipment_profiles WHERE user_id = $1 AND is_default), NOW()) RETURNING id`
+	if err := tx.QueryRow(query, profile.UserID, profile.Name, profile.Equipment, profile.PlateIncrement,
+		profile.DumbbellIncrement, profile.IsDefault).Scan(&id); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// UpdateProfile replaces a profile's settings. IsDefault can only make the
+// profile the default; the default moves away by making another one default.
+func (r *EquipmentRepository) UpdateProfile(profile models.EquipmentProfile) (int, error){
+	const op = "internal.repositories.UpdateProfile"
+	var id int
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if profile.IsDefault{
+		if _, err := tx.Exec(`UPDATE equipment_profiles SET is_default = false WHERE user_id = $1 AND id <> $2 AND is_default`,
+			profile.UserID, profile.ID); err != nil{
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	query := `UPDATE equipment_profiles SET name = $1, equipment = $2, plate_increment = $3, dumbbell_increment = $4,
+		is_default = is_default OR $5
+		WHERE id = $6 AND user_id = $7 RETURNING id`
+	if err := tx.QueryRow(query, profile.Name, profile.Equipment, profile.PlateIncrement, profile.DumbbellIncrement,
+		profile.IsDefault, profile.ID, profile.UserID).Scan(&id); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (r *EquipmentRepository) GetProfiles(userID int) ([]models.EquipmentProfile, error){
+	const op = "internal.repositories.GetProfiles"
+	profiles := []models.EquipmentProfile{}
+
+	query := `SELECT * FROM equipment_profiles WHERE user_id = $1 ORDER BY created_at, id`
+	if err := r.db.Select(&profiles, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return profiles, nil
+}
+
+func (r *EquipmentRepository) GetProfile(profileID int, userID int) (*models.EquipmentProfile, error){
+	const op = "internal.repositories.GetProfile"
+	var profile models.EquipmentProfile
+
+	query := `SELECT * FROM equipment_profiles WHERE id = $1 AND user_id = $2`
+	if err := r.db.Get(&profile, query, profileID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &profile, nil
+}
+
+func (r *EquipmentRepository) GetDefaultProfile(userID int) (*models.EquipmentProfile, error){
+	const op = "internal.repositories.GetDefaultProfile"
+	var profile models.EquipmentProfile
+
+	query := `SELECT * FROM equipment_profiles WHERE user_id = $1 AND is_default`
+	if err := r.db.Get(&profile, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &profile, nil
+}
+
+// DeleteProfile removes a profile. When it was the default, the oldest
+// remaining profile takes over.
+func (r *EquipmentRepository) DeleteProfile(profileID int, userID int) (int, error){
+	const op = "internal.repositories.DeleteProfile"
+	var wasDefault bool
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM equipment_profiles WHERE id = $1 AND user_id = $2 RETURNING is_default`
+	if err := tx.QueryRow(query, profileID, userID).Scan(&wasDefault); err != nil{
+		return 0, fmt.Errorf("%s: failed to delete profile or unauthorized access: %w", op, err)
+	}
+
+	if wasDefault{
+		promoteQuery := `UPDATE equipment_profiles SET is_default = true
+			WHERE id = (SELECT id FROM equipment_profiles WHERE user_id = $1 ORDER BY created_at, id LIMIT 1)`
+		if _, err := tx.Exec(promoteQuery, userID); err != nil{
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return profileID, nil
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// ExportRepository reads rows one at a time and hands them to a callback so
+// an export never holds a user's whole history in memory.
+type ExportRepository struct {
+	db *sqlx.DB
+}
+
+func NewExportRepository(db *sqlx.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+func (r *ExportRepository) StreamWorkoutRows(userID int, from, to time.Time, fn func(models.ExportWorkoutRow) error) error{
+	const op = "internal.repositories.StreamWorkoutRows"
+
+	query := `SELECT w.id AS workout_id, w.date, p.name AS program_name, COALESCE(w.duration, 0) AS duration,
+	        COALESCE(w.calories, 0) AS calories, e.name AS exercise_name, e.type AS exercise_type, ee.sets, ee.reps,
+	        ee.weight, ee.distance, ee.duration_seconds, ee.elevation_gain, ee.avg_heart_rate, ee.max_heart_rate
+	        FROM workouts w
+	        LEFT JOIN programs p ON p.id = w.program_id
+	        LEFT JOIN exercises_entry ee ON ee.workout_id = w.id
+	        LEFT JOIN exercises e ON e.id = ee.exercise_id
+	        WHERE w.user_id = $1 AND w.date >= $2 AND w.date < $3
+	        ORDER BY w.date, w.id, ee.position, ee.id`
+
+	rows, err := r.db.Queryx(query, userID, from, to)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var row models.ExportWorkoutRow
+		if err := rows.StructScan(&row); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fn(row); err != nil{
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *ExportRepository) StreamProgramRows(userID int, fn func(models.ExportProgramRow) error) error{
+	const op = "internal.repositories.StreamProgramRows"
+
+	query := `SELECT p.id AS program_id, p.name AS program_name, p.created_at, ph.name AS phase, w.position AS week,
+	        d.name AS day, e.name AS exercise_name, s.sets, s.reps, s.reps_max, s.weight, s.percent_e1rm
+	        FROM programs p
+	        LEFT JOIN program_phases ph ON ph.program_id = p.id
+	        LEFT JOIN program_weeks w ON w.phase_id = ph.id
+	        LEFT JOIN program_days d ON d.week_id = w.id
+	        LEFT JOIN program_slots s ON s.day_id = d.id
+	        LEFT JOIN exercises e ON e.id = s.exercise_id
+	        WHERE p.user_id = $1
+	        ORDER BY p.id, ph.position, w.position, d.position, s.position`
+
+	rows, err := r.db.Queryx(query, userID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var row models.ExportProgramRow
+		if err := rows.StructScan(&row); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fn(row); err != nil{
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *ExportRepository) StreamBodyMetrics(userID int, from, to time.Time, fn func(models.BodyMetric) error) error{
+	const op = "internal.repositories.StreamBodyMetrics"
+
+	query := `SELECT * FROM body_metrics WHERE user_id = $1 AND measured_at >= $2 AND measured_at < $3
+	          ORDER BY measured_at`
+
+	rows, err := r.db.Queryx(query, userID, from, to)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var metric models.BodyMetric
+		if err := rows.StructScan(&metric); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fn(metric); err != nil{
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
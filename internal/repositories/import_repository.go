@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ImportRepository struct {
+	db *sqlx.DB
+}
+
+func NewImportRepository(db *sqlx.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+func (r *ImportRepository) CreateJob(job models.ImportJob) (int, error){
+	const op = "internal.repositories.CreateJob"
+	var jobID int
+
+	query := `INSERT INTO import_jobs (user_id, source, status, dry_run, content_hash, total_workouts, unmapped, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, '[]', NOW()) RETURNING id`
+	if err := r.db.QueryRow(query, job.UserID, job.Source, job.Status, job.DryRun, job.ContentHash,
+		job.TotalWorkouts).Scan(&jobID); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return jobID, nil
+}
+
+func (r *ImportRepository) GetJob(jobID int, userID int) (*models.ImportJob, error){
+	const op = "internal.repositories.GetJob"
+	var job models.ImportJob
+
+	query := `SELECT * FROM import_jobs WHERE id = $1 AND user_id = $2`
+	if err := r.db.Get(&job, query, jobID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &job, nil
+}
+
+// FindImportedFile returns a real (not dry-run) import of the same file that
+// hasn't failed. Failed imports saved no workouts, so the file can be retried.
+func (r *ImportRepository) FindImportedFile(userID int, hash string) (int, bool, error){
+	const op = "internal.repositories.FindImportedFile"
+	var jobID int
+
+	query := `SELECT id FROM import_jobs WHERE user_id = $1 AND content_hash = $2 AND dry_run = false AND status <> $3
+		ORDER BY id DESC LIMIT 1`
+	if err := r.db.Get(&jobID, query, userID, hash, models.ImportStatusFailed); err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return jobID, true, nil
+}
+
+func (r *ImportRepository) UpdateProgress(job models.ImportJob) error{
+	const op = "internal.repositories.UpdateProgress"
+
+	query := `UPDATE import_jobs SET status = $1, processed_workouts = $2, created_workouts = $3, skipped_workouts = $4
+		WHERE id = $5`
+	if _, err := r.db.Exec(query, job.Status, job.ProcessedWorkouts, job.CreatedWorkouts, job.SkippedWorkouts, job.ID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// FinishJob records the outcome of a job and saves its workouts in the same
+// transaction, so a job is either completed with all its workouts or left
+// without any.
+func (r *ImportRepository) FinishJob(job models.ImportJob, workouts []models.Workout) error{
+	const op = "internal.repositories.FinishJob"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	for _, workout := range workouts{
+		if _, err := insertImportedWorkout(tx, workout); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	query := `UPDATE import_jobs SET status = $1, processed_workouts = $2, created_workouts = $3, skipped_workouts = $4,
+		unmapped = $5, preview = $6, error = $7, finished_at = NOW() WHERE id = $8`
+	if _, err := tx.Exec(query, job.Status, job.ProcessedWorkouts, job.CreatedWorkouts, job.SkippedWorkouts,
+		job.Unmapped, job.Preview, job.Error, job.ID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// FailUnfinishedJobs marks jobs left pending or running by a previous process.
+func (r *ImportRepository) FailUnfinishedJobs() error{
+	const op = "internal.repositories.FailUnfinishedJobs"
+
+	query := `UPDATE import_jobs SET status = $1, error = 'interrupted by a server restart', finished_at = NOW()
+		WHERE status IN ($2, $3)`
+	if _, err := r.db.Exec(query, models.ImportStatusFailed, models.ImportStatusPending, models.ImportStatusRunning); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *ImportRepository) GetMappings(userID int) ([]models.ExerciseNameMapping, error){
+	const op = "internal.repositories.GetMappings"
+	mappings := []models.ExerciseNameMapping{}
+
+	query := `SELECT m.id, m.user_id, m.source_name, m.exercise_id, e.name AS exercise_name, m.created_at
+		FROM exercise_name_mappings m JOIN exercises e ON e.id = m.exercise_id
+		WHERE m.user_id = $1 ORDER BY m.source_name`
+	if err := r.db.Select(&mappings, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return mappings, nil
+}
+
+// SaveMapping creates or replaces the mapping for a source name (case-insensitive).
+func (r *ImportRepository) SaveMapping(userID int, sourceName string, exerciseID int) error{
+	const op = "internal.repositories.SaveMapping"
+
+	query := `INSERT INTO exercise_name_mappings (user_id, source_name, exercise_id, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, lower(source_name)) DO UPDATE SET source_name = EXCLUDED.source_name, exercise_id = EXCLUDED.exercise_id`
+	if _, err := r.db.Exec(query, userID, sourceName, exerciseID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *ImportRepository) DeleteMapping(userID int, sourceName string) error{
+	const op = "internal.repositories.DeleteMapping"
+
+	res, err := r.db.Exec(`DELETE FROM exercise_name_mappings WHERE user_id = $1 AND lower(source_name) = lower($2)`, userID, sourceName)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0{
+		return fmt.Errorf("%s: mapping not found", op)
+	}
+	return nil
+}
+
+// GetExercisesByLowerNames matches catalog names case-insensitively.
+func (r *ImportRepository) GetExercisesByLowerNames(names []string) ([]models.Exercise, error){
+	const op = "internal.repositories.GetExercisesByLowerNames"
+	var exercises []models.Exercise
+
+	lower := make([]string, 0, len(names))
+	for _, name := range names{
+		lower = append(lower, strings.ToLower(name))
+	}
+
+	query := `SELECT id, name, type FROM exercises WHERE lower(name) = ANY($1)`
+	if err := r.db.Select(&exercises, query, pq.Array(lower)); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return exercises, nil
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type LibraryRepository struct {
+	db *sqlx.DB
+}
+
+func NewLibraryRepository(db *sqlx.DB) *LibraryRepository{
+	return &LibraryRepository{db: db}
+}
+
+const libraryProgramColumns = `p.*, u.name AS author,
+	(SELECT COUNT(*) FROM program_followers f WHERE f.program_id = p.id) AS followers_count,
+	(SELECT COUNT(*) FROM programs fk WHERE fk.forked_from_id = p.id) AS forks_count`
+
+// ListPublicPrograms returns public programs having all of tags, most
+// followed first. A non-empty search matches part of the name, ignoring case.
+func (r *LibraryRepository) ListPublicPrograms(search string, tags []string, limit int, offset int) ([]models.LibraryProgramSummary, error){
+	const op = "internal.repositories.ListPublicPrograms"
+	programs := []models.LibraryProgramSummary{}
+
+	query := `SELECT p.id, p.name, u.name AS author, p.tags, p.created_at,
+		(SELECT COUNT(DISTINCT s.exercise_id) FROM program_slots s
+			JOIN program_days d ON d.id = s.day_id
+			JOIN program_weeks w ON w.id = d.week_id
+			JOIN program_phases ph ON ph.id = w.phase_id
+			WHERE ph.program_id = p.id) AS exercise_count,
+		(SELECT COUNT(*) FROM program_followers f WHERE f.program_id = p.id) AS followers_count,
+		(SELECT COUNT(*) FROM programs fk WHERE fk.forked_from_id = p.id) AS forks_count
+		FROM programs p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.visibility = 'public' AND p.tags @> $1 AND ($2 = '' OR p.name ILIKE '%' || $2 || '%')
+		ORDER BY followers_count DESC, p.created_at DESC, p.id DESC
+		LIMIT $3 OFFSET $4`
+	if err := r.db.Select(&programs, query, pq.Array(tags), search, limit, offset); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return programs, nil
+}
+
+func (r *LibraryRepository) GetPublicProgram(programID int) (*models.LibraryProgramDB, error){
+	const op = "internal.repositories.GetPublicProgram"
+	var program models.LibraryProgramDB
+
+	query := `SELECT ` + libraryProgramColumns + ` FROM programs p JOIN users u ON u.id = p.user_id
+		WHERE p.id = $1 AND p.visibility = 'public'`
+	if err := r.db.Get(&program, query, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &program, nil
+}
+
+// GetProgramByShareToken finds an unlisted or public program by its share link.
+func (r *LibraryRepository) GetProgramByShareToken(token string) (*models.LibraryProgramDB, error){
+	const op = "internal.repositories.GetProgramByShareToken"
+	var program models.LibraryProgramDB
+
+	query := `SELECT ` + libraryProgramColumns + ` FROM programs p JOIN users u ON u.id = p.user_id
+		WHERE p.share_token = $1 AND p.visibility IN ('unlisted', 'public')`
+	if err := r.db.Get(&program, query, token); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &program, nil
+}
+
+func (r *LibraryRepository) Follow(programID int, userID int) error{
+	const op = "internal.repositories.Follow"
+
+	query := `INSERT INTO program_followers (program_id, user_id, created_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (program_id, user_id) DO NOTHING`
+	if _, err := r.db.Exec(query, programID, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *LibraryRepository) Unfollow(programID int, userID int) error{
+	const op = "internal.repositories.Unfollow"
+
+	query := `DELETE FROM program_followers WHERE program_id = $1 AND user_id = $2`
+	if _, err := r.db.Exec(query, programID, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *LibraryRepository) GetFollowers(programID int, userID int) (*models.ResponseProgramFollowers, error){
+	const op = "internal.repositories.GetFollowers"
+	var followers models.ResponseProgramFollowers
+
+	query := `SELECT COUNT(*) AS followers_count, COALESCE(BOOL_OR(user_id = $2), false) AS following
+		FROM program_followers WHERE program_id = $1`
+	if err := r.db.QueryRow(query, programID, userID).Scan(&followers.FollowersCount, &followers.Following); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &followers, nil
+}
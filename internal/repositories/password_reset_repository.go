@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type PasswordResetRepository struct {
+	db *sqlx.DB
+}
+
+func NewPasswordResetRepository(db *sqlx.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+func (r *PasswordResetRepository) SaveToken(userID int, tokenHash string, expiresAt time.Time) error{
+	const op = "internal.repositories.password_reset.SaveToken"
+
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+	        VALUES ($1, $2, $3, NOW())`
+	if _, err := r.db.Exec(query, userID, tokenHash, expiresAt); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ConsumeToken marks a valid token as used and returns it. Expired, used and
+// unknown tokens all produce an error.
+func (r *PasswordResetRepository) ConsumeToken(tokenHash string) (*models.PasswordResetToken, error){
+	const op = "internal.repositories.password_reset.ConsumeToken"
+	var token models.PasswordResetToken
+
+	query := `UPDATE password_reset_tokens SET used_at = NOW()
+	          WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	          RETURNING *`
+	if err := r.db.Get(&token, query, tokenHash); err != nil{
+		return nil, fmt.Errorf("%s: invalid or expired token: %w", op, err)
+	}
+	return &token, nil
+}
+
+func (r *PasswordResetRepository) InvalidateUserTokens(userID int) error{
+	const op = "internal.repositories.password_reset.InvalidateUserTokens"
+
+	query := `UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`
+	if _, err := r.db.Exec(query, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/jmoiron/sqlx"
@@ -20,69 +19,192 @@ func NewProgramRepository(db *sqlx.DB) *ProgramRepository{
 func (r *ProgramRepository) SaveProgram(program models.Program) (int, error){
 	const op = "internal.repositories.SaveProgram"
 
-	query := `INSERT INTO programs (user_id, name, created_at)
-	        VALUES($1, $2, NOW()) RETURNING id`
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
 
-	if err := r.db.QueryRow(query, program.UserID, program.Name).Scan(&program.ID); err != nil{
+	query := `INSERT INTO programs (user_id, name, created_at, forked_from_id, forked_from_name, forked_from_author)
+	        VALUES($1, $2, NOW(), $3, $4, $5) RETURNING id`
+
+	if err := tx.QueryRow(query, program.UserID, program.Name, program.ForkedFromID, program.ForkedFromName,
+		program.ForkedFromAuthor).Scan(&program.ID); err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err := r.SaveExercisesProgram(program.ID, program.Exercises); err != nil{
-		return program.ID, fmt.Errorf("%s: failed to save exercises: %w", op, err)
+	if err := saveProgramStructure(tx, program.ID, program.Phases); err != nil{
+		return 0, fmt.Errorf("%s: failed to save program structure: %w", op, err)
+	}
+	if err := saveProgramVersion(tx, program.ID); err != nil{
+		return 0, fmt.Errorf("%s: failed to save program version: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-	
 	return program.ID, nil
 }
 
-func (r *ProgramRepository) UpdateProgram(program models.Program, programID int) (int, error){
+// UpdateProgram replaces the name and the whole phase structure. The
+// previous state stays available as a program version. In the same
+// transaction the progression rules and state of the exercises in moveRules
+// are moved to their replacements, and those of dropRules are removed.
+func (r *ProgramRepository) UpdateProgram(program models.Program, programID int, moveRules map[int]int, dropRules []int) (int, error){
 	const op = "internal.repositories.UpdateProgram"
 
-	query := `UPDATE programs SET user_id = $1, name = $2, created_at = NOW()
-	          WHERE id = $3 AND user_id = $4
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE programs SET name = $1
+	          WHERE id = $2 AND user_id = $3
 			  RETURNING id`
 
-	if err := r.db.QueryRow(query, program.UserID, program.Name, programID, program.UserID).Scan(&program.ID); err != nil{
+	if err := tx.QueryRow(query, program.Name, programID, program.UserID).Scan(&program.ID); err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err := r.SaveExercisesProgram(program.ID, program.Exercises); err != nil{
-		return program.ID, fmt.Errorf("%s: failed to save program exercises: %w", op, err)
+	if _, err := tx.Exec(`DELETE FROM program_phases WHERE program_id = $1`, program.ID); err != nil{
+		return 0, fmt.Errorf("%s: failed to delete program structure: %w", op, err)
+	}
+	if err := saveProgramStructure(tx, program.ID, program.Phases); err != nil{
+		return 0, fmt.Errorf("%s: failed to save program structure: %w", op, err)
+	}
+	if err := saveProgramVersion(tx, program.ID); err != nil{
+		return 0, fmt.Errorf("%s: failed to save program version: %w", op, err)
+	}
+
+	for fromID, toID := range moveRules{
+		if _, err := tx.Exec(`UPDATE program_progression_rules SET exercise_id = $1 WHERE program_id = $2 AND exercise_id = $3`,
+			toID, program.ID, fromID); err != nil{
+			return 0, fmt.Errorf("%s: failed to move progression rule: %w", op, err)
+		}
+		if _, err := tx.Exec(`UPDATE program_progression_state SET exercise_id = $1 WHERE program_id = $2 AND exercise_id = $3`,
+			toID, program.ID, fromID); err != nil{
+			return 0, fmt.Errorf("%s: failed to move progression state: %w", op, err)
+		}
+	}
+	if len(dropRules) > 0{
+		if _, err := tx.Exec(`DELETE FROM program_progression_rules WHERE program_id = $1 AND exercise_id = ANY($2)`,
+			program.ID, pq.Array(dropRules)); err != nil{
+			return 0, fmt.Errorf("%s: failed to delete progression rules: %w", op, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM program_progression_state WHERE program_id = $1 AND exercise_id = ANY($2)`,
+			program.ID, pq.Array(dropRules)); err != nil{
+			return 0, fmt.Errorf("%s: failed to delete progression state: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-	
 	return program.ID, nil
 }
 
-func (r *ProgramRepository) SaveExercisesProgram(programID int, exercises []models.ExerciseProgramDB) error {
-	const op = "internal.repositories.SaveExercisesProgram"
+// saveProgramStructure inserts phases, weeks, days and slots; positions
+// follow the order of the slices, starting at 1.
+func saveProgramStructure(tx *sqlx.Tx, programID int, phases []models.ProgramPhase) error{
+	phaseQuery := `INSERT INTO program_phases (program_id, position, name) VALUES ($1, $2, $3) RETURNING id`
+	weekQuery := `INSERT INTO program_weeks (phase_id, position, name) VALUES ($1, $2, $3) RETURNING id`
+	dayQuery := `INSERT INTO program_days (week_id, position, name, groups) VALUES ($1, $2, $3, $4) RETURNING id`
+	slotQuery := `INSERT INTO program_slots (day_id, position, exercise_id, sets, reps, reps_max, weight, percent_e1rm,
+		rest_seconds, notes, group_label, substituted_for_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	for i, phase := range phases{
+		var phaseID int
+		if err := tx.QueryRow(phaseQuery, programID, i+1, phase.Name).Scan(&phaseID); err != nil{
+			return err
+		}
+		for j, week := range phase.Weeks{
+			var weekID int
+			if err := tx.QueryRow(weekQuery, phaseID, j+1, week.Name).Scan(&weekID); err != nil{
+				return err
+			}
+			for k, day := range week.Days{
+				var dayID int
+				if err := tx.QueryRow(dayQuery, weekID, k+1, day.Name, day.Groups).Scan(&dayID); err != nil{
+					return err
+				}
+				for l, slot := range day.Slots{
+					if _, err := tx.Exec(slotQuery, dayID, l+1, slot.ExerciseID, slot.Sets, slot.Reps, slot.RepsMax,
+						slot.Weight, slot.PercentE1RM, slot.RestSeconds, slot.Notes, slot.Group, slot.SubstitutedForID); err != nil{
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
 
-	values := []interface{}{}
-	query := `INSERT INTO exercises_program (program_id, exercise_id, sets, reps, weight) VALUES `
-	placeholderID := 1
-	placeholders := []string{}
+// programStructureJSON builds the program_versions.structure snapshot of the
+// program aliased p from the phase tables, in the shape of models.ProgramStructure.
+// Groups and substitutions are only added when set, so snapshots taken before
+// they existed still compare equal.
+const programStructureJSON = `COALESCE((SELECT jsonb_agg(jsonb_build_object('name', ph.name, 'weeks', COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('name', w.name, 'days', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object('name', d.name, 'slots', COALESCE((
+				SELECT jsonb_agg(jsonb_build_object('exercise_id', s.exercise_id, 'sets', s.sets, 'reps', s.reps,
+					'reps_max', s.reps_max, 'weight', s.weight, 'percent_e1rm', s.percent_e1rm,
+					'rest_seconds', s.rest_seconds, 'notes', s.notes)
+					|| jsonb_strip_nulls(jsonb_build_object('group', s.group_label,
+						'substituted_for_id', s.substituted_for_id)) ORDER BY s.position)
+				FROM program_slots s WHERE s.day_id = d.id), '[]'::jsonb))
+				|| jsonb_strip_nulls(jsonb_build_object('groups', d.groups)) ORDER BY d.position)
+			FROM program_days d WHERE d.week_id = w.id), '[]'::jsonb)) ORDER BY w.position)
+		FROM program_weeks w WHERE w.phase_id = ph.id), '[]'::jsonb)) ORDER BY ph.position)
+	FROM program_phases ph WHERE ph.program_id = p.id), '[]'::jsonb)`
+
+// saveProgramVersion snapshots the program as the next version. Nothing is
+// stored when the name and structure equal the latest version.
+func saveProgramVersion(tx *sqlx.Tx, programID int) error{
+	query := `INSERT INTO program_versions (program_id, version, name, structure, created_at)
+		SELECT p.id, COALESCE(latest.version, 0) + 1, p.name, snap.structure, NOW()
+		FROM programs p
+		CROSS JOIN LATERAL (SELECT ` + programStructureJSON + ` AS structure) snap
+		LEFT JOIN LATERAL (SELECT v.version, v.name, v.structure FROM program_versions v
+			WHERE v.program_id = p.id ORDER BY v.version DESC LIMIT 1) latest ON true
+		WHERE p.id = $1 AND (latest.version IS NULL OR latest.name <> p.name OR latest.structure <> snap.structure)`
+	_, err := tx.Exec(query, programID)
+	return err
+}
 
-	for _, ex := range exercises {
-		values = append(values, programID, ex.ExerciseID, ex.Sets, ex.Reps, ex.Weight)
-		placeholders = append(placeholders,
-			fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)",
-				placeholderID, placeholderID+1, placeholderID+2, placeholderID+3, placeholderID+4),
-		)
-		placeholderID += 5
+// ListProgramVersions returns the versions of a program, newest first, with
+// the number of workouts logged against each.
+func (r *ProgramRepository) ListProgramVersions(programID int) ([]models.ProgramVersionSummary, error){
+	const op = "internal.repositories.ListProgramVersions"
+	versions := []models.ProgramVersionSummary{}
+
+	query := `SELECT v.version, v.name, v.created_at,
+		(SELECT COUNT(*) FROM workouts wo WHERE wo.program_version_id = v.id) AS workout_count,
+		v.version = MAX(v.version) OVER () AS current
+		FROM program_versions v WHERE v.program_id = $1
+		ORDER BY v.version DESC`
+	if err := r.db.Select(&versions, query, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	return versions, nil
+}
 
-	query += strings.Join(placeholders, ", ")
+func (r *ProgramRepository) GetProgramVersion(programID int, version int) (*models.ProgramVersion, error){
+	const op = "internal.repositories.GetProgramVersion"
+	var programVersion models.ProgramVersion
 
-	if _, err := r.db.Exec(query, values...); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+	query := `SELECT * FROM program_versions WHERE program_id = $1 AND version = $2`
+	if err := r.db.Get(&programVersion, query, programID, version); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-
-	return nil
+	return &programVersion, nil
 }
 
 func (r *ProgramRepository) GetExercisesByNames(names []string) ([]models.Exercise, error){
 	const op = "internal.repositories.GetExercisesByNames"
 	var exercises []models.Exercise
 
-	query := `SELECT id, name FROM exercises WHERE name = ANY($1)`
+	query := `SELECT id, name, type FROM exercises WHERE name = ANY($1)`
 	if err := r.db.Select(&exercises, query, pq.Array(names)); err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -112,32 +234,150 @@ func(r *ProgramRepository) GetProgramByID(programID int, userID int) (*models.Pr
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	exercises, err := r.GetExercsisesProgram(programID)
+	phases, err := r.GetProgramStructure(programID)
 	if err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	programResp := models.Program{
+	programResp := programFromDB(programDB, phases)
+	return &programResp, nil
+}
+
+func programFromDB(programDB models.ProgramDB, phases []models.ProgramPhase) models.Program{
+	return models.Program{
 		ID: programDB.ID,
 		UserID: programDB.UserID,
 		Name: programDB.Name,
-		Exercises: exercises,
+		Phases: phases,
 		CreatedAt: programDB.CreatedAt,
+		Visibility: programDB.Visibility,
+		ShareToken: programDB.ShareToken,
+		Tags: programDB.Tags,
+		ForkedFromID: programDB.ForkedFromID,
+		ForkedFromName: programDB.ForkedFromName,
+		ForkedFromAuthor: programDB.ForkedFromAuthor,
+	}
+}
+
+// UpdateSharing sets the visibility, share token and tags of a program.
+func (r *ProgramRepository) UpdateSharing(programID int, userID int, visibility string, shareToken *string, tags []string) error{
+	const op = "internal.repositories.UpdateSharing"
+
+	query := `UPDATE programs SET visibility = $1, share_token = $2, tags = $3
+		WHERE id = $4 AND user_id = $5 RETURNING id`
+	var id int
+	if err := r.db.Get(&id, query, visibility, shareToken, pq.Array(tags), programID, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
 	}
+	return nil
+}
 
-	return &programResp, nil
+// GetProgramStructure loads the phases of a program with their weeks, days
+// and slots, each level in position order.
+func (r *ProgramRepository) GetProgramStructure(programID int) ([]models.ProgramPhase, error){
+	const op = "internal.repositories.GetProgramStructure"
+	var phases []models.ProgramPhase
+	var weeks []models.ProgramWeek
+	var days []models.ProgramDay
+	var slots []models.ProgramSlot
+
+	phaseQuery := `SELECT * FROM program_phases WHERE program_id = $1 ORDER BY position`
+	if err := r.db.Select(&phases, phaseQuery, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	weekQuery := `SELECT w.* FROM program_weeks w
+		JOIN program_phases ph ON ph.id = w.phase_id
+		WHERE ph.program_id = $1 ORDER BY w.position`
+	if err := r.db.Select(&weeks, weekQuery, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	dayQuery := `SELECT d.* FROM program_days d
+		JOIN program_weeks w ON w.id = d.week_id
+		JOIN program_phases ph ON ph.id = w.phase_id
+		WHERE ph.program_id = $1 ORDER BY d.position`
+	if err := r.db.Select(&days, dayQuery, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	slotQuery := `SELECT s.* FROM program_slots s
+		JOIN program_days d ON d.id = s.day_id
+		JOIN program_weeks w ON w.id = d.week_id
+		JOIN program_phases ph ON ph.id = w.phase_id
+		WHERE ph.program_id = $1 ORDER BY s.position`
+	if err := r.db.Select(&slots, slotQuery, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	slotsByDay := make(map[int][]models.ProgramSlot)
+	for _, slot := range slots{
+		slotsByDay[slot.DayID] = append(slotsByDay[slot.DayID], slot)
+	}
+	daysByWeek := make(map[int][]models.ProgramDay)
+	for _, day := range days{
+		day.Slots = slotsByDay[day.ID]
+		daysByWeek[day.WeekID] = append(daysByWeek[day.WeekID], day)
+	}
+	weeksByPhase := make(map[int][]models.ProgramWeek)
+	for _, week := range weeks{
+		week.Days = daysByWeek[week.ID]
+		weeksByPhase[week.PhaseID] = append(weeksByPhase[week.PhaseID], week)
+	}
+	for i := range phases{
+		phases[i].Weeks = weeksByPhase[phases[i].ID]
+	}
+
+	return phases, nil
 }
 
-func (r *ProgramRepository) GetExercsisesProgram(programID int) ([]models.ExerciseProgramDB, error){
-	const op = "internal.repositories.GetExercsisesProgram"
-	var exercises []models.ExerciseProgramDB
+// GetEstimatedOneRepMaxes returns the best Epley estimate (weight x (1 + reps/30))
+// per exercise over the user's logged sets of 1 to 12 reps.
+func (r *ProgramRepository) GetEstimatedOneRepMaxes(userID int, exerciseIDs []int) (map[int]float64, error){
+	const op = "internal.repositories.GetEstimatedOneRepMaxes"
+	var rows []struct {
+		ExerciseID int     `db:"exercise_id"`
+		E1RM       float64 `db:"e1rm"`
+	}
+
+	query := `SELECT ee.exercise_id, MAX(s.weight * (1 + s.reps / 30.0)) AS e1rm
+		FROM exercises_entry ee
+		JOIN workouts w ON w.id = ee.workout_id
+		CROSS JOIN LATERAL unnest(ee.reps, ee.weight) AS s(reps, weight)
+		WHERE w.user_id = $1 AND ee.exercise_id = ANY($2) AND s.reps BETWEEN 1 AND 12 AND s.weight > 0
+		GROUP BY ee.exercise_id`
+	if err := r.db.Select(&rows, query, userID, pq.Array(exerciseIDs)); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	query := `SELECT * FROM exercises_program WHERE program_id = $1`
+	result := make(map[int]float64, len(rows))
+	for _, row := range rows{
+		result[row.ExerciseID] = row.E1RM
+	}
+	return result, nil
+}
 
-	if err := r.db.Select(&exercises, query, programID); err != nil{
+// ListPrograms returns the user's programs, most recently used first. A
+// non-empty search keeps programs whose name contains it, ignoring case.
+func (r *ProgramRepository) ListPrograms(userID int, search string) ([]models.ProgramSummary, error){
+	const op = "internal.repositories.ListPrograms"
+	programs := []models.ProgramSummary{}
+
+	query := `SELECT p.id, p.name, p.visibility, p.created_at,
+		(SELECT COUNT(DISTINCT s.exercise_id) FROM program_slots s
+			JOIN program_days d ON d.id = s.day_id
+			JOIN program_weeks w ON w.id = d.week_id
+			JOIN program_phases ph ON ph.id = w.phase_id
+			WHERE ph.program_id = p.id) AS exercise_count,
+		(SELECT MAX(wo.date) FROM workouts wo WHERE wo.program_id = p.id) AS last_used_at
+		FROM programs p
+		WHERE p.user_id = $1 AND ($2 = '' OR p.name ILIKE '%' || $2 || '%')
+		ORDER BY last_used_at DESC NULLS LAST, p.created_at DESC, p.id DESC`
+	if err := r.db.Select(&programs, query, userID, search); err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	return exercises, nil
+
+	return programs, nil
 }
 
 func (r *ProgramRepository) DeleteProgram(programID int, userID int) (int, error){
@@ -156,13 +396,3 @@ func (r *ProgramRepository) DeleteProgram(programID int, userID int) (int, error
 	}
 	return deletedID, nil
 }
-
-func (r *ProgramRepository) DeleteExercisesProgram(programID int) (error){
-	const op = "internal.repositories.DeleteExercisesProgram"
-	
-	query := `DELETE FROM exercises_program WHERE program_id = $1`
-	if _, err := r.db.Exec(query, programID); err != nil{
-		return fmt.Errorf("%s: You are not authorized to delete this program: %w", op, err)
-	}
-	return nil
-}
\ No newline at end of file
@@ -0,0 +1,160 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type ProgressionRepository struct {
+	db *sqlx.DB
+}
+
+func NewProgressionRepository(db *sqlx.DB) *ProgressionRepository{
+	return &ProgressionRepository{db: db}
+}
+
+// SaveRule creates or replaces the rule for an exercise and resets its
+// progression state to the given starting target.
+func (r *ProgressionRepository) SaveRule(rule models.ProgressionRule, state models.ProgressionState) error{
+	const op = "internal.repositories.SaveRule"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	ruleQuery := `INSERT INTO program_progression_rules (program_id, exercise_id, type, increment, deload_after,
+		deload_percent, wave_percents, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (program_id, exercise_id) DO UPDATE SET type = EXCLUDED.type, increment = EXCLUDED.increment,
+		deload_after = EXCLUDED.deload_after, deload_percent = EXCLUDED.deload_percent,
+		wave_percents = EXCLUDED.wave_percents`
+	if _, err := tx.Exec(ruleQuery, rule.ProgramID, rule.ExerciseID, rule.Type, rule.Increment, rule.DeloadAfter,
+		rule.DeloadPercent, rule.WavePercents); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := saveProgressionState(tx, state); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func saveProgressionState(tx *sqlx.Tx, state models.ProgressionState) error{
+	query := `INSERT INTO program_progression_state (program_id, exercise_id, weight, reps, wave_step, failures, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (program_id, exercise_id) DO UPDATE SET weight = EXCLUDED.weight, reps = EXCLUDED.reps,
+		wave_step = EXCLUDED.wave_step, failures = EXCLUDED.failures, updated_at = NOW()`
+	_, err := tx.Exec(query, state.ProgramID, state.ExerciseID, state.Weight, state.Reps, state.WaveStep, state.Failures)
+	return err
+}
+
+func (r *ProgressionRepository) GetRules(programID int) ([]models.ProgressionRule, error){
+	const op = "internal.repositories.GetRules"
+	var rules []models.ProgressionRule
+
+	query := `SELECT * FROM program_progression_rules WHERE program_id = $1 ORDER BY id`
+	if err := r.db.Select(&rules, query, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rules, nil
+}
+
+// GetStates returns the progression state of a program keyed by exercise id.
+func (r *ProgressionRepository) GetStates(programID int) (map[int]models.ProgressionState, error){
+	const op = "internal.repositories.GetStates"
+	var states []models.ProgressionState
+
+	query := `SELECT * FROM program_progression_state WHERE program_id = $1`
+	if err := r.db.Select(&states, query, programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	result := make(map[int]models.ProgressionState, len(states))
+	for _, state := range states{
+		result[state.ExerciseID] = state
+	}
+	return result, nil
+}
+
+// DeleteRule removes the rule and its state; the history is kept.
+func (r *ProgressionRepository) DeleteRule(programID int, exerciseID int) (bool, error){
+	const op = "internal.repositories.DeleteRule"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM program_progression_rules WHERE program_id = $1 AND exercise_id = $2`, programID, exerciseID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM program_progression_state WHERE program_id = $1 AND exercise_id = $2`, programID, exerciseID); err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return affected > 0, nil
+}
+
+// SaveEvaluation stores the new state of each exercise together with the
+// history entries that explain it.
+func (r *ProgressionRepository) SaveEvaluation(states []models.ProgressionState, events []models.ProgressionEvent) error{
+	const op = "internal.repositories.SaveEvaluation"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	for _, state := range states{
+		if err := saveProgressionState(tx, state); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	eventQuery := `INSERT INTO program_progression_history (program_id, exercise_id, workout_id, action, previous_weight,
+		new_weight, previous_reps, new_reps, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())`
+	for _, event := range events{
+		if _, err := tx.Exec(eventQuery, event.ProgramID, event.ExerciseID, event.WorkoutID, event.Action,
+			event.PreviousWeight, event.NewWeight, event.PreviousReps, event.NewReps, event.Reason); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetHistory returns the newest entries first; exerciseID 0 means all exercises.
+func (r *ProgressionRepository) GetHistory(programID int, exerciseID int) ([]models.ProgressionEvent, error){
+	const op = "internal.repositories.GetHistory"
+	var events []models.ProgressionEvent
+
+	query := `SELECT * FROM program_progression_history
+		WHERE program_id = $1 AND ($2 = 0 OR exercise_id = $2)
+		ORDER BY created_at DESC, id DESC`
+	if err := r.db.Select(&events, query, programID, exerciseID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return events, nil
+}
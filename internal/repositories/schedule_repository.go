@@ -0,0 +1,328 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type ScheduleRepository struct {
+	db *sqlx.DB
+}
+
+func NewScheduleRepository(db *sqlx.DB) *ScheduleRepository{
+	return &ScheduleRepository{db: db}
+}
+
+func (r *ScheduleRepository) CreateRule(rule models.ScheduleRule) (int, error){
+	const op = "internal.repositories.CreateRule"
+	var id int
+
+	query := `INSERT INTO schedule_rules (user_id, program_id, weekdays, start_date, end_date, day_index, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW()) RETURNING id`
+	if err := r.db.QueryRow(query, rule.UserID, rule.ProgramID, rule.Weekdays, rule.StartDate, rule.EndDate,
+		rule.DayIndex).Scan(&id); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (r *ScheduleRepository) GetRules(userID int) ([]models.ScheduleRule, error){
+	const op = "internal.repositories.GetRules"
+	rules := []models.ScheduleRule{}
+
+	query := `SELECT * FROM schedule_rules WHERE user_id = $1 ORDER BY id`
+	if err := r.db.Select(&rules, query, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rules, nil
+}
+
+func (r *ScheduleRepository) GetRule(ruleID int, userID int) (*models.ScheduleRule, error){
+	const op = "internal.repositories.GetRule"
+	var rule models.ScheduleRule
+
+	query := `SELECT * FROM schedule_rules WHERE id = $1 AND user_id = $2`
+	if err := r.db.Get(&rule, query, ruleID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &rule, nil
+}
+
+// GetRulesToMaterialize returns the user's rules whose sessions have not
+// been created up to until yet.
+func (r *ScheduleRepository) GetRulesToMaterialize(userID int, until time.Time) ([]models.ScheduleRule, error){
+	const op = "internal.repositories.GetRulesToMaterialize"
+	var rules []models.ScheduleRule
+
+	query := `SELECT * FROM schedule_rules WHERE user_id = $1
+		AND (materialized_until IS NULL OR materialized_until < $2)
+		AND (end_date IS NULL OR materialized_until IS NULL OR materialized_until < end_date)`
+	if err := r.db.Select(&rules, query, userID, until); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return rules, nil
+}
+
+// SaveMaterialized stores the sessions of a rule up to until. Occurrences
+// that already exist, possibly moved or skipped, are left alone.
+func (r *ScheduleRepository) SaveMaterialized(ruleID int, sessions []models.ScheduledSession, until time.Time) error{
+	const op = "internal.repositories.SaveMaterialized"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO scheduled_sessions (user_id, program_id, rule_id, occurrence, day_index, date, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'planned', NOW(), NOW())
+		ON CONFLICT (rule_id, occurrence) DO NOTHING`
+	for _, session := range sessions{
+		if _, err := tx.Exec(query, session.UserID, session.ProgramID, session.RuleID, session.Occurrence,
+			session.DayIndex, session.Date); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE schedule_rules SET materialized_until = $1 WHERE id = $2`, until, ruleID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteRule removes a rule with its planned sessions from the given date
+// on; earlier sessions stay in the calendar.
+func (r *ScheduleRepository) DeleteRule(ruleID int, userID int, from time.Time) (bool, error){
+	const op = "internal.repositories.DeleteRule"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM scheduled_sessions WHERE rule_id = $1 AND user_id = $2 AND status = 'planned' AND date >= $3`,
+		ruleID, userID, from); err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	res, err := tx.Exec(`DELETE FROM schedule_rules WHERE id = $1 AND user_id = $2`, ruleID, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return affected > 0, nil
+}
+
+// ShiftRule pushes the day rotation of a rule back by one: planned sessions
+// from the given date on get the program day of the occurrence before them.
+func (r *ScheduleRepository) ShiftRule(ruleID int, dayCount int, from time.Time) error{
+	const op = "internal.repositories.ShiftRule"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var offset int
+	if err := tx.QueryRow(`UPDATE schedule_rules SET index_offset = index_offset + 1 WHERE id = $1 RETURNING index_offset`,
+		ruleID).Scan(&offset); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	query := `UPDATE scheduled_sessions SET day_index = ((occurrence - $1) % $2 + $2) % $2 + 1, updated_at = NOW()
+		WHERE rule_id = $3 AND status = 'planned' AND date >= $4`
+	if _, err := tx.Exec(query, offset, dayCount, ruleID, from); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *ScheduleRepository) CreateSession(session models.ScheduledSession) (int, error){
+	const op = "internal.repositories.CreateSession"
+	var id int
+
+	query := `INSERT INTO scheduled_sessions (user_id, program_id, day_index, date, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'planned', NOW(), NOW()) RETURNING id`
+	if err := r.db.QueryRow(query, session.UserID, session.ProgramID, session.DayIndex, session.Date).Scan(&id); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// SkipSession marks a planned session skipped; it reports false when the
+// user has no planned session with this id.
+func (r *ScheduleRepository) SkipSession(sessionID int, userID int) (bool, error){
+	const op = "internal.repositories.SkipSession"
+
+	query := `UPDATE scheduled_sessions SET status = 'skipped', updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND status IN ('planned', 'missed')`
+	res, err := r.db.Exec(query, sessionID, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return affected > 0, nil
+}
+
+func (r *ScheduleRepository) DeleteSession(sessionID int, userID int) (bool, error){
+	const op = "internal.repositories.DeleteSession"
+
+	res, err := r.db.Exec(`DELETE FROM scheduled_sessions WHERE id = $1 AND user_id = $2`, sessionID, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return affected > 0, nil
+}
+
+// GetSessions returns the user's sessions dated from from to to inclusive.
+func (r *ScheduleRepository) GetSessions(userID int, from time.Time, to time.Time) ([]models.ScheduleSessionRow, error){
+	const op = "internal.repositories.GetSessions"
+	var sessions []models.ScheduleSessionRow
+
+	query := `SELECT s.*, p.name AS program_name FROM scheduled_sessions s
+		JOIN programs p ON p.id = s.program_id
+		WHERE s.user_id = $1 AND s.date BETWEEN $2 AND $3
+		ORDER BY s.date, s.id`
+	if err := r.db.Select(&sessions, query, userID, from, to); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return sessions, nil
+}
+
+// GetUnscheduledWorkouts returns the user's workouts in the range that did
+// not complete a scheduled session.
+func (r *ScheduleRepository) GetUnscheduledWorkouts(userID int, from time.Time, to time.Time) ([]models.ScheduleWorkoutRow, error){
+	const op = "internal.repositories.GetUnscheduledWorkouts"
+	var workouts []models.ScheduleWorkoutRow
+
+	query := `SELECT w.id, w.date, w.program_id, p.name AS program_name FROM workouts w
+		LEFT JOIN programs p ON p.id = w.program_id
+		WHERE w.user_id = $1 AND w.date BETWEEN $2 AND $3
+		AND NOT EXISTS (SELECT 1 FROM scheduled_sessions s WHERE s.workout_id = w.id)
+		ORDER BY w.date, w.id`
+	if err := r.db.Select(&workouts, query, userID, from, to); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return workouts, nil
+}
+
+// GetOverdueSessions returns planned sessions dated before today, oldest first.
+func (r *ScheduleRepository) GetOverdueSessions(userID int, today time.Time) ([]models.ScheduledSession, error){
+	const op = "internal.repositories.GetOverdueSessions"
+	var sessions []models.ScheduledSession
+
+	query := `SELECT * FROM scheduled_sessions WHERE user_id = $1 AND status = 'planned' AND date < $2
+		ORDER BY date, id`
+	if err := r.db.Select(&sessions, query, userID, today); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return sessions, nil
+}
+
+func (r *ScheduleRepository) MarkMissed(sessionID int) error{
+	const op = "internal.repositories.MarkMissed"
+
+	query := `UPDATE scheduled_sessions SET status = 'missed', updated_at = NOW() WHERE id = $1 AND status = 'planned'`
+	if _, err := r.db.Exec(query, sessionID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// MoveSession gives a session a new date and remembers the first date it
+// was planned for.
+func (r *ScheduleRepository) MoveSession(sessionID int, date time.Time) error{
+	const op = "internal.repositories.MoveSession"
+
+	query := `UPDATE scheduled_sessions SET rescheduled_from = COALESCE(rescheduled_from, date), date = $1, updated_at = NOW()
+		WHERE id = $2`
+	if _, err := r.db.Exec(query, date, sessionID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// HasSessionOn reports whether a planned or completed session falls on date.
+func (r *ScheduleRepository) HasSessionOn(userID int, date time.Time) (bool, error){
+	const op = "internal.repositories.HasSessionOn"
+	var exists bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM scheduled_sessions WHERE user_id = $1 AND date = $2
+		AND status IN ('planned', 'completed'))`
+	if err := r.db.Get(&exists, query, userID, date); err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return exists, nil
+}
+
+// LinkWorkout completes the latest planned session of the program dated
+// between from and to. It reports false when there is none.
+func (r *ScheduleRepository) LinkWorkout(userID int, programID int, workoutID int, from time.Time, to time.Time) (bool, error){
+	const op = "internal.repositories.LinkWorkout"
+
+	query := `UPDATE scheduled_sessions SET status = 'completed', workout_id = $1, updated_at = NOW()
+		WHERE id = (SELECT id FROM scheduled_sessions
+			WHERE user_id = $2 AND program_id = $3 AND status = 'planned' AND date BETWEEN $4 AND $5
+			ORDER BY date DESC, id LIMIT 1)`
+	res, err := r.db.Exec(query, workoutID, userID, programID, from, to)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return affected > 0, nil
+}
+
+// UnlinkWorkout puts the session completed by a workout back to planned.
+func (r *ScheduleRepository) UnlinkWorkout(workoutID int, userID int) error{
+	const op = "internal.repositories.UnlinkWorkout"
+
+	query := `UPDATE scheduled_sessions SET status = 'planned', workout_id = NULL, updated_at = NOW() WHERE workout_id = $1 AND user_id = $2`
+	if _, err := r.db.Exec(query, workoutID, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// GetUsersWithPendingSchedule returns users with overdue planned sessions or
+// rules not materialized up to until.
+func (r *ScheduleRepository) GetUsersWithPendingSchedule(today time.Time, until time.Time) ([]int, error){
+	const op = "internal.repositories.GetUsersWithPendingSchedule"
+	var userIDs []int
+
+	query := `SELECT user_id FROM scheduled_sessions WHERE status = 'planned' AND date < $1
+		UNION
+		SELECT user_id FROM schedule_rules
+		WHERE (materialized_until IS NULL OR materialized_until < $2)
+		AND (end_date IS NULL OR materialized_until IS NULL OR materialized_until < end_date)`
+	if err := r.db.Select(&userIDs, query, today, until); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return userIDs, nil
+}
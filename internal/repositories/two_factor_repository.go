@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactorRepository(db *sqlx.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) SetPendingSecret(userID int, secret string) error{
+	const op = "repositories.two_factor_repository.SetPendingSecret"
+
+	query := `UPDATE users SET totp_secret = $1, totp_enabled = false WHERE id = $2 AND totp_enabled = false`
+	res, err := r.db.Exec(query, secret, userID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0{
+		return fmt.Errorf("%s: two-factor authentication is already enabled", op)
+	}
+	return nil
+}
+
+// Enable switches 2FA on and replaces any previous recovery codes in one transaction.
+func (r *TwoFactorRepository) Enable(userID int, codeHashes []string) error{
+	const op = "repositories.two_factor_repository.Enable"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET totp_enabled = true WHERE id = $1`, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, hash := range codeHashes{
+		if _, err := tx.Exec(`INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, NOW())`, userID, hash); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) Disable(userID int) error{
+	const op = "repositories.two_factor_repository.Disable"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET totp_secret = '', totp_enabled = false WHERE id = $1`, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// UseTOTPStep records the period of an accepted code and reports whether it
+// is newer than the last one, so the same code can not be used twice.
+func (r *TwoFactorRepository) UseTOTPStep(userID int, step int64) (bool, error){
+	const op = "repositories.two_factor_repository.UseTOTPStep"
+
+	query := `UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1`
+	res, err := r.db.Exec(query, step, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n > 0, nil
+}
+
+// UseRecoveryCode marks a matching unused code as spent and reports whether one was found.
+func (r *TwoFactorRepository) UseRecoveryCode(userID int, codeHash string) (bool, error){
+	const op = "repositories.two_factor_repository.UseRecoveryCode"
+
+	query := `UPDATE recovery_codes SET used_at = NOW()
+	          WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	res, err := r.db.Exec(query, userID, codeHash)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n > 0, nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/jmoiron/sqlx"
@@ -17,8 +18,8 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 
 func (s *UserRepository) RegisterUserRepository(user models.User) (int, error){
 	const op = "repositories.RegisterUserRepository" 
-	query := `INSERT INTO users (name, email, password_hash, age, gender, height, weight, created_at) 
-			VALUES (:name, :email, :password_hash, :age, :gender, :height, :weight, NOW()) RETURNING id`
+	query := `INSERT INTO users (name, email, password_hash, age, gender, height, weight, email_verified, unit_system, created_at) 
+			VALUES (:name, :email, :password_hash, :age, :gender, :height, :weight, :email_verified, :unit_system, NOW()) RETURNING id`
 	rows, err := s.db.NamedQuery(query, user)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
@@ -45,15 +46,80 @@ func (s *UserRepository) GetUserByEmail(email string) (*models.User, error){
 	return &user, nil
 }
 
-func (r *UserRepository) DeleteUser(email string, userID int) (int, error){
-	const op = "repositories.DeleteUser"
-	var deletedID int
+func (s *UserRepository) GetUserByID(userID int) (*models.User, error){
+	const op = "repositories.user_repository.GetUserByID"
 	
-	query := `DELETE FROM users WHERE email = $1 AND id = $2 RETURNING id`
+	var user models.User
+	getUserQuery := `SELECT * FROM users WHERE id = $1`
+	err := s.db.Get(&user, getUserQuery, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: failed to find user by id: %w", op, err)
+	}
+	return &user, nil
+}
+
+// UpdatePassword stores the new hash and bumps token_version so every JWT issued
+// before the change stops working. It returns the new version.
+func (r *UserRepository) UpdatePassword(userID int, passwordHash string) (int, error){
+	const op = "repositories.UpdatePassword"
+	var tokenVersion int
+
+	query := `UPDATE users SET password_hash = $1, token_version = token_version + 1
+	          WHERE id = $2 RETURNING token_version`
+
+	if err := r.db.QueryRow(query, passwordHash, userID).Scan(&tokenVersion); err != nil{
+		return 0, fmt.Errorf("%s: failed to update password: %w", op, err)
+	}
+
+	return tokenVersion, nil
+}
+
+// MarkEmailVerified only succeeds while the account still uses the email the
+// token was issued for.
+func (r *UserRepository) MarkEmailVerified(userID int, email string) error{
+	const op = "repositories.MarkEmailVerified"
+
+	query := `UPDATE users SET email_verified = true WHERE id = $1 AND email = $2`
+	res, err := r.db.Exec(query, userID, email)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0{
+		return fmt.Errorf("%s: email has changed since the token was issued", op)
+	}
+	return nil
+}
 
-	if err := r.db.QueryRow(query, email, userID).Scan(&deletedID); err != nil{
-		return 0, fmt.Errorf("%s: failed to delete user by email: %w", op, err)
+// UpdateUser sets only the given columns. Changing the email resets
+// email_verified and bumps token_version in the same statement.
+func (r *UserRepository) UpdateUser(userID int, fields map[string]interface{}) (*models.User, error){
+	const op = "repositories.UpdateUser"
+	var user models.User
+
+	sets := make([]string, 0, len(fields)+2)
+	values := make([]interface{}, 0, len(fields)+1)
+	placeholderID := 1
+	for column, value := range fields{
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, placeholderID))
+		values = append(values, value)
+		placeholderID++
+	}
+	if _, ok := fields["email"]; ok{
+		sets = append(sets, "email_verified = false", "token_version = token_version + 1")
 	}
+	if len(sets) == 0{
+		if err := r.db.Get(&user, `SELECT * FROM users WHERE id = $1`, userID); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return &user, nil
+	}
+	values = append(values, userID)
+
+	query := fmt.Sprintf(`UPDATE users SET %s WHERE id = $%d RETURNING *`, strings.Join(sets, ", "), placeholderID)
 
-	return deletedID, nil
+	if err := r.db.Get(&user, query, values...); err != nil{
+		return nil, fmt.Errorf("%s: failed to update user: %w", op, err)
+	}
+
+	return &user, nil
 }
\ No newline at end of file
@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/jmoiron/sqlx"
@@ -17,14 +20,19 @@ func NewWorkoutRepository(db *sqlx.DB) *WorkoutRepository{
 	return &WorkoutRepository{db: db}
 }
 
+// latestProgramVersionQuery selects the current version of the program in $2.
+const latestProgramVersionQuery = `SELECT id FROM program_versions WHERE program_id = $2 ORDER BY version DESC LIMIT 1`
+
 func (r *WorkoutRepository) SaveWorkout(workout models.Workout) (int, error){
 	const op = "internal.repositories.SaveWorkout"
 	var workoutID int
 
-	query := `INSERT INTO workouts (user_id, program_id, date, duration, calories, created_at)
-		VALUES($1, $2, CURRENT_DATE, $3, $4, NOW()) RETURNING id`
+	query := `INSERT INTO workouts (user_id, program_id, program_version_id, date, duration, calories, calories_estimated,
+		calories_override, exercise_groups, created_at)
+		VALUES($1, $2, (` + latestProgramVersionQuery + `), CURRENT_DATE, $3, $4, $5, $6, $7, NOW()) RETURNING id`
 	
-	if err := r.db.QueryRow(query, workout.UserID, workout.ProgramID, workout.Duration.Nanoseconds(), workout.Calories).Scan(&workoutID); err != nil{
+	if err := r.db.QueryRow(query, workout.UserID, workout.ProgramID, workout.Duration.Nanoseconds(), workout.Calories,
+		workout.CaloriesEstimated, workout.CaloriesOverride, workout.ExerciseGroups).Scan(&workoutID); err != nil{
 		return 0, fmt.Errorf("%s: failed to create workout: %w", op, err)
 	}
 
@@ -35,21 +43,40 @@ func (r *WorkoutRepository) SaveWorkout(workout models.Workout) (int, error){
 	return workoutID, nil
 }
 
+// UpdateWorkout replaces a workout of workout.UserID and its entries in one
+// transaction.
 func (r *WorkoutRepository) UpdateWorkout(workout models.Workout, workoutID int) (int, error){
 	const op = "internal.repositories.UpdateWorkout"
 
-	query := `UPDATE workouts SET user_id = $1, program_id = $2, date = CURRENT_DATE, duration = $3, 
-	        calories = $4, created_at = NOW() 
-	        WHERE id = $5 AND user_id = $6 RETURNING id`
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// a workout keeps the version it was logged against unless its program changes
+	query := `UPDATE workouts SET user_id = $1, program_id = $2, date = CURRENT_DATE, duration = $3,
+	        program_version_id = CASE WHEN program_id IS NOT DISTINCT FROM $2 THEN program_version_id
+	        ELSE (` + latestProgramVersionQuery + `) END,
+	        calories = $4, calories_estimated = $5, calories_override = $6, exercise_groups = $7, created_at = NOW() 
+	        WHERE id = $8 AND user_id = $9 RETURNING id`
 
-	if err := r.db.QueryRow(query, workout.UserID, workout.ProgramID, workout.Duration, workout.Calories, workoutID, workout.UserID).Scan(&workoutID); err != nil{
+	if err := tx.QueryRow(query, workout.UserID, workout.ProgramID, workout.Duration, workout.Calories,
+		workout.CaloriesEstimated, workout.CaloriesOverride, workout.ExerciseGroups, workoutID, workout.UserID).Scan(&workoutID); err != nil{
 		return 0, fmt.Errorf("%s: failed to update workout: %w", op, err)
 	}
 
-	if err := r.SaveExercisesWorkout(workoutID, workout.Exercises); err != nil{
+	deleteQuery := `DELETE FROM exercises_entry WHERE workout_id = (SELECT id FROM workouts WHERE id = $1 AND user_id = $2)`
+	if _, err := tx.Exec(deleteQuery, workoutID, workout.UserID); err != nil{
+		return 0, fmt.Errorf("%s: failed to delete workout exercises: %w", op, err)
+	}
+	if err := saveExercisesWorkout(tx, workoutID, workout.Exercises); err != nil{
 		return 0, fmt.Errorf("%s: failed to save workout exercises: %w", op, err)
 	}
 
+	if err := tx.Commit(); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
 	return workoutID, nil
 }
 
@@ -65,17 +92,6 @@ func (r *WorkoutRepository) DeleteWorkout(workoutID int, userID int) (int, error
 	return deletedID, nil
 }
 
-func (r *WorkoutRepository) DeleteWorkoutExercises(workoutID int) error{
-	const op = "internal.repositories.DeleteWorkoutExercises"
-	
-	query := `DELETE FROM exercises_entry WHERE workout_id = $1`
-	if _, err := r.db.Exec(query, workoutID); err != nil{
-		return fmt.Errorf("%s: failed to delete workout exercises: %w", op, err)
-	}
-	
-	return nil
-}
-
 func (r *WorkoutRepository) GetWorkoutByID(workoutID int, userID int) (*models.Workout, error){
 	const op = "internal.repositories.GetWorkoutByID" 
 	var workout models.Workout
@@ -93,7 +109,7 @@ func (r *WorkoutRepository) GetExercsisesWorkout(workoutID int) ([]models.Exerci
 	const op = "internal.repositories.GetExercsisesWorkout"
 	var exercises []models.ExerciseEntry
 
-	query := `SELECT * FROM exercises_entry WHERE workout_id = $1`
+	query := `SELECT * FROM exercises_entry WHERE workout_id = $1 ORDER BY position, id`
 
 	if err := r.db.Select(&exercises, query, workoutID); err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -105,7 +121,7 @@ func (r *WorkoutRepository) GetExercisesByID(idSlice []int) ([]models.Exercise,
 	const op = "internal.repositories.GetExercisesByID"
 	var exercises []models.Exercise
 
-	query := `SELECT id, name FROM exercises WHERE id = ANY($1)`
+	query := `SELECT id, name, type FROM exercises WHERE id = ANY($1)`
 	
 	if err := r.db.Select(&exercises, query, pq.Array(idSlice)); err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -114,43 +130,206 @@ func (r *WorkoutRepository) GetExercisesByID(idSlice []int) ([]models.Exercise,
 	return exercises, nil
 }
 
+// SwapEntryExercises stores the exercise and substitution of the given
+// entries of a workout, together with its calories, which depend on the
+// exercise types.
+func (r *WorkoutRepository) SwapEntryExercises(workout models.Workout, entries []models.ExerciseEntry) error{
+	const op = "internal.repositories.SwapEntryExercises"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE exercises_entry SET exercise_id = $1, substituted_for_id = $2 WHERE id = $3 AND workout_id = $4`
+	for _, entry := range entries{
+		if _, err := tx.Exec(query, entry.ExerciseID, entry.SubstitutedForID, entry.ID, workout.ID); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	caloriesQuery := `UPDATE workouts SET calories = $1, calories_estimated = $2 WHERE id = $3 AND user_id = $4`
+	if _, err := tx.Exec(caloriesQuery, workout.Calories, workout.CaloriesEstimated, workout.ID, workout.UserID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
 func (r *WorkoutRepository) SaveExercisesWorkout(workoutID int, exercises []models.ExerciseEntry) error{
 	const op = "internal.repositories.SaveExercisesWorkout"
 
+	if err := saveExercisesWorkout(r.db, workoutID, exercises); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func saveExercisesWorkout(exec sqlx.Execer, workoutID int, exercises []models.ExerciseEntry) error{
+	if len(exercises) == 0{
+		return nil
+	}
+
 	values := []interface{}{}
-	query := `INSERT INTO exercises_entry (workout_id, exercise_id, sets, reps, weight) VALUES `
+	query := `INSERT INTO exercises_entry (workout_id, exercise_id, position, group_label, substituted_for_id, sets, reps, weight,
+		distance, duration_seconds, elevation_gain, avg_heart_rate, max_heart_rate, avg_cadence, intervals) VALUES `
 	placeholderID := 1
 	placeholders := []string{}
 
-	for _, ex := range exercises {
-		values = append(values, workoutID, ex.ExerciseID, ex.Sets, pq.Array(ex.Reps), pq.Array(ex.Weight))
-		placeholders = append(placeholders,
-			fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)",
-				placeholderID, placeholderID+1, placeholderID+2, placeholderID+3, placeholderID+4),
-		)
-		placeholderID += 5
+	// positions follow the order of the slice, starting at 1
+	for n, ex := range exercises {
+		values = append(values, workoutID, ex.ExerciseID, n+1, ex.Group, ex.SubstitutedForID, ex.Sets, pq.Array(ex.Reps),
+			pq.Array(ex.Weight), ex.Distance, ex.DurationSeconds, ex.ElevationGain, ex.AvgHeartRate, ex.MaxHeartRate,
+			ex.AvgCadence, ex.Intervals)
+		row := make([]string, 0, 15)
+		for i := 0; i < 15; i++{
+			row = append(row, fmt.Sprintf("$%d", placeholderID+i))
+		}
+		placeholders = append(placeholders, "(" + strings.Join(row, ", ") + ")")
+		placeholderID += 15
 	}
 
 	query += strings.Join(placeholders, ", ")
 
-	if _, err := r.db.Exec(query, values...); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+	_, err := exec.Exec(query, values...)
+	return err
+}
+
+// ImportWorkout saves a workout with an explicit date together with its
+// entries and, when given, the uploaded track file in one transaction.
+func (r *WorkoutRepository) ImportWorkout(workout models.Workout, track *models.WorkoutTrack) (int, error){
+	const op = "internal.repositories.ImportWorkout"
+
+	tx, err := r.db.Beginx()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	defer tx.Rollback()
 
-	return nil
+	workoutID, err := insertImportedWorkout(tx, workout)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if track != nil{
+		trackQuery := `INSERT INTO workout_tracks (user_id, workout_id, format, content_hash, data, created_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())`
+		if _, err := tx.Exec(trackQuery, track.UserID, workoutID, track.Format, track.ContentHash, track.Data); err != nil{
+			return 0, fmt.Errorf("%s: failed to save track: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return workoutID, nil
+}
+
+// insertImportedWorkout saves a workout and its entries inside tx.
+func insertImportedWorkout(tx *sqlx.Tx, workout models.Workout) (int, error){
+	var workoutID int
+
+	query := `INSERT INTO workouts (user_id, program_id, program_version_id, date, duration, calories, calories_estimated,
+		calories_override, exercise_groups, created_at)
+		VALUES($1, $2, (` + latestProgramVersionQuery + `), $3, $4, $5, $6, $7, $8, NOW()) RETURNING id`
+	if err := tx.QueryRow(query, workout.UserID, workout.ProgramID, workout.Date, workout.Duration.Nanoseconds(), workout.Calories,
+		workout.CaloriesEstimated, workout.CaloriesOverride, workout.ExerciseGroups).Scan(&workoutID); err != nil{
+		return 0, fmt.Errorf("failed to create workout: %w", err)
+	}
+
+	if err := saveExercisesWorkout(tx, workoutID, workout.Exercises); err != nil{
+		return 0, fmt.Errorf("failed to save workout exercises: %w", err)
+	}
+	return workoutID, nil
+}
+
+// GetWorkoutIDByTrackHash finds an earlier import of the same file.
+func (r *WorkoutRepository) GetWorkoutIDByTrackHash(userID int, hash string) (int, bool, error){
+	const op = "internal.repositories.GetWorkoutIDByTrackHash"
+	var workoutID int
+
+	query := `SELECT workout_id FROM workout_tracks WHERE user_id = $1 AND content_hash = $2`
+	if err := r.db.Get(&workoutID, query, userID, hash); err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return workoutID, true, nil
+}
+
+func (r *WorkoutRepository) GetTrack(workoutID int, userID int) (*models.WorkoutTrack, error){
+	const op = "internal.repositories.GetTrack"
+	var track models.WorkoutTrack
+
+	query := `SELECT * FROM workout_tracks WHERE workout_id = $1 AND user_id = $2`
+	if err := r.db.Get(&track, query, workoutID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &track, nil
+}
+
+// FindExerciseByKeywords returns the catalog exercise with the shortest name
+// containing one of the keywords, trying the keywords in order. An empty
+// exerciseType matches exercises of any type.
+func (r *WorkoutRepository) FindExerciseByKeywords(keywords []string, exerciseType string) (*models.Exercise, error){
+	const op = "internal.repositories.FindExerciseByKeywords"
+
+	for _, keyword := range keywords{
+		var exercise models.Exercise
+		query := `SELECT id, name, type FROM exercises WHERE ($2 = '' OR type = $2) AND name ILIKE '%' || $1 || '%'
+			ORDER BY length(name), id LIMIT 1`
+		err := r.db.Get(&exercise, query, keyword, exerciseType)
+		if err == nil{
+			return &exercise, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows){
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil, fmt.Errorf("%s: no exercise matches %v", op, keywords)
 }
 
-func (r *WorkoutRepository) GetProgramIdByName(programName string) (int, error){
-	const op = "internal.repositories.GetProgramIdByName"
-	var programID int
+// GetProgramIDsByName returns the ids of the user's programs with this name;
+// names are not unique, so there may be several.
+func (r *WorkoutRepository) GetProgramIDsByName(programName string, userID int) ([]int, error){
+	const op = "internal.repositories.GetProgramIDsByName"
+	var programIDs []int
 
-	query := `SELECT id FROM programs WHERE name = $1`
+	query := `SELECT id FROM programs WHERE name = $1 AND user_id = $2 ORDER BY id`
 	
-	if err := r.db.Get(&programID, query, programName); err != nil{
+	if err := r.db.Select(&programIDs, query, programName, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return programIDs, nil
+}
+
+func (r *WorkoutRepository) GetProgramVersionNumber(versionID int) (int, error){
+	const op = "internal.repositories.GetProgramVersionNumber"
+	var version int
+
+	query := `SELECT version FROM program_versions WHERE id = $1`
+	if err := r.db.Get(&version, query, versionID); err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	return version, nil
+}
+
+func (r *WorkoutRepository) ProgramBelongsToUser(programID int, userID int) (bool, error){
+	const op = "internal.repositories.ProgramBelongsToUser"
+	var exists bool
+
+	query := `SELECT EXISTS(SELECT 1 FROM programs WHERE id = $1 AND user_id = $2)`
+	if err := r.db.Get(&exists, query, programID, userID); err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
 
-	return programID, nil
+	return exists, nil
 }
 
 func (r *WorkoutRepository) GetExercisesByNames(names []string) ([]models.Exercise, error){
@@ -163,4 +342,21 @@ func (r *WorkoutRepository) GetExercisesByNames(names []string) ([]models.Exerci
 	}
 	
 	return exercises, nil
-}
\ No newline at end of file
+}
+// GetCardioEntries returns the cardio entries of a user's workouts dated in [from, to).
+func (r *WorkoutRepository) GetCardioEntries(userID int, from, to time.Time) ([]models.CardioEntryStat, error){
+	const op = "internal.repositories.GetCardioEntries"
+	var entries []models.CardioEntryStat
+
+	query := `SELECT w.id AS workout_id, w.date, e.name, COALESCE(ee.distance, 0) AS distance, ee.duration_seconds
+		FROM exercises_entry ee
+		JOIN workouts w ON w.id = ee.workout_id
+		JOIN exercises e ON e.id = ee.exercise_id
+		WHERE w.user_id = $1 AND e.type = 'cardio' AND w.date >= $2 AND w.date < $3
+		ORDER BY w.date`
+	if err := r.db.Select(&entries, query, userID, from, to); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
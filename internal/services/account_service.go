@@ -0,0 +1,370 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/auth"
+	"github.com/artembliss/go-fitness-tracker/pkg/export"
+	"github.com/artembliss/go-fitness-tracker/pkg/mailer"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const (
+	dataExportTTL            = 7 * 24 * time.Hour
+	defaultDeletionGraceDays = 14
+	accountWorkerInterval    = 10 * time.Minute
+)
+
+// AccountService builds personal data archives and erases accounts once
+// their deletion grace period is over.
+type AccountService struct {
+	AccountRepo *repositories.AccountRepository
+	UserRepo    *repositories.UserRepository
+	Exports     *ExportService
+	Mailer      mailer.Mailer
+	ExportDir   string
+	GracePeriod time.Duration
+}
+
+// NewAccountService reads DATA_EXPORT_DIR (where archives are written) and
+// ACCOUNT_DELETION_GRACE_DAYS from the environment.
+func NewAccountService(accountRepo *repositories.AccountRepository, userRepo *repositories.UserRepository, exports *ExportService, m mailer.Mailer) *AccountService {
+	dir := os.Getenv("DATA_EXPORT_DIR")
+	if dir == ""{
+		dir = filepath.Join(os.TempDir(), "fitness-data-exports")
+	}
+	days := defaultDeletionGraceDays
+	if v, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS")); err == nil && v >= 0{
+		days = v
+	}
+	return &AccountService{
+		AccountRepo: accountRepo,
+		UserRepo: userRepo,
+		Exports: exports,
+		Mailer: m,
+		ExportDir: dir,
+		GracePeriod: time.Duration(days) * 24 * time.Hour,
+	}
+}
+
+// RequestDataExport starts building an archive in the background. While one
+// is being built it is returned instead of starting another.
+func (s *AccountService) RequestDataExport(userID int) (*models.DataExport, error){
+	const op = "services.account_service.RequestDataExport"
+
+	if active, ok, err := s.AccountRepo.FindActiveDataExport(userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	} else if ok{
+		return active, nil
+	}
+
+	exportID, err := s.AccountRepo.CreateDataExport(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	dataExport, err := s.AccountRepo.GetDataExport(exportID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	go s.runDataExport(*dataExport)
+
+	return dataExport, nil
+}
+
+func (s *AccountService) GetDataExport(exportID int, userID int) (*models.DataExport, error){
+	const op = "services.account_service.GetDataExport"
+
+	dataExport, err := s.AccountRepo.GetDataExport(exportID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return dataExport, nil
+}
+
+// GetDataExportFile returns the path of a finished archive that hasn't expired.
+func (s *AccountService) GetDataExportFile(exportID int, userID int) (string, error){
+	const op = "services.account_service.GetDataExportFile"
+
+	dataExport, err := s.AccountRepo.GetDataExport(exportID, userID)
+	if err != nil{
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if dataExport.Status != models.DataExportStatusCompleted{
+		return "", fmt.Errorf("%s: export is %s", op, dataExport.Status)
+	}
+	if dataExport.ExpiresAt != nil && time.Now().After(*dataExport.ExpiresAt){
+		return "", fmt.Errorf("%s: export has expired", op)
+	}
+	return dataExport.FilePath, nil
+}
+
+func (s *AccountService) runDataExport(dataExport models.DataExport){
+	defer func(){
+		if r := recover(); r != nil{
+			dataExport.Status = models.DataExportStatusFailed
+			dataExport.Error = fmt.Sprint("export crashed: ", r)
+			if err := s.AccountRepo.FinishDataExport(dataExport); err != nil{
+				log.Printf("warning: failed to record failure of data export %d: %v", dataExport.ID, err)
+			}
+		}
+	}()
+
+	if err := s.AccountRepo.UpdateDataExportStatus(dataExport.ID, models.DataExportStatusRunning); err != nil{
+		log.Printf("warning: failed to start data export %d: %v", dataExport.ID, err)
+	}
+
+	path, size, err := s.writeArchive(dataExport)
+	if err != nil{
+		dataExport.Status = models.DataExportStatusFailed
+		dataExport.Error = err.Error()
+	} else{
+		expiresAt := time.Now().Add(dataExportTTL)
+		dataExport.Status = models.DataExportStatusCompleted
+		dataExport.FilePath = path
+		dataExport.SizeBytes = size
+		dataExport.ExpiresAt = &expiresAt
+	}
+
+	if err := s.AccountRepo.FinishDataExport(dataExport); err != nil{
+		log.Printf("warning: failed to finish data export %d: %v", dataExport.ID, err)
+	}
+}
+
+// writeArchive writes the zip under a random name to a temporary file and
+// renames it once it is complete.
+func (s *AccountService) writeArchive(dataExport models.DataExport) (string, int64, error){
+	if err := os.MkdirAll(s.ExportDir, 0o700); err != nil{
+		return "", 0, err
+	}
+	suffix, err := auth.GenerateRandomToken(16)
+	if err != nil{
+		return "", 0, err
+	}
+	path := filepath.Join(s.ExportDir, fmt.Sprintf("data-export-%d-%s.zip", dataExport.ID, suffix))
+	tmpPath := path + ".tmp"
+
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil{
+		return "", 0, err
+	}
+	defer os.Remove(tmpPath)
+
+	zw := zip.NewWriter(f)
+	if err := s.writeArchiveFiles(zw, dataExport.UserID); err != nil{
+		f.Close()
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil{
+		f.Close()
+		return "", 0, err
+	}
+	info, err := f.Stat()
+	if err != nil{
+		f.Close()
+		return "", 0, err
+	}
+	if err := f.Close(); err != nil{
+		return "", 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil{
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+// writeArchiveFiles adds profile.json, workouts.json, programs.json,
+// body_metrics.json, tokens.json and audit_log.json. The tables use the
+// /export layout in the user's preferred unit.
+func (s *AccountService) writeArchiveFiles(zw *zip.Writer, userID int) error{
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil{
+		return err
+	}
+	unit, err := units.Resolve(user.UnitSystem)
+	if err != nil{
+		return err
+	}
+
+	profile := struct {
+		models.User
+		CreatedAt time.Time `json:"created_at"`
+	}{User: *user, CreatedAt: user.CreatedAt}
+	if err := writeJSONFile(zw, "profile.json", profile); err != nil{
+		return err
+	}
+
+	from, to := time.Time{}, time.Now().AddDate(100, 0, 0)
+	for _, section := range exportSections{
+		w, err := zw.Create(section.Name + ".json")
+		if err != nil{
+			return err
+		}
+		writer, err := export.NewWriter(export.FormatJSON, w, []export.Section{section})
+		if err != nil{
+			return err
+		}
+		if err := s.Exports.WriteSection(writer, section.Name, userID, from, to, unit); err != nil{
+			return err
+		}
+		if err := writer.Close(); err != nil{
+			return err
+		}
+	}
+
+	tokens, err := s.AccountRepo.GetTokenMetadata(userID)
+	if err != nil{
+		return err
+	}
+	tokenFile := struct {
+		TwoFactorEnabled bool                   `json:"two_factor_enabled"`
+		Tokens           []models.TokenMetadata `json:"tokens"`
+	}{TwoFactorEnabled: user.TOTPEnabled, Tokens: tokens}
+	if err := writeJSONFile(zw, "tokens.json", tokenFile); err != nil{
+		return err
+	}
+
+	audit, err := s.AccountRepo.GetAuditEntries(userID)
+	if err != nil{
+		return err
+	}
+	return writeJSONFile(zw, "audit_log.json", audit)
+}
+
+func writeJSONFile(zw *zip.Writer, name string, v interface{}) error{
+	w, err := zw.Create(name)
+	if err != nil{
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// ScheduleDeletion marks the account for erasure after the grace period.
+// email must match the account as a confirmation. Scheduling again keeps the
+// original date.
+func (s *AccountService) ScheduleDeletion(ctx context.Context, email string, userID int) (*models.ResponseAccountDeletion, error){
+	const op = "services.account_service.ScheduleDeletion"
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Email != email{
+		return nil, fmt.Errorf("%s: email does not match the account", op)
+	}
+	if user.DeletionScheduledAt != nil{
+		return &models.ResponseAccountDeletion{DeletionScheduledAt: *user.DeletionScheduledAt}, nil
+	}
+
+	at := time.Now().Add(s.GracePeriod).Truncate(time.Second)
+	if err := s.AccountRepo.ScheduleDeletion(userID, at); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	msg := mailer.Message{
+		To: user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account and all of its data will be deleted on %s.\n\n"+
+			"Changed your mind? Sign in and call POST %s/user/deletion/cancel before then.\n"+
+			"You can still download a copy of your data with POST %s/user/data-export.\n",
+			user.Name, at.UTC().Format(time.RFC1123), appBaseURL(), appBaseURL()),
+	}
+	if err := s.Mailer.Send(ctx, msg); err != nil{
+		log.Printf("warning: failed to send deletion notice to user %d: %v", userID, err)
+	}
+
+	return &models.ResponseAccountDeletion{DeletionScheduledAt: at}, nil
+}
+
+func (s *AccountService) CancelDeletion(userID int) error{
+	const op = "services.account_service.CancelDeletion"
+
+	cancelled, err := s.AccountRepo.CancelDeletion(userID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !cancelled{
+		return fmt.Errorf("%s: account is not scheduled for deletion", op)
+	}
+	return nil
+}
+
+// RunWorker erases accounts whose grace period is over and removes expired
+// archives, once right away and then every accountWorkerInterval until ctx
+// is done.
+func (s *AccountService) RunWorker(ctx context.Context){
+	ticker := time.NewTicker(accountWorkerInterval)
+	defer ticker.Stop()
+
+	for{
+		s.processDueDeletions()
+		s.removeExpiredDataExports()
+
+		select{
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *AccountService) processDueDeletions(){
+	now := time.Now()
+	userIDs, err := s.AccountRepo.GetUsersDueForDeletion(now)
+	if err != nil{
+		log.Printf("warning: failed to load accounts due for deletion: %v", err)
+		return
+	}
+
+	for _, userID := range userIDs{
+		paths, err := s.AccountRepo.GetDataExportFiles(userID)
+		if err != nil{
+			log.Printf("warning: failed to load data exports of user %d: %v", userID, err)
+			continue
+		}
+		deleted, err := s.AccountRepo.DeleteUser(userID, now)
+		if err != nil{
+			log.Printf("warning: failed to delete user %d: %v", userID, err)
+			continue
+		}
+		if !deleted{
+			continue
+		}
+		for _, path := range paths{
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err){
+				log.Printf("warning: failed to remove data export %s: %v", path, err)
+			}
+		}
+		log.Printf("deleted account %d after its grace period", userID)
+	}
+}
+
+func (s *AccountService) removeExpiredDataExports(){
+	exports, err := s.AccountRepo.GetExpiredDataExports(time.Now())
+	if err != nil{
+		log.Printf("warning: failed to load expired data exports: %v", err)
+		return
+	}
+
+	for _, e := range exports{
+		if err := os.Remove(e.FilePath); err != nil && !os.IsNotExist(err){
+			log.Printf("warning: failed to remove data export %s: %v", e.FilePath, err)
+			continue
+		}
+		if err := s.AccountRepo.ExpireDataExport(e.ID); err != nil{
+			log.Printf("warning: failed to expire data export %d: %v", e.ID, err)
+		}
+	}
+}
@@ -1,36 +1,231 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/repositories"
 	"github.com/artembliss/go-fitness-tracker/pkg/auth"
 )
 
+const recoveryCodesCount = 10
+
 type AuthService struct {
-	UserRepo *repositories.UserRepository
+	UserRepo      *repositories.UserRepository
+	TwoFactorRepo *repositories.TwoFactorRepository
+	Guard         *LoginGuard
 }
 
-func NewAuthService(userRepo *repositories.UserRepository) *AuthService {
-	return &AuthService{UserRepo: userRepo}
+func NewAuthService(userRepo *repositories.UserRepository, twoFactorRepo *repositories.TwoFactorRepository, guard *LoginGuard) *AuthService {
+	return &AuthService{UserRepo: userRepo, TwoFactorRepo: twoFactorRepo, Guard: guard}
 }
 
-func (s *AuthService) AuthenticateUserService(email, password string) (string, error){
-	const op = "services.auth_service.AuthenticateUserService" 
+// AuthenticateUserService checks the password. Users with 2FA enabled get a
+// challenge token instead of an access token and must finish with CompleteTwoFactorLogin.
+func (s *AuthService) AuthenticateUserService(ctx context.Context, email, password, ip string) (*models.ResponseLogin, error){
+	const op = "services.auth_service.AuthenticateUserService"
+
+	if err := s.Guard.Check(ctx, email, ip); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
 	user, err := s.UserRepo.GetUserByEmail(email)
 	if err != nil{
-		return "", fmt.Errorf("%s: user not found: %w", op, err)
+		if guardErr := s.Guard.RegisterFailure(ctx, email, ip, nil); guardErr != nil{
+			return nil, fmt.Errorf("%s: %w", op, guardErr)
+		}
+		return nil, fmt.Errorf("%s: user not found: %w", op, err)
 	}
 
 	if !auth.CheckPassword(password, user.PasswordHash){
-		return "", fmt.Errorf("%s: Ivalid email or password: %w", op, err)
+		if guardErr := s.Guard.RegisterFailure(ctx, email, ip, &user.ID); guardErr != nil{
+			return nil, fmt.Errorf("%s: %w", op, guardErr)
+		}
+		return nil, fmt.Errorf("%s: Ivalid email or password", op)
+	}
+
+	if user.TOTPEnabled{
+		challenge, err := auth.GenerateChallengeJWT(user.Email)
+		if err != nil{
+			return nil, fmt.Errorf("%s: failed to generate challenge token: %w", op, err)
+		}
+		return &models.ResponseLogin{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	if err := s.Guard.RegisterSuccess(ctx, email); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := auth.GenerateJWT(user.Email, user.TokenVersion)
+	if err != nil{
+		return nil, fmt.Errorf("%s: failed to generate token: %w", op, err)
+	}
+
+	return &models.ResponseLogin{Token: token}, nil
+}
+
+// CompleteTwoFactorLogin shares the password step's attempt counters, so
+// guessing TOTP codes is throttled and locks the account the same way.
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, req models.RequestLoginTwoFactor, ip string) (*models.ResponseLogin, error){
+	const op = "services.auth_service.CompleteTwoFactorLogin"
+
+	claims, err := auth.VerifyChallengeJWT(req.ChallengeToken)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.Guard.Check(ctx, claims.Subject, ip); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := s.UserRepo.GetUserByEmail(claims.Subject)
+	if err != nil{
+		return nil, fmt.Errorf("%s: user not found: %w", op, err)
+	}
+
+	if err := s.checkSecondFactor(user, req.Code, req.RecoveryCode); err != nil{
+		if guardErr := s.Guard.RegisterFailure(ctx, user.Email, ip, &user.ID); guardErr != nil{
+			return nil, fmt.Errorf("%s: %w", op, guardErr)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.Guard.RegisterSuccess(ctx, user.Email); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := auth.GenerateJWT(user.Email, user.TokenVersion)
+	if err != nil{
+		return nil, fmt.Errorf("%s: failed to generate token: %w", op, err)
+	}
+
+	return &models.ResponseLogin{Token: token}, nil
+}
+
+// EnrollTwoFactor stores a new pending secret. 2FA stays disabled until the
+// user proves possession of the secret with VerifyTwoFactor.
+func (s *AuthService) EnrollTwoFactor(userID int) (*models.ResponseTwoFactorEnroll, error){
+	const op = "services.auth_service.EnrollTwoFactor"
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.TOTPEnabled{
+		return nil, fmt.Errorf("%s: two-factor authentication is already enabled", op)
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	token, err := auth.GenerateJWT(user.Email) 
+	if err := s.TwoFactorRepo.SetPendingSecret(userID, secret); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.ResponseTwoFactorEnroll{
+		Secret: secret,
+		URI: auth.TOTPURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+func (s *AuthService) VerifyTwoFactor(userID int, code string) (*models.ResponseRecoveryCodes, error){
+	const op = "services.auth_service.VerifyTwoFactor"
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.TOTPEnabled{
+		return nil, fmt.Errorf("%s: two-factor authentication is already enabled", op)
+	}
+	if user.TOTPSecret == ""{
+		return nil, fmt.Errorf("%s: two-factor enrolment has not been started", op)
+	}
+	if err := s.checkTOTP(user, code); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, c := range codes{
+		hashes = append(hashes, auth.HashToken(c))
+	}
+
+	if err := s.TwoFactorRepo.Enable(userID, hashes); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.ResponseRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s *AuthService) DisableTwoFactor(userID int, req models.RequestDisableTwoFactor) error{
+	const op = "services.auth_service.DisableTwoFactor"
+
+	user, err := s.UserRepo.GetUserByID(userID)
 	if err != nil{
-		return "", fmt.Errorf("%s: failed to generate token: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.TOTPEnabled{
+		return fmt.Errorf("%s: two-factor authentication is not enabled", op)
+	}
+	if !auth.CheckPassword(req.Password, user.PasswordHash){
+		return fmt.Errorf("%s: invalid password", op)
+	}
+	if err := s.checkSecondFactor(user, req.Code, req.RecoveryCode); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.TwoFactorRepo.Disable(userID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
 	}
+	return nil
+}
 
-	return token, nil
-}
\ No newline at end of file
+func (s *AuthService) checkSecondFactor(user *models.User, code, recoveryCode string) error{
+	if code != ""{
+		return s.checkTOTP(user, code)
+	}
+	if recoveryCode != ""{
+		ok, err := s.TwoFactorRepo.UseRecoveryCode(user.ID, auth.HashToken(auth.NormalizeRecoveryCode(recoveryCode)))
+		if err != nil{
+			return err
+		}
+		if ok{
+			return nil
+		}
+		return fmt.Errorf("invalid recovery code")
+	}
+	return fmt.Errorf("two-factor code or recovery code is required")
+}
+
+// checkTOTP accepts a code once: its period is stored and codes from the
+// same or earlier periods are rejected afterwards.
+func (s *AuthService) checkTOTP(user *models.User, code string) error{
+	step, ok := auth.ValidateTOTP(code, user.TOTPSecret, user.TOTPLastStep, time.Now())
+	if !ok{
+		return fmt.Errorf("invalid two-factor code")
+	}
+	used, err := s.TwoFactorRepo.UseTOTPStep(user.ID, step)
+	if err != nil{
+		return err
+	}
+	if !used{
+		return fmt.Errorf("invalid two-factor code")
+	}
+	return nil
+}
+
+func totpIssuer() string{
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != ""{
+		return issuer
+	}
+	return "Fitness Tracker"
+}
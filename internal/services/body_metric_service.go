@@ -0,0 +1,236 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const defaultTrendWindowDays = 7
+
+type BodyMetricService struct {
+	MetricRepo *repositories.BodyMetricRepository
+}
+
+func NewBodyMetricService(repo *repositories.BodyMetricRepository) *BodyMetricService {
+	return &BodyMetricService{MetricRepo: repo}
+}
+
+func (s *BodyMetricService) CreateMetric(userID int, req models.RequestBodyMetric, unit units.System) (int, error){
+	const op = "internal.servises.CreateMetric"
+
+	metric, err := buildBodyMetric(userID, req, unit)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.MetricRepo.SaveMetric(metric)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *BodyMetricService) UpdateMetric(metricID int, userID int, req models.RequestBodyMetric, unit units.System) (int, error){
+	const op = "internal.servises.UpdateMetric"
+
+	existing, err := s.MetricRepo.GetMetricByID(metricID, userID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	metric, err := buildBodyMetric(userID, req, unit)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	// an edit keeps the entry at its date unless a new one is given
+	if req.MeasuredAt == nil{
+		metric.MeasuredAt = existing.MeasuredAt
+	}
+
+	id, err := s.MetricRepo.UpdateMetric(metric, metricID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *BodyMetricService) GetMetric(metricID int, userID int, unit units.System) (*models.BodyMetric, error){
+	const op = "internal.servises.GetMetric"
+
+	metric, err := s.MetricRepo.GetMetricByID(metricID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	presentBodyMetric(metric, unit)
+	return metric, nil
+}
+
+func (s *BodyMetricService) GetMetrics(userID int, from, to time.Time, unit units.System) ([]models.BodyMetric, error){
+	const op = "internal.servises.GetMetrics"
+
+	metrics, err := s.MetricRepo.GetMetrics(userID, from, to)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range metrics{
+		presentBodyMetric(&metrics[i], unit)
+	}
+	return metrics, nil
+}
+
+// presentBodyMetric converts the stored kilograms for output. Lengths stay in centimetres.
+func presentBodyMetric(metric *models.BodyMetric, unit units.System){
+	if metric.Weight != nil{
+		weight := units.FromKilograms(*metric.Weight, unit)
+		metric.Weight = &weight
+	}
+	metric.Unit = unit.WeightUnit()
+}
+
+func (s *BodyMetricService) DeleteMetric(metricID int, userID int) (int, error){
+	const op = "internal.servises.DeleteMetric"
+
+	deletedID, err := s.MetricRepo.DeleteMetric(metricID, userID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return deletedID, nil
+}
+
+// GetTrend smooths one metric with a trailing moving average: each point is the
+// mean of all values measured within windowDays up to and including it.
+func (s *BodyMetricService) GetTrend(userID int, metricName string, windowDays int, from, to time.Time, unit units.System) (*models.ResponseBodyMetricTrend, error){
+	const op = "internal.servises.GetTrend"
+
+	pick, ok := bodyMetricFields[metricName]
+	if !ok{
+		return nil, fmt.Errorf("%s: unknown metric %q", op, metricName)
+	}
+	if windowDays <= 0{
+		windowDays = defaultTrendWindowDays
+	}
+
+	// load an extra window before from so the first points are fully smoothed
+	window := time.Duration(windowDays) * 24 * time.Hour
+	metrics, err := s.MetricRepo.GetMetrics(userID, from.Add(-window), to)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	type sample struct {
+		at    time.Time
+		value float64
+	}
+	var samples []sample
+	for _, m := range metrics{
+		if v, ok := pick(m); ok{
+			samples = append(samples, sample{at: m.MeasuredAt, value: v})
+		}
+	}
+
+	points := make([]models.BodyMetricTrendPoint, 0, len(samples))
+	start, sum := 0, 0.0
+	for i, cur := range samples{
+		sum += cur.value
+		for samples[start].at.Before(cur.at.Add(-window)){
+			sum -= samples[start].value
+			start++
+		}
+		if cur.at.Before(from){
+			continue
+		}
+		points = append(points, models.BodyMetricTrendPoint{
+			MeasuredAt: cur.at,
+			Value: cur.value,
+			MovingAverage: sum / float64(i-start+1),
+		})
+	}
+
+	trend := &models.ResponseBodyMetricTrend{Metric: metricName, WindowDays: windowDays, Points: points}
+	if metricName == "weight"{
+		for i := range trend.Points{
+			trend.Points[i].Value = units.FromKilograms(trend.Points[i].Value, unit)
+			trend.Points[i].MovingAverage = units.FromKilograms(trend.Points[i].MovingAverage, unit)
+		}
+		trend.Unit = unit.WeightUnit()
+	}
+	return trend, nil
+}
+
+var bodyMetricFields = map[string]func(models.BodyMetric) (float64, bool){
+	"weight": func(m models.BodyMetric) (float64, bool) { return floatValue(m.Weight) },
+	"body_fat": func(m models.BodyMetric) (float64, bool) { return floatValue(m.BodyFat) },
+	"waist": func(m models.BodyMetric) (float64, bool) { return floatValue(m.Waist) },
+	"chest": func(m models.BodyMetric) (float64, bool) { return floatValue(m.Chest) },
+	"arms": func(m models.BodyMetric) (float64, bool) { return floatValue(m.Arms) },
+	"thighs": func(m models.BodyMetric) (float64, bool) { return floatValue(m.Thighs) },
+	"resting_heart_rate": func(m models.BodyMetric) (float64, bool) {
+		if m.RestingHeartRate == nil{
+			return 0, false
+		}
+		return float64(*m.RestingHeartRate), true
+	},
+}
+
+func floatValue(v *float64) (float64, bool){
+	if v == nil{
+		return 0, false
+	}
+	return *v, true
+}
+
+func buildBodyMetric(userID int, req models.RequestBodyMetric, unit units.System) (models.BodyMetric, error){
+	if req.Weight != nil{
+		weight := units.ToKilograms(*req.Weight, unit)
+		req.Weight = &weight
+	}
+
+	metric := models.BodyMetric{
+		UserID: userID,
+		MeasuredAt: time.Now(),
+		Weight: req.Weight,
+		BodyFat: req.BodyFat,
+		Waist: req.Waist,
+		Chest: req.Chest,
+		Arms: req.Arms,
+		Thighs: req.Thighs,
+		RestingHeartRate: req.RestingHeartRate,
+	}
+	if req.MeasuredAt != nil{
+		if req.MeasuredAt.After(time.Now().Add(time.Hour)){
+			return models.BodyMetric{}, fmt.Errorf("measured_at can not be in the future")
+		}
+		metric.MeasuredAt = *req.MeasuredAt
+	}
+
+	if req.Weight == nil && req.BodyFat == nil && req.Waist == nil && req.Chest == nil &&
+		req.Arms == nil && req.Thighs == nil && req.RestingHeartRate == nil{
+		return models.BodyMetric{}, fmt.Errorf("at least one measurement is required")
+	}
+
+	checks := []struct {
+		name     string
+		value    *float64
+		min, max float64
+	}{
+		{"weight", req.Weight, 20, 500},
+		{"body_fat", req.BodyFat, 2, 75},
+		{"waist", req.Waist, 30, 300},
+		{"chest", req.Chest, 30, 300},
+		{"arms", req.Arms, 10, 100},
+		{"thighs", req.Thighs, 20, 150},
+	}
+	for _, c := range checks{
+		if c.value != nil && (*c.value < c.min || *c.value > c.max){
+			return models.BodyMetric{}, fmt.Errorf("%s must be between %g and %g", c.name, c.min, c.max)
+		}
+	}
+	if req.RestingHeartRate != nil && (*req.RestingHeartRate < 20 || *req.RestingHeartRate > 250){
+		return models.BodyMetric{}, fmt.Errorf("resting_heart_rate must be between 20 and 250")
+	}
+
+	return metric, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/auth"
+	"github.com/artembliss/go-fitness-tracker/pkg/ical"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const (
+	calendarProductID = "-//go-fitness-tracker//Workouts//EN"
+	// calendarHistory limits how far back completed workouts are published.
+	calendarHistory = 365 * 24 * time.Hour
+)
+
+type CalendarService struct {
+	CalendarRepo *repositories.CalendarRepository
+	ExportRepo   *repositories.ExportRepository
+	UserRepo     *repositories.UserRepository
+	Schedule     *ScheduleService
+}
+
+func NewCalendarService(calendarRepo *repositories.CalendarRepository, exportRepo *repositories.ExportRepository, userRepo *repositories.UserRepository, schedule *ScheduleService) *CalendarService {
+	return &CalendarService{CalendarRepo: calendarRepo, ExportRepo: exportRepo, UserRepo: userRepo, Schedule: schedule}
+}
+
+// CreateFeed generates a new secret feed URL, revoking the previous one.
+func (s *CalendarService) CreateFeed(userID int) (*models.ResponseCalendarFeed, error){
+	const op = "services.calendar_service.CreateFeed"
+
+	token, err := auth.GenerateRandomToken(32)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	feed, err := s.CalendarRepo.SaveFeed(userID, auth.HashToken(token))
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &models.ResponseCalendarFeed{
+		URL: fmt.Sprintf("%s/calendar/%s.ics", appBaseURL(), token),
+		CreatedAt: feed.CreatedAt,
+	}, nil
+}
+
+func (s *CalendarService) GetFeed(userID int) (*models.CalendarFeed, error){
+	const op = "services.calendar_service.GetFeed"
+
+	feed, err := s.CalendarRepo.GetFeed(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return feed, nil
+}
+
+func (s *CalendarService) RevokeFeed(userID int) error{
+	const op = "services.calendar_service.RevokeFeed"
+
+	deleted, err := s.CalendarRepo.DeleteFeed(userID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !deleted{
+		return fmt.Errorf("%s: no calendar feed to revoke", op)
+	}
+	return nil
+}
+
+// BuildFeed returns the calendar behind a feed token: the completed workouts
+// of the last year as all-day events, in the user's preferred unit, followed
+// by the planned sessions of the coming weeks as tentative events.
+func (s *CalendarService) BuildFeed(token string) (*ical.Calendar, error){
+	const op = "services.calendar_service.BuildFeed"
+
+	feed, err := s.CalendarRepo.GetFeedByTokenHash(auth.HashToken(token))
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	user, err := s.UserRepo.GetUserByID(feed.UserID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	unit, err := units.Resolve(user.UnitSystem)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cal := &ical.Calendar{Name: "Workouts", ProductID: calendarProductID, Events: []ical.Event{}}
+
+	now := time.Now()
+	var rows []models.ExportWorkoutRow
+	flush := func(){
+		if len(rows) > 0{
+			cal.Events = append(cal.Events, workoutEvent(rows, unit))
+			rows = nil
+		}
+	}
+	err = s.ExportRepo.StreamWorkoutRows(feed.UserID, now.Add(-calendarHistory), now.AddDate(0, 0, 1), func(row models.ExportWorkoutRow) error{
+		if len(rows) > 0 && rows[0].WorkoutID != row.WorkoutID{
+			flush()
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	flush()
+
+	if s.Schedule != nil{
+		entries, err := s.Schedule.GetSchedule(feed.UserID, today(), today().Add(scheduleHorizon))
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		for _, entry := range entries{
+			if entry.Kind != models.ScheduleEntrySession || entry.Status != models.SessionStatusPlanned{
+				continue
+			}
+			if event, ok := sessionEvent(entry); ok{
+				cal.Events = append(cal.Events, event)
+			}
+		}
+	}
+
+	return cal, nil
+}
+
+// sessionEvent turns a planned session into a tentative all-day event.
+// Sessions of a recurring rule are identified by the rule and their original
+// date, so the event keeps its UID once a session computed on the fly is
+// stored, or when it is moved to another day.
+func sessionEvent(entry models.ScheduleEntry) (ical.Event, bool){
+	var uid string
+	switch{
+	case entry.RuleID != nil:
+		date := entry.Date
+		if entry.RescheduledFrom != nil{
+			date = *entry.RescheduledFrom
+		}
+		uid = fmt.Sprintf("rule-%d-%s@go-fitness-tracker", *entry.RuleID, date.Format("20060102"))
+	case entry.SessionID != nil:
+		uid = fmt.Sprintf("session-%d@go-fitness-tracker", *entry.SessionID)
+	default:
+		return ical.Event{}, false
+	}
+
+	return ical.Event{
+		UID: uid,
+		Start: entry.Date,
+		End: entry.Date.AddDate(0, 0, 1),
+		AllDay: true,
+		Summary: fmt.Sprintf("Planned: %s – %s", entry.Program, entry.DayName),
+		Status: "TENTATIVE",
+	}, true
+}
+
+// workoutEvent turns the entry rows of one workout into an all-day event.
+// The summary names the program and exercises; the description has the sets.
+func workoutEvent(rows []models.ExportWorkoutRow, unit units.System) ical.Event{
+	first := rows[0]
+
+	title := "Workout"
+	if first.ProgramName != nil{
+		title = *first.ProgramName
+	}
+
+	var names, details []string
+	for _, row := range rows{
+		if row.ExerciseName == nil{
+			continue
+		}
+		names = append(names, *row.ExerciseName)
+		if d := describeExportEntry(row, unit); d != ""{
+			details = append(details, *row.ExerciseName+": "+d)
+		}
+	}
+
+	summary := title
+	if len(names) > 0{
+		summary += ": " + strings.Join(names, ", ")
+	}
+	if first.Duration > 0{
+		details = append(details, "Duration: "+first.Duration.Round(time.Second).String())
+	}
+	if first.Calories > 0{
+		details = append(details, fmt.Sprintf("Calories: %.0f kcal", first.Calories))
+	}
+
+	return ical.Event{
+		UID: fmt.Sprintf("workout-%d@go-fitness-tracker", first.WorkoutID),
+		Start: first.Date,
+		End: first.Date.AddDate(0, 0, 1),
+		AllDay: true,
+		Summary: summary,
+		Description: strings.Join(details, "\n"),
+		Status: "CONFIRMED",
+	}
+}
+
+// describeExportEntry renders "5.2 km in 25m0s" for cardio and
+// "10 x 60 kg, 8 x 62.5 kg" (or just reps) for everything else.
+func describeExportEntry(row models.ExportWorkoutRow, unit units.System) string{
+	if row.ExerciseType != nil && *row.ExerciseType == models.ExerciseTypeCardio{
+		var parts []string
+		if row.Distance != nil{
+			parts = append(parts, fmt.Sprintf("%s %s", strconv.FormatFloat(units.DistanceFromMeters(*row.Distance, unit), 'f', -1, 64), unit.DistanceUnit()))
+		}
+		if row.DurationSeconds != nil{
+			parts = append(parts, (time.Duration(*row.DurationSeconds) * time.Second).String())
+		}
+		return strings.Join(parts, " in ")
+	}
+
+	sets := make([]string, 0, len(row.Reps))
+	for i, reps := range row.Reps{
+		set := strconv.FormatInt(reps, 10)
+		if i < len(row.Weight) && row.Weight[i] > 0{
+			weight := strconv.FormatFloat(units.FromKilograms(row.Weight[i], unit), 'f', -1, 64)
+			set += " x " + weight + " " + unit.WeightUnit()
+		}
+		sets = append(sets, set)
+	}
+	return strings.Join(sets, ", ")
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+func TestSessionEvent(t *testing.T){
+	date := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	moved := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct{
+		name    string
+		entry   models.ScheduleEntry
+		wantUID string
+		wantOK  bool
+	}{
+		{
+			"single session",
+			models.ScheduleEntry{SessionID: intPtr(7)},
+			"session-7@go-fitness-tracker", true,
+		},
+		{
+			"stored rule session",
+			models.ScheduleEntry{SessionID: intPtr(7), RuleID: intPtr(3)},
+			"rule-3-20240603@go-fitness-tracker", true,
+		},
+		{
+			// computed on the fly past the stored schedule
+			"projected rule session",
+			models.ScheduleEntry{RuleID: intPtr(3)},
+			"rule-3-20240603@go-fitness-tracker", true,
+		},
+		{
+			"rescheduled rule session",
+			models.ScheduleEntry{SessionID: intPtr(7), RuleID: intPtr(3), RescheduledFrom: &moved},
+			"rule-3-20240601@go-fitness-tracker", true,
+		},
+		{
+			"neither session nor rule",
+			models.ScheduleEntry{},
+			"", false,
+		},
+	}
+	for _, tt := range tests{
+		t.Run(tt.name, func(t *testing.T){
+			entry := tt.entry
+			entry.Date = date
+			entry.Kind = models.ScheduleEntrySession
+			entry.Status = models.SessionStatusPlanned
+			entry.Program = "Strength"
+			entry.DayName = "Push"
+
+			event, ok := sessionEvent(entry)
+			if ok != tt.wantOK || event.UID != tt.wantUID{
+				t.Fatalf("sessionEvent() = %q, %v, want %q, %v", event.UID, ok, tt.wantUID, tt.wantOK)
+			}
+			if !ok{
+				return
+			}
+			if !event.Start.Equal(date) || !event.End.Equal(date.AddDate(0, 0, 1)) || !event.AllDay || event.Status != "TENTATIVE"{
+				t.Errorf("event = %+v, want a tentative all-day event on %v", event, date)
+			}
+			if event.Summary != "Planned: Strength – Push"{
+				t.Errorf("Summary = %q", event.Summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+// metByType holds MET values (Compendium of Physical Activities) for the
+// exercise types of the catalog.
+var metByType = map[string]float64{
+	"strength":              5.0,
+	"powerlifting":          6.0,
+	"olympic_weightlifting": 6.0,
+	"strongman":             6.0,
+	"cardio":                7.0,
+	"plyometrics":           8.0,
+	"stretching":            2.5,
+}
+
+const (
+	defaultMET = 4.0
+	// standard resting oxygen uptake in ml/kg/min that MET values are based on
+	restingVO2 = 3.5
+)
+
+type metSample struct {
+	met    float64
+	weight float64
+}
+
+func metForType(exerciseType string) float64{
+	if met, ok := metByType[strings.ToLower(exerciseType)]; ok{
+		return met
+	}
+	return defaultMET
+}
+
+// EstimateCalories estimates energy expenditure from the workout's average MET
+// and duration. When age, height and gender are known the MET is corrected for
+// the user's own resting metabolic rate (Harris-Benedict), which matters most
+// for people far from the 70 kg reference adult. It returns false when the
+// user's weight is unknown.
+func EstimateCalories(user models.User, samples []metSample, duration time.Duration) (float64, bool){
+	if user.Weight <= 0 || duration <= 0{
+		return 0, false
+	}
+
+	met := defaultMET
+	var total, weights float64
+	for _, s := range samples{
+		total += s.met * s.weight
+		weights += s.weight
+	}
+	if weights > 0{
+		met = total / weights
+	}
+
+	vo2 := restingVO2
+	if rmr, ok := restingMetabolicRate(user); ok{
+		// kcal/day -> ml O2/kg/min, using 5 kcal per litre of oxygen
+		vo2 = rmr / 1440 / 5 * 1000 / user.Weight
+	}
+
+	kcalPerMinute := met * restingVO2 * restingVO2 / vo2 * user.Weight / 200
+	return math.Round(kcalPerMinute*duration.Minutes()*10) / 10, true
+}
+
+func restingMetabolicRate(user models.User) (float64, bool){
+	if user.Age <= 0 || user.Height <= 0{
+		return 0, false
+	}
+
+	w, h, a := user.Weight, float64(user.Height), float64(user.Age)
+	male := 88.362 + 13.397*w + 4.799*h - 5.677*a
+	female := 447.593 + 9.247*w + 3.098*h - 4.330*a
+
+	switch strings.ToLower(user.Gender){
+	case "male":
+		return male, true
+	case "female":
+		return female, true
+	default:
+		return (male + female) / 2, true
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const (
+	minHeartRate = 30
+	maxHeartRate = 250
+)
+
+// paceDistances are the distances best paces are reported for, in metres.
+var paceDistances = []struct {
+	name   string
+	meters float64
+}{
+	{"1k", 1000},
+	{"5k", 5000},
+	{"10k", 10000},
+	{"half_marathon", 21097.5},
+	{"marathon", 42195},
+}
+
+// mapCardioToDB converts the cardio part of a request entry to metres and seconds.
+func mapCardioToDB(ex models.ExerciseRequestEntry, entry *models.ExerciseEntry, unit units.System) error{
+	if ex.Distance != nil{
+		if *ex.Distance <= 0{
+			return fmt.Errorf("%s: distance must be positive", ex.Name)
+		}
+		meters := units.DistanceToMeters(*ex.Distance, unit)
+		entry.Distance = &meters
+	}
+	if ex.Duration != ""{
+		d, err := time.ParseDuration(ex.Duration)
+		if err != nil || d <= 0{
+			return fmt.Errorf("%s: invalid duration format", ex.Name)
+		}
+		seconds := int(d.Seconds())
+		entry.DurationSeconds = &seconds
+	}
+	if ex.ElevationGain != nil{
+		if *ex.ElevationGain < 0{
+			return fmt.Errorf("%s: elevation gain can not be negative", ex.Name)
+		}
+		meters := units.ElevationToMeters(*ex.ElevationGain, unit)
+		entry.ElevationGain = &meters
+	}
+	entry.AvgHeartRate = ex.AvgHeartRate
+	entry.MaxHeartRate = ex.MaxHeartRate
+	entry.AvgCadence = ex.AvgCadence
+
+	for _, in := range ex.Intervals{
+		d, err := time.ParseDuration(in.Duration)
+		if err != nil || d <= 0{
+			return fmt.Errorf("%s: invalid interval duration format", ex.Name)
+		}
+		interval := models.Interval{Type: in.Type, DurationSeconds: int(d.Seconds())}
+		if in.Distance != nil{
+			if *in.Distance <= 0{
+				return fmt.Errorf("%s: interval distance must be positive", ex.Name)
+			}
+			interval.Distance = units.DistanceToMeters(*in.Distance, unit)
+		}
+		if in.AvgHeartRate != nil{
+			interval.AvgHeartRate = *in.AvgHeartRate
+		}
+		if in.AvgCadence != nil{
+			interval.AvgCadence = *in.AvgCadence
+		}
+		entry.Intervals = append(entry.Intervals, interval)
+	}
+	return nil
+}
+
+// mapCardioToResponse fills the cardio fields of a response entry in the requested unit.
+func mapCardioToResponse(ex models.ExerciseEntry, entry *models.ExerciseRequestEntry, unit units.System){
+	if ex.Distance != nil{
+		distance := units.DistanceFromMeters(*ex.Distance, unit)
+		entry.Distance = &distance
+	}
+	if ex.DurationSeconds != nil{
+		entry.Duration = (time.Duration(*ex.DurationSeconds) * time.Second).String()
+	}
+	if ex.Distance != nil && ex.DurationSeconds != nil{
+		entry.Pace = formatPace(float64(*ex.DurationSeconds), *ex.Distance, unit)
+	}
+	if ex.ElevationGain != nil{
+		elevation := units.ElevationFromMeters(*ex.ElevationGain, unit)
+		entry.ElevationGain = &elevation
+	}
+	entry.AvgHeartRate = ex.AvgHeartRate
+	entry.MaxHeartRate = ex.MaxHeartRate
+	entry.AvgCadence = ex.AvgCadence
+
+	for _, in := range ex.Intervals{
+		interval := models.RequestInterval{
+			Type: in.Type,
+			Duration: (time.Duration(in.DurationSeconds) * time.Second).String(),
+		}
+		if in.Distance > 0{
+			distance := units.DistanceFromMeters(in.Distance, unit)
+			interval.Distance = &distance
+			interval.Pace = formatPace(float64(in.DurationSeconds), in.Distance, unit)
+		}
+		if in.AvgHeartRate > 0{
+			hr := in.AvgHeartRate
+			interval.AvgHeartRate = &hr
+		}
+		if in.AvgCadence > 0{
+			cadence := in.AvgCadence
+			interval.AvgCadence = &cadence
+		}
+		entry.Intervals = append(entry.Intervals, interval)
+	}
+}
+
+// validateExerciseEntry checks an entry against the type of its exercise:
+// cardio needs a distance or a duration, everything else needs sets and reps.
+func validateExerciseEntry(name string, ex models.ExerciseEntry, exerciseType string) error{
+	if exerciseType == models.ExerciseTypeCardio{
+		if ex.Distance == nil && ex.DurationSeconds == nil{
+			return fmt.Errorf("%s: cardio entries need a distance or a duration", name)
+		}
+		if ex.Sets < 0{
+			return fmt.Errorf("%s: sets can not be negative", name)
+		}
+	} else{
+		if ex.Sets < 1{
+			return fmt.Errorf("%s: sets must be at least 1", name)
+		}
+		if len(ex.Reps) != ex.Sets{
+			return fmt.Errorf("%s: reps must have one value per set", name)
+		}
+		if len(ex.Weight) != 0 && len(ex.Weight) != ex.Sets{
+			return fmt.Errorf("%s: weight must be empty or have one value per set", name)
+		}
+		if ex.Distance != nil || ex.DurationSeconds != nil || ex.AvgCadence != nil || len(ex.Intervals) > 0{
+			return fmt.Errorf("%s: distance, duration and intervals are only allowed for cardio exercises", name)
+		}
+	}
+
+	for _, hr := range []*int{ex.AvgHeartRate, ex.MaxHeartRate}{
+		if hr != nil && (*hr < minHeartRate || *hr > maxHeartRate){
+			return fmt.Errorf("%s: heart rate must be between %d and %d bpm", name, minHeartRate, maxHeartRate)
+		}
+	}
+	if ex.AvgHeartRate != nil && ex.MaxHeartRate != nil && *ex.AvgHeartRate > *ex.MaxHeartRate{
+		return fmt.Errorf("%s: average heart rate can not exceed max heart rate", name)
+	}
+	for _, in := range ex.Intervals{
+		if in.AvgHeartRate != 0 && (in.AvgHeartRate < minHeartRate || in.AvgHeartRate > maxHeartRate){
+			return fmt.Errorf("%s: heart rate must be between %d and %d bpm", name, minHeartRate, maxHeartRate)
+		}
+	}
+	return nil
+}
+
+// formatPace renders seconds per kilometre or mile as "m:ss/km".
+func formatPace(seconds, meters float64, unit units.System) string{
+	if meters <= 0 || seconds <= 0{
+		return ""
+	}
+	pace := int(math.Round(seconds / (meters / units.DistanceToMeters(1, unit))))
+	return fmt.Sprintf("%d:%02d/%s", pace/60, pace%60, unit.DistanceUnit())
+}
+
+// BuildWorkoutStats aggregates cardio entries into weekly totals (weeks start
+// on Monday) and the best pace per standard distance for every exercise.
+// Only entries at least as long as a distance count towards its best pace.
+func BuildWorkoutStats(entries []models.CardioEntryStat, unit units.System) models.ResponseWorkoutStats{
+	stats := models.ResponseWorkoutStats{
+		Unit: unit.DistanceUnit(),
+		WeeklyDistance: []models.WeeklyDistance{},
+		BestPaces: []models.BestPace{},
+	}
+
+	type weekTotal struct {
+		meters   float64
+		seconds  int
+		sessions map[int]bool
+	}
+	weeks := make(map[time.Time]*weekTotal)
+	var totalMeters float64
+
+	type bestKey struct {
+		exercise string
+		distance int
+	}
+	best := make(map[bestKey]models.CardioEntryStat)
+
+	for _, e := range entries{
+		week := startOfWeek(e.Date)
+		w, ok := weeks[week]
+		if !ok{
+			w = &weekTotal{sessions: make(map[int]bool)}
+			weeks[week] = w
+		}
+		w.meters += e.Distance
+		if e.DurationSeconds != nil{
+			w.seconds += *e.DurationSeconds
+		}
+		w.sessions[e.WorkoutID] = true
+		totalMeters += e.Distance
+
+		if e.DurationSeconds == nil || e.Distance <= 0{
+			continue
+		}
+		for i, d := range paceDistances{
+			if e.Distance < d.meters{
+				break
+			}
+			key := bestKey{e.Exercise, i}
+			current, ok := best[key]
+			if !ok || paceOf(e) < paceOf(current){
+				best[key] = e
+			}
+		}
+	}
+
+	for week, w := range weeks{
+		stats.WeeklyDistance = append(stats.WeeklyDistance, models.WeeklyDistance{
+			WeekStart: week,
+			Distance: units.DistanceFromMeters(w.meters, unit),
+			Duration: (time.Duration(w.seconds) * time.Second).String(),
+			Sessions: len(w.sessions),
+		})
+	}
+	sort.Slice(stats.WeeklyDistance, func(i, j int) bool{
+		return stats.WeeklyDistance[i].WeekStart.Before(stats.WeeklyDistance[j].WeekStart)
+	})
+
+	for key, e := range best{
+		stats.BestPaces = append(stats.BestPaces, models.BestPace{
+			Exercise: key.exercise,
+			Distance: paceDistances[key.distance].name,
+			Pace: formatPace(float64(*e.DurationSeconds), e.Distance, unit),
+			WorkoutID: e.WorkoutID,
+			Date: e.Date,
+		})
+	}
+	sort.Slice(stats.BestPaces, func(i, j int) bool{
+		a, b := stats.BestPaces[i], stats.BestPaces[j]
+		if a.Exercise != b.Exercise{
+			return a.Exercise < b.Exercise
+		}
+		return distanceIndex(a.Distance) < distanceIndex(b.Distance)
+	})
+
+	stats.TotalDistance = units.DistanceFromMeters(totalMeters, unit)
+	return stats
+}
+
+func paceOf(e models.CardioEntryStat) float64{
+	return float64(*e.DurationSeconds) / e.Distance
+}
+
+func distanceIndex(name string) int{
+	for i, d := range paceDistances{
+		if d.name == name{
+			return i
+		}
+	}
+	return len(paceDistances)
+}
+
+func startOfWeek(t time.Time) time.Time{
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
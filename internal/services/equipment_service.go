@@ -0,0 +1,238 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+	"github.com/lib/pq"
+)
+
+const (
+	maxEquipmentProfileName = 64
+	// maxLoadIncrement caps plate and dumbbell increments, in kilograms.
+	maxLoadIncrement = 50.0
+)
+
+// plateEquipment is loaded with plates, dumbbellEquipment in fixed steps.
+var (
+	plateEquipment    = map[string]bool{"barbell": true, "e-z_curl_bar": true}
+	dumbbellEquipment = map[string]bool{"dumbbell": true, "kettlebells": true}
+)
+
+type EquipmentService struct {
+	EquipmentRepo *repositories.EquipmentRepository
+	ExerciseRepo  *repositories.ExerciseRepository
+}
+
+func NewEquipmentService(repo *repositories.EquipmentRepository, exerciseRepo *repositories.ExerciseRepository) *EquipmentService{
+	return &EquipmentService{EquipmentRepo: repo, ExerciseRepo: exerciseRepo}
+}
+
+func (s *EquipmentService) CreateProfile(userID int, req models.RequestEquipmentProfile, unit units.System) (int, error){
+	const op = "internal.servises.CreateProfile"
+
+	profile, err := s.buildProfile(userID, 0, req, unit)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.EquipmentRepo.CreateProfile(profile)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *EquipmentService) UpdateProfile(profileID int, userID int, req models.RequestEquipmentProfile, unit units.System) (int, error){
+	const op = "internal.servises.UpdateProfile"
+
+	if _, err := s.EquipmentRepo.GetProfile(profileID, userID); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	profile, err := s.buildProfile(userID, profileID, req, unit)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.EquipmentRepo.UpdateProfile(profile)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *EquipmentService) ListProfiles(userID int, unit units.System) ([]models.ResponseEquipmentProfile, error){
+	const op = "internal.servises.ListProfiles"
+
+	profiles, err := s.EquipmentRepo.GetProfiles(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp := make([]models.ResponseEquipmentProfile, 0, len(profiles))
+	for _, profile := range profiles{
+		resp = append(resp, presentEquipmentProfile(profile, unit))
+	}
+	return resp, nil
+}
+
+func (s *EquipmentService) DeleteProfile(profileID int, userID int) (int, error){
+	const op = "internal.servises.DeleteProfile"
+
+	id, err := s.EquipmentRepo.DeleteProfile(profileID, userID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// ActiveEquipment returns the equipment of the given profile, or of the
+// default one when profileID is nil, with body weight always included. Users
+// without profiles get nil, which means any equipment.
+func (s *EquipmentService) ActiveEquipment(userID int, profileID *int) ([]string, *models.EquipmentProfile, error){
+	const op = "internal.servises.ActiveEquipment"
+
+	var profile *models.EquipmentProfile
+	var err error
+	if profileID != nil{
+		profile, err = s.EquipmentRepo.GetProfile(*profileID, userID)
+	} else{
+		profile, err = s.EquipmentRepo.GetDefaultProfile(userID)
+		if errors.Is(err, sql.ErrNoRows){
+			return nil, nil, nil
+		}
+	}
+	if err != nil{
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	equipment := []string{bodyWeightEquipment}
+	for _, e := range profile.Equipment{
+		if e != bodyWeightEquipment{
+			equipment = append(equipment, e)
+		}
+	}
+	return equipment, profile, nil
+}
+
+// DefaultIncrement is the load step in kilograms the user's default profile
+// allows for an exercise: the plate increment for bars, the dumbbell
+// increment for dumbbells and kettlebells. It is 0 when none is set.
+func (s *EquipmentService) DefaultIncrement(userID int, exerciseID int) (float64, error){
+	const op = "internal.servises.DefaultIncrement"
+
+	profile, err := s.EquipmentRepo.GetDefaultProfile(userID)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	exercise, err := s.ExerciseRepo.GetExercisesByID(exerciseID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	equipment := normalizeEquipment(exercise.Equipment)
+	switch{
+	case plateEquipment[equipment] && profile.PlateIncrement != nil:
+		return *profile.PlateIncrement, nil
+	case dumbbellEquipment[equipment] && profile.DumbbellIncrement != nil:
+		return *profile.DumbbellIncrement, nil
+	}
+	return 0, nil
+}
+
+func (s *EquipmentService) buildProfile(userID int, profileID int, req models.RequestEquipmentProfile, unit units.System) (models.EquipmentProfile, error){
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxEquipmentProfileName{
+		return models.EquipmentProfile{}, fmt.Errorf("name must be 1 to %d characters", maxEquipmentProfileName)
+	}
+
+	existing, err := s.EquipmentRepo.GetProfiles(userID)
+	if err != nil{
+		return models.EquipmentProfile{}, err
+	}
+	for _, profile := range existing{
+		if profile.ID != profileID && strings.EqualFold(profile.Name, name){
+			return models.EquipmentProfile{}, fmt.Errorf("profile %q already exists", name)
+		}
+	}
+
+	catalog, err := s.ExerciseRepo.GetAllExercises()
+	if err != nil{
+		return models.EquipmentProfile{}, err
+	}
+	seen := map[string]bool{}
+	equipment := pq.StringArray{}
+	for _, e := range req.Equipment{
+		e = normalizeEquipment(e)
+		if !seen[e]{
+			seen[e] = true
+			equipment = append(equipment, e)
+		}
+	}
+	if err := checkEquipment(equipment, catalog); err != nil{
+		return models.EquipmentProfile{}, err
+	}
+
+	plate, err := loadIncrement("plate_increment", req.PlateIncrement, unit)
+	if err != nil{
+		return models.EquipmentProfile{}, err
+	}
+	dumbbell, err := loadIncrement("dumbbell_increment", req.DumbbellIncrement, unit)
+	if err != nil{
+		return models.EquipmentProfile{}, err
+	}
+
+	return models.EquipmentProfile{
+		ID: profileID,
+		UserID: userID,
+		Name: name,
+		Equipment: equipment,
+		PlateIncrement: plate,
+		DumbbellIncrement: dumbbell,
+		IsDefault: req.Default,
+	}, nil
+}
+
+// loadIncrement converts an optional increment to kilograms.
+func loadIncrement(field string, value *float64, unit units.System) (*float64, error){
+	if value == nil{
+		return nil, nil
+	}
+	kg := units.ToKilograms(*value, unit)
+	if kg <= 0 || kg > maxLoadIncrement{
+		return nil, fmt.Errorf("%s must be positive and at most %g %s", field,
+			units.FromKilograms(maxLoadIncrement, unit), unit.WeightUnit())
+	}
+	return &kg, nil
+}
+
+func presentEquipmentProfile(profile models.EquipmentProfile, unit units.System) models.ResponseEquipmentProfile{
+	resp := models.ResponseEquipmentProfile{
+		ID: profile.ID,
+		Name: profile.Name,
+		Equipment: []string{},
+		Default: profile.IsDefault,
+		Unit: unit.WeightUnit(),
+		CreatedAt: profile.CreatedAt,
+	}
+	if len(profile.Equipment) > 0{
+		resp.Equipment = []string(profile.Equipment)
+	}
+	if profile.PlateIncrement != nil{
+		v := units.FromKilograms(*profile.PlateIncrement, unit)
+		resp.PlateIncrement = &v
+	}
+	if profile.DumbbellIncrement != nil{
+		v := units.FromKilograms(*profile.DumbbellIncrement, unit)
+		resp.DumbbellIncrement = &v
+	}
+	return resp
+}
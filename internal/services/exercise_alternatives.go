@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+const maxAlternativesLimit = 50
+
+// equipmentFamilies groups catalog equipment that can usually stand in for
+// each other.
+var equipmentFamilies = map[string]string{
+	"barbell": "free_weight",
+	"dumbbell": "free_weight",
+	"e-z_curl_bar": "free_weight",
+	"kettlebells": "free_weight",
+	"machine": "machine",
+	"cable": "machine",
+	"body_only": "body_weight",
+	"bands": "body_weight",
+	"exercise_ball": "body_weight",
+	"medicine_ball": "body_weight",
+}
+
+var strengthExerciseTypes = map[string]bool{
+	"strength": true,
+	"powerlifting": true,
+	"olympic_weightlifting": true,
+	"strongman": true,
+}
+
+var difficultyRank = map[string]int{
+	models.ExperienceBeginner: 0,
+	models.ExperienceIntermediate: 1,
+	models.ExperienceExpert: 2,
+}
+
+// GetAlternatives ranks the catalog exercises that train the same muscle
+// group as exerciseID by how closely their equipment, type and difficulty
+// match. Only exercises using the given equipment (or body weight) are
+// returned; without equipment the user's equipment profile applies, the one
+// given or else the default.
+func (s *ExerciseService) GetAlternatives(ctx context.Context, userID int, exerciseID int, equipment []string, profileID *int, limit int) ([]models.ExerciseAlternative, error){
+	const op = "internal.services.GetAlternatives"
+
+	if limit < 1 || limit > maxAlternativesLimit{
+		return nil, fmt.Errorf("%s: limit must be between 1 and %d", op, maxAlternativesLimit)
+	}
+	if len(equipment) == 0{
+		active, _, err := s.Equipment.ActiveEquipment(userID, profileID)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		equipment = active
+	}
+
+	catalog, err := s.GetAllExercises(ctx)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var original *models.Exercise
+	for i := range catalog{
+		if catalog[i].ID == exerciseID{
+			original = &catalog[i]
+			break
+		}
+	}
+	if original == nil{
+		return nil, fmt.Errorf("%s: %w", op, sql.ErrNoRows)
+	}
+
+	allowed := map[string]bool{bodyWeightEquipment: true}
+	for _, e := range equipment{
+		allowed[normalizeEquipment(e)] = true
+	}
+
+	alternatives := []models.ExerciseAlternative{}
+	for _, candidate := range catalog{
+		if candidate.ID == original.ID || !strings.EqualFold(candidate.MuscleGroup, original.MuscleGroup){
+			continue
+		}
+		if len(equipment) > 0 && !allowed[normalizeEquipment(candidate.Equipment)]{
+			continue
+		}
+		score, reasons := rankAlternative(*original, candidate)
+		alternatives = append(alternatives, models.ExerciseAlternative{
+			ID: candidate.ID,
+			Name: candidate.Name,
+			Type: candidate.Type,
+			MuscleGroup: candidate.MuscleGroup,
+			Equipment: candidate.Equipment,
+			Difficulty: candidate.Difficulty,
+			Score: score,
+			Reasons: reasons,
+		})
+	}
+
+	sort.SliceStable(alternatives, func(i, j int) bool{
+		if alternatives[i].Score != alternatives[j].Score{
+			return alternatives[i].Score > alternatives[j].Score
+		}
+		return alternatives[i].Name < alternatives[j].Name
+	})
+	if len(alternatives) > limit{
+		alternatives = alternatives[:limit]
+	}
+	return alternatives, nil
+}
+
+// rankAlternative scores a candidate that trains the same muscle group:
+// 3 for the same equipment or 2 for the same kind, 2 for the same type or 1
+// for another strength type, 2 for the same difficulty or 1 for one level off.
+func rankAlternative(original models.Exercise, candidate models.Exercise) (int, []string){
+	score := 0
+	reasons := []string{"same muscle group"}
+
+	originalEquipment, candidateEquipment := normalizeEquipment(original.Equipment), normalizeEquipment(candidate.Equipment)
+	switch{
+	case originalEquipment == candidateEquipment:
+		score += 3
+		reasons = append(reasons, "same equipment")
+	case equipmentFamilies[originalEquipment] != "" && equipmentFamilies[originalEquipment] == equipmentFamilies[candidateEquipment]:
+		score += 2
+		reasons = append(reasons, "similar equipment")
+	}
+
+	originalType, candidateType := strings.ToLower(original.Type), strings.ToLower(candidate.Type)
+	switch{
+	case originalType == candidateType:
+		score += 2
+		reasons = append(reasons, "same type")
+	case strengthExerciseTypes[originalType] && strengthExerciseTypes[candidateType]:
+		score++
+		reasons = append(reasons, "similar type")
+	}
+
+	originalRank, ok1 := difficultyRank[strings.ToLower(original.Difficulty)]
+	candidateRank, ok2 := difficultyRank[strings.ToLower(candidate.Difficulty)]
+	if ok1 && ok2{
+		switch originalRank - candidateRank{
+		case 0:
+			score += 2
+			reasons = append(reasons, "same difficulty")
+		case 1, -1:
+			score++
+			reasons = append(reasons, "similar difficulty")
+		}
+	}
+	return score, reasons
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+const (
+	maxGroupRounds      = 50
+	maxGroupRestSeconds = 3600
+)
+
+// buildExerciseGroups checks the groups of a program day or workout against
+// the group labels of its exercises, given in order ("" for exercises outside
+// any group). Groups the exercises refer to but that are not described are
+// added, and a missing type becomes superset for two exercises and giant_set
+// for more. The result follows the order of the exercises.
+func buildExerciseGroups(groups []models.ExerciseGroup, labels []string) (models.ExerciseGroups, error){
+	declared := make(map[string]models.ExerciseGroup, len(groups))
+	for _, group := range groups{
+		group.Label = strings.TrimSpace(group.Label)
+		if group.Label == ""{
+			return nil, fmt.Errorf("every group needs a label")
+		}
+		if _, ok := declared[group.Label]; ok{
+			return nil, fmt.Errorf("group %q is described twice", group.Label)
+		}
+		switch group.Type{
+		case "", models.ExerciseGroupSuperset, models.ExerciseGroupGiantSet, models.ExerciseGroupCircuit:
+		default:
+			return nil, fmt.Errorf("group %q: invalid type %q, expected superset, giant_set or circuit", group.Label, group.Type)
+		}
+		if group.Rounds != nil && (*group.Rounds < 1 || *group.Rounds > maxGroupRounds){
+			return nil, fmt.Errorf("group %q: rounds must be between 1 and %d", group.Label, maxGroupRounds)
+		}
+		if group.RestSeconds != nil && (*group.RestSeconds < 0 || *group.RestSeconds > maxGroupRestSeconds){
+			return nil, fmt.Errorf("group %q: rest_seconds must be between 0 and %d", group.Label, maxGroupRestSeconds)
+		}
+		declared[group.Label] = group
+	}
+
+	var order []string
+	counts := make(map[string]int)
+	previous := ""
+	for _, label := range labels{
+		if label != "" && label != previous{
+			if counts[label] > 0{
+				return nil, fmt.Errorf("the exercises of group %q must follow each other", label)
+			}
+			order = append(order, label)
+		}
+		if label != ""{
+			counts[label]++
+		}
+		previous = label
+	}
+
+	var result models.ExerciseGroups
+	for _, label := range order{
+		group, ok := declared[label]
+		if !ok{
+			group = models.ExerciseGroup{Label: label}
+		}
+		count := counts[label]
+		if count < 2{
+			return nil, fmt.Errorf("group %q needs at least two exercises", label)
+		}
+		if group.Type == ""{
+			group.Type = models.ExerciseGroupGiantSet
+			if count == 2{
+				group.Type = models.ExerciseGroupSuperset
+			}
+		}
+		if group.Type == models.ExerciseGroupSuperset && count != 2{
+			return nil, fmt.Errorf("group %q: a superset has two exercises, use giant_set or circuit for %d", label, count)
+		}
+		if group.Type == models.ExerciseGroupGiantSet && count < 3{
+			return nil, fmt.Errorf("group %q: a giant set has at least three exercises, use superset for two", label)
+		}
+		result = append(result, group)
+	}
+	for _, group := range groups{
+		if counts[strings.TrimSpace(group.Label)] == 0{
+			return nil, fmt.Errorf("group %q has no exercises", strings.TrimSpace(group.Label))
+		}
+	}
+	return result, nil
+}
+
+// groupLabel returns nil for exercises outside any group.
+func groupLabel(label string) *string{
+	label = strings.TrimSpace(label)
+	if label == ""{
+		return nil
+	}
+	return &label
+}
+
+func equalExerciseGroups(a models.ExerciseGroups, b models.ExerciseGroups) bool{
+	if len(a) != len(b){
+		return false
+	}
+	for i := range a{
+		if a[i].Label != b[i].Label || a[i].Type != b[i].Type ||
+			!equalPtr(a[i].Rounds, b[i].Rounds) || !equalPtr(a[i].RestSeconds, b[i].RestSeconds){
+			return false
+		}
+	}
+	return true
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/repositories"
@@ -19,12 +20,14 @@ import (
 type ExerciseService struct{
 	ExerciseRepo *repositories.ExerciseRepository
 	Cache        *redis.Client
+	Equipment    *EquipmentService
 }
 
-func NewExerciseService(repo *repositories.ExerciseRepository, cache *redis.Client) *ExerciseService {
+func NewExerciseService(repo *repositories.ExerciseRepository, cache *redis.Client, equipment *EquipmentService) *ExerciseService {
 	return &ExerciseService{
 		ExerciseRepo: repo,
 	    Cache: cache,
+		Equipment: equipment,
 	}
 }
 
@@ -260,4 +263,48 @@ func (s *ExerciseService) GetExercisesByDifficulty(param ...interface{}) (interf
 	}
 
 	return exercises, nil
-}
\ No newline at end of file
+}
+
+// GetAvailableExercises searches the catalog for exercises the user can do
+// with the equipment of a profile, the one given or else the default. Name
+// matches any part of the exercise name; the other filters match exactly.
+// Without profiles the whole catalog is searched.
+func (s *ExerciseService) GetAvailableExercises(ctx context.Context, userID int, profileID *int, search models.ExerciseSearch) ([]models.Exercise, error){
+	const op = "internal.servises.GetAvailableExercises"
+
+	equipment, _, err := s.Equipment.ActiveEquipment(userID, profileID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	allowed := map[string]bool{}
+	for _, e := range equipment{
+		allowed[e] = true
+	}
+
+	catalog, err := s.GetAllExercises(ctx)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	name := strings.ToLower(strings.TrimSpace(search.Name))
+	exercises := []models.Exercise{}
+	for _, exercise := range catalog{
+		if len(allowed) > 0 && !allowed[normalizeEquipment(exercise.Equipment)]{
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(exercise.Name), name){
+			continue
+		}
+		if search.Type != "" && !strings.EqualFold(exercise.Type, search.Type){
+			continue
+		}
+		if search.MuscleGroup != "" && !strings.EqualFold(exercise.MuscleGroup, search.MuscleGroup){
+			continue
+		}
+		if search.Difficulty != "" && !strings.EqualFold(exercise.Difficulty, search.Difficulty){
+			continue
+		}
+		exercises = append(exercises, exercise)
+	}
+	return exercises, nil
+}
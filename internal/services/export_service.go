@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/export"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const (
+	exportSectionWorkouts    = "workouts"
+	exportSectionPrograms    = "programs"
+	exportSectionBodyMetrics = "body_metrics"
+)
+
+// exportSections is the documented export layout. Columns may be appended
+// but never renamed, removed or reordered.
+var exportSections = []export.Section{
+	{Name: exportSectionWorkouts, Columns: []string{
+		"workout_id", "date", "program", "workout_duration_seconds", "calories",
+		"exercise", "exercise_type", "set_number", "reps", "weight", "weight_unit",
+		"distance", "distance_unit", "duration_seconds", "elevation_gain", "elevation_unit",
+		"avg_heart_rate", "max_heart_rate",
+	}},
+	{Name: exportSectionPrograms, Columns: []string{
+		"program_id", "program", "created_at", "exercise", "sets", "reps", "weight", "weight_unit",
+		"phase", "week", "day", "reps_max", "percent_e1rm",
+	}},
+	{Name: exportSectionBodyMetrics, Columns: []string{
+		"measured_at", "body_weight", "weight_unit", "body_fat", "waist", "chest", "arms", "thighs",
+		"resting_heart_rate",
+	}},
+}
+
+type ExportService struct {
+	ExportRepo *repositories.ExportRepository
+}
+
+func NewExportService(exportRepo *repositories.ExportRepository) *ExportService {
+	return &ExportService{ExportRepo: exportRepo}
+}
+
+// Export writes workouts and body metrics between from and to and every
+// program of the user to w, row by row, in the requested unit.
+func (s *ExportService) Export(w io.Writer, format string, userID int, from, to time.Time, unit units.System) error{
+	const op = "services.export_service.Export"
+
+	writer, err := export.NewWriter(format, w, exportSections)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, section := range exportSections{
+		if err := s.WriteSection(writer, section.Name, userID, from, to, unit); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := writer.Close(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// WriteSection starts the named section on writer and streams its rows.
+func (s *ExportService) WriteSection(writer export.Writer, name string, userID int, from, to time.Time, unit units.System) error{
+	const op = "services.export_service.WriteSection"
+
+	if err := writer.BeginSection(name); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var err error
+	switch name{
+	case exportSectionWorkouts:
+		err = s.ExportRepo.StreamWorkoutRows(userID, from, to, func(row models.ExportWorkoutRow) error{
+			for _, values := range workoutExportRows(row, unit){
+				if err := writer.WriteRow(values); err != nil{
+					return err
+				}
+			}
+			return nil
+		})
+	case exportSectionPrograms:
+		err = s.ExportRepo.StreamProgramRows(userID, func(row models.ExportProgramRow) error{
+			return writer.WriteRow([]interface{}{
+				row.ProgramID, row.ProgramName, row.CreatedAt.Format(time.RFC3339), stringOrNil(row.ExerciseName),
+				intOrNil(row.Sets), intOrNil(row.Reps), weightOrNil(row.Weight, unit), unit.WeightUnit(),
+				stringOrNil(row.Phase), intOrNil(row.Week), stringOrNil(row.Day), intOrNil(row.RepsMax),
+				floatOrNil(row.PercentE1RM),
+			})
+		})
+	case exportSectionBodyMetrics:
+		err = s.ExportRepo.StreamBodyMetrics(userID, from, to, func(m models.BodyMetric) error{
+			return writer.WriteRow([]interface{}{
+				m.MeasuredAt.Format(time.RFC3339), weightOrNil(m.Weight, unit), unit.WeightUnit(), floatOrNil(m.BodyFat),
+				floatOrNil(m.Waist), floatOrNil(m.Chest), floatOrNil(m.Arms), floatOrNil(m.Thighs),
+				intOrNil(m.RestingHeartRate),
+			})
+		})
+	default:
+		err = fmt.Errorf("unknown export section %q", name)
+	}
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// workoutExportRows expands an entry into one row per set. Cardio entries and
+// workouts without entries produce a single row.
+func workoutExportRows(row models.ExportWorkoutRow, unit units.System) [][]interface{}{
+	base := []interface{}{
+		row.WorkoutID, row.Date.Format("2006-01-02"), stringOrNil(row.ProgramName), int(row.Duration.Seconds()),
+		row.Calories, stringOrNil(row.ExerciseName), stringOrNil(row.ExerciseType),
+	}
+
+	entryRow := func(setNumber, reps, weight interface{}) []interface{}{
+		var distance, elevation interface{}
+		if row.Distance != nil{
+			distance = units.DistanceFromMeters(*row.Distance, unit)
+		}
+		if row.ElevationGain != nil{
+			elevation = units.ElevationFromMeters(*row.ElevationGain, unit)
+		}
+		values := append([]interface{}{}, base...)
+		return append(values, setNumber, reps, weight, unit.WeightUnit(),
+			distance, unit.DistanceUnit(), intOrNil(row.DurationSeconds), elevation, unit.ElevationUnit(),
+			intOrNil(row.AvgHeartRate), intOrNil(row.MaxHeartRate))
+	}
+
+	if row.ExerciseName == nil || (row.ExerciseType != nil && *row.ExerciseType == models.ExerciseTypeCardio){
+		return [][]interface{}{entryRow(nil, nil, nil)}
+	}
+
+	sets := len(row.Reps)
+	if row.Sets != nil && *row.Sets > sets{
+		sets = *row.Sets
+	}
+	if sets == 0{
+		return [][]interface{}{entryRow(nil, nil, nil)}
+	}
+
+	result := make([][]interface{}, 0, sets)
+	for i := 0; i < sets; i++{
+		var reps, weight interface{}
+		if i < len(row.Reps){
+			reps = int(row.Reps[i])
+		}
+		if i < len(row.Weight){
+			weight = units.FromKilograms(row.Weight[i], unit)
+		}
+		result = append(result, entryRow(i+1, reps, weight))
+	}
+	return result
+}
+
+func stringOrNil(v *string) interface{}{
+	if v == nil{
+		return nil
+	}
+	return *v
+}
+
+func intOrNil(v *int) interface{}{
+	if v == nil{
+		return nil
+	}
+	return *v
+}
+
+func floatOrNil(v *float64) interface{}{
+	if v == nil{
+		return nil
+	}
+	return *v
+}
+
+func weightOrNil(kg *float64, unit units.System) interface{}{
+	if kg == nil{
+		return nil
+	}
+	return units.FromKilograms(*kg, unit)
+}
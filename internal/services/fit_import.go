@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/pkg/fit"
+	"github.com/lib/pq"
+)
+
+// fitCategoryExercises maps FIT exercise categories onto catalog exercises.
+// The catalog comes from an external API, so entries are name keywords tried
+// in order rather than ids. Categories missing here are reported as unmapped.
+var fitCategoryExercises = map[string][]string{
+	"bench_press":        {"barbell bench press", "bench press"},
+	"calf_raise":         {"calf raise"},
+	"carry":              {"farmer", "carry"},
+	"chop":               {"wood chop", "chop"},
+	"crunch":             {"crunch"},
+	"curl":               {"barbell curl", "dumbbell bicep curl", "curl"},
+	"deadlift":           {"barbell deadlift", "deadlift"},
+	"flye":               {"dumbbell flye", "fly"},
+	"hip_raise":          {"glute bridge", "hip thrust", "hip raise"},
+	"hyperextension":     {"hyperextension", "back extension"},
+	"lateral_raise":      {"lateral raise"},
+	"leg_curl":           {"leg curl"},
+	"leg_raise":          {"leg raise"},
+	"lunge":              {"lunge"},
+	"olympic_lift":       {"clean and jerk", "snatch", "power clean"},
+	"plank":              {"plank"},
+	"plyo":               {"box jump", "jump"},
+	"pull_up":            {"pull-up", "pullup", "pull up", "chin-up"},
+	"push_up":            {"push-up", "pushup", "push up"},
+	"row":                {"bent over barbell row", "barbell row", "row"},
+	"shoulder_press":     {"overhead press", "shoulder press", "military press"},
+	"shrug":              {"shrug"},
+	"sit_up":             {"sit-up", "situp", "sit up"},
+	"squat":              {"barbell full squat", "barbell squat", "squat"},
+	"triceps_extension":  {"triceps extension", "tricep extension", "skullcrusher"},
+}
+
+type fitSetGroup struct {
+	category string
+	subtype  int
+	sets     []fit.Set
+}
+
+// mapFITSets turns consecutive active sets of the same category into one
+// entry each. Rest sets are dropped; sets without a catalog match are
+// counted in the unmapped report.
+func (s *WorkoutService) mapFITSets(sets []fit.Set) ([]models.ExerciseEntry, []models.UnmappedItem, error){
+	var groups []fitSetGroup
+	for _, set := range sets{
+		if !set.Active{
+			continue
+		}
+		if n := len(groups); n > 0 && groups[n-1].category == set.Category && groups[n-1].subtype == set.Subtype{
+			groups[n-1].sets = append(groups[n-1].sets, set)
+			continue
+		}
+		groups = append(groups, fitSetGroup{category: set.Category, subtype: set.Subtype, sets: []fit.Set{set}})
+	}
+
+	resolved := make(map[string]*models.Exercise)
+	unmappedSets := make(map[string]int)
+	var unmappedOrder []string
+	var entries []models.ExerciseEntry
+
+	for _, g := range groups{
+		exercise, seen := resolved[g.category]
+		if !seen{
+			if keywords, ok := fitCategoryExercises[g.category]; ok{
+				// cardio exercises can't hold sets × reps, so a keyword hit on one doesn't count
+				found, err := s.WorkoutRepo.FindExerciseByKeywords(keywords, "")
+				if err == nil && found.Type != models.ExerciseTypeCardio{
+					exercise = found
+				}
+			}
+			resolved[g.category] = exercise
+		}
+		if exercise == nil{
+			if _, ok := unmappedSets[g.category]; !ok{
+				unmappedOrder = append(unmappedOrder, g.category)
+			}
+			unmappedSets[g.category] += len(g.sets)
+			continue
+		}
+
+		entry := models.ExerciseEntry{ExerciseID: exercise.ID, Sets: len(g.sets)}
+		hasWeight := false
+		for _, set := range g.sets{
+			if set.Weight != nil{
+				hasWeight = true
+			}
+		}
+		for _, set := range g.sets{
+			entry.Reps = append(entry.Reps, int64(set.Reps))
+			if hasWeight{
+				weight := 0.0
+				if set.Weight != nil{
+					weight = *set.Weight
+				}
+				entry.Weight = append(entry.Weight, weight)
+			}
+		}
+		if entry.Weight == nil{
+			entry.Weight = pq.Float64Array{}
+		}
+
+		if err := validateExerciseEntry(exercise.Name, entry, exercise.Type); err != nil{
+			return nil, nil, fmt.Errorf("FIT %s sets: %w", g.category, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	unmapped := make([]models.UnmappedItem, 0, len(unmappedOrder))
+	for _, category := range unmappedOrder{
+		unmapped = append(unmapped, models.UnmappedItem{Category: category, Sets: unmappedSets[category]})
+	}
+	return entries, unmapped, nil
+}
+
+// fitSetsDuration spans from the first set start to the end of the last set.
+func fitSetsDuration(sets []fit.Set) time.Duration{
+	first, last := sets[0], sets[len(sets)-1]
+	if first.Start.IsZero() || last.Start.IsZero(){
+		return 0
+	}
+	end := last.Start.Add(time.Duration(last.DurationSeconds * float64(time.Second)))
+	return end.Sub(first.Start)
+}
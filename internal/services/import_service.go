@@ -0,0 +1,327 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/historyimport"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+	"github.com/lib/pq"
+)
+
+const reasonNoMatch = "no catalog exercise matches this name"
+
+type ImportService struct {
+	ImportRepo *repositories.ImportRepository
+	Workouts   *WorkoutService
+}
+
+func NewImportService(importRepo *repositories.ImportRepository, workouts *WorkoutService) *ImportService {
+	return &ImportService{ImportRepo: importRepo, Workouts: workouts}
+}
+
+// DuplicateHistoryImportError is returned when the same export was already imported.
+type DuplicateHistoryImportError struct {
+	JobID int
+}
+
+func (e *DuplicateHistoryImportError) Error() string{
+	return fmt.Sprintf("this file was already imported by import %d", e.JobID)
+}
+
+// StartImport parses the export right away so format errors are reported to
+// the caller, then creates the workouts in the background. Progress is
+// tracked on the returned job.
+func (s *ImportService) StartImport(userID int, data []byte, dryRun bool, unit units.System) (*models.ImportJob, error){
+	const op = "internal.servises.StartImport"
+
+	hash := contentHash(data)
+	if !dryRun{
+		if jobID, ok, err := s.ImportRepo.FindImportedFile(userID, hash); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		} else if ok{
+			return nil, &DuplicateHistoryImportError{JobID: jobID}
+		}
+	}
+
+	source, workouts, err := historyimport.Parse(bytes.NewReader(data), unit)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(workouts) == 0{
+		return nil, fmt.Errorf("%s: the file contains no workouts", op)
+	}
+
+	job := models.ImportJob{
+		UserID: userID,
+		Source: source,
+		Status: models.ImportStatusPending,
+		DryRun: dryRun,
+		ContentHash: hash,
+		TotalWorkouts: len(workouts),
+		Unmapped: models.ImportUnmappedList{},
+	}
+	job.ID, err = s.ImportRepo.CreateJob(job)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	go s.run(job, workouts)
+
+	return &job, nil
+}
+
+func (s *ImportService) GetJob(jobID int, userID int) (*models.ImportJob, error){
+	const op = "internal.servises.GetJob"
+
+	job, err := s.ImportRepo.GetJob(jobID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return job, nil
+}
+
+func (s *ImportService) run(job models.ImportJob, workouts []historyimport.Workout){
+	defer func(){
+		if r := recover(); r != nil{
+			job.Status = models.ImportStatusFailed
+			job.Error = fmt.Sprint("import crashed: ", r)
+			job.CreatedWorkouts = 0
+			if err := s.ImportRepo.FinishJob(job, nil); err != nil{
+				log.Printf("warning: failed to record failure of import %d: %v", job.ID, err)
+			}
+		}
+	}()
+
+	job.Status = models.ImportStatusRunning
+	if err := s.ImportRepo.UpdateProgress(job); err != nil{
+		log.Printf("warning: failed to start import %d: %v", job.ID, err)
+	}
+
+	created, err := s.process(&job, workouts)
+	if err == nil{
+		job.Status = models.ImportStatusCompleted
+		if err = s.ImportRepo.FinishJob(job, created); err == nil{
+			return
+		}
+	}
+
+	job.Status = models.ImportStatusFailed
+	job.Error = err.Error()
+	job.CreatedWorkouts = 0
+	if err := s.ImportRepo.FinishJob(job, nil); err != nil{
+		log.Printf("warning: failed to finish import %d: %v", job.ID, err)
+	}
+}
+
+// process maps the exported workouts to catalog exercises and returns the
+// workouts to create. Nothing is saved here: the workouts are written
+// together with the job's final status.
+func (s *ImportService) process(job *models.ImportJob, workouts []historyimport.Workout) ([]models.Workout, error){
+	exercises, err := s.resolveNames(job.UserID, workouts)
+	if err != nil{
+		return nil, err
+	}
+
+	unmapped := make(map[string]*models.ImportUnmapped)
+	var unmappedOrder []string
+	skip := func(name string, sets int, reason string){
+		u, ok := unmapped[name]
+		if !ok{
+			u = &models.ImportUnmapped{Name: name, Reason: reason}
+			unmapped[name] = u
+			unmappedOrder = append(unmappedOrder, name)
+		}
+		u.Sets += sets
+	}
+	defer func(){
+		job.Unmapped = make(models.ImportUnmappedList, 0, len(unmappedOrder))
+		for _, name := range unmappedOrder{
+			job.Unmapped = append(job.Unmapped, *unmapped[name])
+		}
+	}()
+
+	var created []models.Workout
+	for _, w := range workouts{
+		workout := models.Workout{UserID: job.UserID, Date: w.Start, Duration: w.Duration}
+		preview := models.ImportPreviewWorkout{Title: w.Title, Date: w.Start, Duration: w.Duration.String()}
+
+		for _, ex := range w.Exercises{
+			exercise, ok := exercises[strings.ToLower(ex.Name)]
+			if !ok{
+				skip(ex.Name, len(ex.Sets), reasonNoMatch)
+				preview.Exercises = append(preview.Exercises, models.ImportPreviewExercise{Name: ex.Name, Sets: len(ex.Sets)})
+				continue
+			}
+			entry := importedEntry(exercise, ex.Sets)
+			if err := validateExerciseEntry(exercise.Name, entry, exercise.Type); err != nil{
+				skip(ex.Name, len(ex.Sets), err.Error())
+				continue
+			}
+			workout.Exercises = append(workout.Exercises, entry)
+			preview.Exercises = append(preview.Exercises, models.ImportPreviewExercise{Name: ex.Name, MappedTo: exercise.Name, Sets: len(ex.Sets)})
+		}
+
+		job.ProcessedWorkouts++
+		if len(workout.Exercises) == 0{
+			job.SkippedWorkouts++
+		} else if job.DryRun{
+			job.CreatedWorkouts++
+		} else{
+			if err := s.Workouts.ApplyCalories(&workout, nil); err != nil{
+				return nil, err
+			}
+			created = append(created, workout)
+			job.CreatedWorkouts++
+		}
+		if job.DryRun{
+			job.Preview = append(job.Preview, preview)
+		}
+
+		if err := s.ImportRepo.UpdateProgress(*job); err != nil{
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// resolveNames maps export names to catalog exercises: the user's own
+// mappings first, then a case-insensitive match on the catalog name.
+func (s *ImportService) resolveNames(userID int, workouts []historyimport.Workout) (map[string]models.Exercise, error){
+	nameSet := make(map[string]string)
+	for _, w := range workouts{
+		for _, ex := range w.Exercises{
+			nameSet[strings.ToLower(ex.Name)] = ex.Name
+		}
+	}
+	names := make([]string, 0, len(nameSet))
+	for _, name := range nameSet{
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make(map[string]models.Exercise, len(names))
+
+	catalog, err := s.ImportRepo.GetExercisesByLowerNames(names)
+	if err != nil{
+		return nil, err
+	}
+	for _, ex := range catalog{
+		result[strings.ToLower(ex.Name)] = ex
+	}
+
+	mappings, err := s.ImportRepo.GetMappings(userID)
+	if err != nil{
+		return nil, err
+	}
+	ids := make([]int, 0, len(mappings))
+	for _, m := range mappings{
+		ids = append(ids, m.ExerciseID)
+	}
+	mapped, err := s.Workouts.WorkoutRepo.GetExercisesByID(ids)
+	if err != nil{
+		return nil, err
+	}
+	byID := make(map[int]models.Exercise, len(mapped))
+	for _, ex := range mapped{
+		byID[ex.ID] = ex
+	}
+	for _, m := range mappings{
+		if ex, ok := byID[m.ExerciseID]; ok{
+			result[strings.ToLower(m.SourceName)] = ex
+		}
+	}
+
+	return result, nil
+}
+
+// importedEntry sums distance and time for cardio exercises and keeps one
+// reps/weight value per set for everything else.
+func importedEntry(exercise models.Exercise, sets []historyimport.Set) models.ExerciseEntry{
+	entry := models.ExerciseEntry{ExerciseID: exercise.ID, Reps: pq.Int64Array{}, Weight: pq.Float64Array{}}
+
+	if exercise.Type == models.ExerciseTypeCardio{
+		var meters float64
+		var seconds int
+		for _, set := range sets{
+			if set.Distance != nil{
+				meters += *set.Distance
+			}
+			if set.Seconds != nil{
+				seconds += *set.Seconds
+			}
+		}
+		if meters > 0{
+			entry.Distance = &meters
+		}
+		if seconds > 0{
+			entry.DurationSeconds = &seconds
+		}
+		return entry
+	}
+
+	hasWeight := false
+	for _, set := range sets{
+		if set.Weight != nil{
+			hasWeight = true
+		}
+	}
+	entry.Sets = len(sets)
+	for _, set := range sets{
+		entry.Reps = append(entry.Reps, int64(set.Reps))
+		if hasWeight{
+			weight := 0.0
+			if set.Weight != nil{
+				weight = *set.Weight
+			}
+			entry.Weight = append(entry.Weight, weight)
+		}
+	}
+	return entry
+}
+
+func (s *ImportService) GetMappings(userID int) ([]models.ExerciseNameMapping, error){
+	const op = "internal.servises.GetMappings"
+
+	mappings, err := s.ImportRepo.GetMappings(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return mappings, nil
+}
+
+func (s *ImportService) SaveMapping(userID int, req models.RequestExerciseNameMapping) error{
+	const op = "internal.servises.SaveMapping"
+
+	sourceName := strings.TrimSpace(req.SourceName)
+	if sourceName == ""{
+		return fmt.Errorf("%s: source_name is required", op)
+	}
+
+	found, err := s.ImportRepo.GetExercisesByLowerNames([]string{strings.TrimSpace(req.Exercise)})
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(found) == 0{
+		return fmt.Errorf("%s: exercise %q not found", op, req.Exercise)
+	}
+
+	if err := s.ImportRepo.SaveMapping(userID, sourceName, found[0].ID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *ImportService) DeleteMapping(userID int, sourceName string) error{
+	const op = "internal.servises.DeleteMapping"
+
+	if err := s.ImportRepo.DeleteMapping(userID, sourceName); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
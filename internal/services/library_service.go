@@ -0,0 +1,266 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/auth"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const (
+	maxProgramTags  = 10
+	shareTokenBytes = 16
+	maxLibraryLimit = 100
+)
+
+var programTagRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+type LibraryService struct {
+	LibraryRepo *repositories.LibraryRepository
+	ProgramRepo *repositories.ProgramRepository
+	Programs    *ProgramService
+}
+
+func NewLibraryService(repo *repositories.LibraryRepository, programRepo *repositories.ProgramRepository, programs *ProgramService) *LibraryService{
+	return &LibraryService{LibraryRepo: repo, ProgramRepo: programRepo, Programs: programs}
+}
+
+func programShareURL(token string) string{
+	return fmt.Sprintf("%s/library/shared/%s", appBaseURL(), token)
+}
+
+// normalizeTags lower-cases and de-duplicates tags such as "strength" or
+// "hypertrophy", keeping their order.
+func normalizeTags(tags []string) ([]string, error){
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags{
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag]{
+			continue
+		}
+		if !programTagRe.MatchString(tag){
+			return nil, fmt.Errorf("invalid tag %q: use up to 32 letters, digits and dashes", tag)
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	if len(result) > maxProgramTags{
+		return nil, fmt.Errorf("a program can have at most %d tags", maxProgramTags)
+	}
+	return result, nil
+}
+
+// SetSharing changes the visibility and tags of one of the user's programs.
+// Making a program unlisted or public creates its share link; making it
+// private revokes the link.
+func (s *LibraryService) SetSharing(userID int, programID int, req models.RequestProgramSharing) (*models.ResponseProgramSharing, error){
+	const op = "internal.servises.SetSharing"
+
+	program, err := s.ProgramRepo.GetProgramByID(programID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	visibility := program.Visibility
+	if req.Visibility != nil{
+		visibility = *req.Visibility
+	}
+	switch visibility{
+	case models.ProgramVisibilityPrivate, models.ProgramVisibilityUnlisted, models.ProgramVisibilityPublic:
+	default:
+		return nil, fmt.Errorf("%s: visibility must be private, unlisted or public", op)
+	}
+
+	tags := []string(program.Tags)
+	if req.Tags != nil{
+		tags, err = normalizeTags(*req.Tags)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	shareToken := program.ShareToken
+	if visibility == models.ProgramVisibilityPrivate{
+		shareToken = nil
+	} else if shareToken == nil{
+		token, err := auth.GenerateRandomToken(shareTokenBytes)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		shareToken = &token
+	}
+
+	if err := s.ProgramRepo.UpdateSharing(programID, userID, visibility, shareToken, tags); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp := &models.ResponseProgramSharing{Visibility: visibility, Tags: tags}
+	if resp.Tags == nil{
+		resp.Tags = []string{}
+	}
+	if shareToken != nil{
+		resp.ShareURL = programShareURL(*shareToken)
+	}
+	return resp, nil
+}
+
+func (s *LibraryService) ListPublicPrograms(search string, tags []string, limit int, offset int) ([]models.LibraryProgramSummary, error){
+	const op = "internal.servises.ListPublicPrograms"
+
+	tags, err := normalizeTags(tags)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if limit < 1 || limit > maxLibraryLimit{
+		return nil, fmt.Errorf("%s: limit must be between 1 and %d", op, maxLibraryLimit)
+	}
+	if offset < 0{
+		return nil, fmt.Errorf("%s: offset must not be negative", op)
+	}
+
+	programs, err := s.LibraryRepo.ListPublicPrograms(strings.TrimSpace(search), tags, limit, offset)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return programs, nil
+}
+
+// GetPublicProgram returns a public program; viewerID may be 0 for visitors.
+func (s *LibraryService) GetPublicProgram(viewerID int, programID int, unit units.System) (*models.ResponseLibraryProgram, error){
+	const op = "internal.servises.GetPublicProgram"
+
+	program, err := s.LibraryRepo.GetPublicProgram(programID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	resp, err := s.buildLibraryProgram(*program, viewerID, unit)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp, nil
+}
+
+// GetSharedProgram returns an unlisted or public program by its share link.
+func (s *LibraryService) GetSharedProgram(viewerID int, token string, unit units.System) (*models.ResponseLibraryProgram, error){
+	const op = "internal.servises.GetSharedProgram"
+
+	program, err := s.LibraryRepo.GetProgramByShareToken(token)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	resp, err := s.buildLibraryProgram(*program, viewerID, unit)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return resp, nil
+}
+
+func (s *LibraryService) buildLibraryProgram(programDB models.LibraryProgramDB, viewerID int, unit units.System) (*models.ResponseLibraryProgram, error){
+	phases, err := s.ProgramRepo.GetProgramStructure(programDB.ID)
+	if err != nil{
+		return nil, err
+	}
+	program := models.Program{
+		ID: programDB.ID,
+		UserID: programDB.UserID,
+		Name: programDB.Name,
+		Phases: phases,
+		CreatedAt: programDB.CreatedAt,
+		Visibility: programDB.Visibility,
+		Tags: programDB.Tags,
+		ForkedFromID: programDB.ForkedFromID,
+		ForkedFromName: programDB.ForkedFromName,
+		ForkedFromAuthor: programDB.ForkedFromAuthor,
+	}
+	resp, err := s.Programs.BuildResponseProgram(program, viewerID, unit)
+	if err != nil{
+		return nil, err
+	}
+	return &models.ResponseLibraryProgram{
+		RequestGetProgram: *resp,
+		Author: programDB.Author,
+		FollowersCount: programDB.FollowersCount,
+		ForksCount: programDB.ForksCount,
+	}, nil
+}
+
+// ForkProgram copies a public program, or an unlisted one when the share
+// token is given, into the user's account as a private program that keeps
+// the name and author of the original.
+func (s *LibraryService) ForkProgram(userID int, programID int, req models.RequestForkProgram) (int, error){
+	const op = "internal.servises.ForkProgram"
+
+	var source *models.LibraryProgramDB
+	var err error
+	if req.ShareToken != ""{
+		source, err = s.LibraryRepo.GetProgramByShareToken(req.ShareToken)
+		if err == nil && source.ID != programID{
+			return 0, fmt.Errorf("%s: share token does not belong to program %d", op, programID)
+		}
+	} else{
+		source, err = s.LibraryRepo.GetPublicProgram(programID)
+	}
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	phases, err := s.ProgramRepo.GetProgramStructure(source.ID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == ""{
+		name = source.Name
+	}
+	fork := models.Program{
+		UserID: userID,
+		Name: name,
+		Phases: phases,
+		ForkedFromID: &source.ID,
+		ForkedFromName: &source.Name,
+		ForkedFromAuthor: &source.Author,
+	}
+
+	id, err := s.ProgramRepo.SaveProgram(fork)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// Follow subscribes the user to a public program.
+func (s *LibraryService) Follow(userID int, programID int) (*models.ResponseProgramFollowers, error){
+	const op = "internal.servises.Follow"
+
+	if _, err := s.LibraryRepo.GetPublicProgram(programID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.LibraryRepo.Follow(programID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	followers, err := s.LibraryRepo.GetFollowers(programID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return followers, nil
+}
+
+// Unfollow works even after the program stopped being public.
+func (s *LibraryService) Unfollow(userID int, programID int) (*models.ResponseProgramFollowers, error){
+	const op = "internal.servises.Unfollow"
+
+	if err := s.LibraryRepo.Unfollow(programID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	followers, err := s.LibraryRepo.GetFollowers(programID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return followers, nil
+}
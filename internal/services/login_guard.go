@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/ratelimit"
+)
+
+type LoginGuardConfig struct {
+	// Failures allowed for an account before every further failure adds an
+	// exponentially growing back-off.
+	BackoffAfter int64
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Failures within Window after which the account is locked for LockoutDuration.
+	LockoutAfter    int64
+	LockoutDuration time.Duration
+	// Failures from a single IP within Window after which the IP is blocked.
+	IPLimit     int64
+	IPBlockTime time.Duration
+	Window      time.Duration
+}
+
+func DefaultLoginGuardConfig() LoginGuardConfig {
+	return LoginGuardConfig{
+		BackoffAfter:    3,
+		BaseBackoff:     time.Second,
+		MaxBackoff:      5 * time.Minute,
+		LockoutAfter:    10,
+		LockoutDuration: 15 * time.Minute,
+		IPLimit:         50,
+		IPBlockTime:     15 * time.Minute,
+		Window:          15 * time.Minute,
+	}
+}
+
+// LoginBlockedError is returned when a login attempt is refused before the
+// password is checked. Locked distinguishes an account lockout from throttling.
+type LoginBlockedError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string{
+	if e.Locked{
+		return fmt.Sprintf("account is temporarily locked, retry after %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+type LoginGuard struct {
+	Store     ratelimit.Store
+	AuditRepo *repositories.AuditRepository
+	Config    LoginGuardConfig
+}
+
+func NewLoginGuard(store ratelimit.Store, auditRepo *repositories.AuditRepository, config LoginGuardConfig) *LoginGuard {
+	return &LoginGuard{Store: store, AuditRepo: auditRepo, Config: config}
+}
+
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error{
+	const op = "services.login_guard.Check"
+
+	if d, err := g.Store.BlockedFor(ctx, lockKey(email)); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	} else if d > 0{
+		return &LoginBlockedError{Locked: true, RetryAfter: d}
+	}
+
+	if d, err := g.Store.BlockedFor(ctx, ipKey(ip)); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	} else if d > 0{
+		return &LoginBlockedError{RetryAfter: d}
+	}
+
+	if d, err := g.Store.BlockedFor(ctx, accountKey(email)); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	} else if d > 0{
+		return &LoginBlockedError{RetryAfter: d}
+	}
+
+	return nil
+}
+
+// RegisterFailure counts a failed attempt and applies back-off, lockout and IP
+// blocking. userID is nil when the email does not belong to an account.
+func (g *LoginGuard) RegisterFailure(ctx context.Context, email, ip string, userID *int) error{
+	const op = "services.login_guard.RegisterFailure"
+	cfg := g.Config
+
+	ipFailures, err := g.Store.Increment(ctx, ipKey(ip), cfg.Window)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if ipFailures == cfg.IPLimit{
+		if err := g.Store.Block(ctx, ipKey(ip), cfg.IPBlockTime); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		g.audit(models.AuditEntry{
+			Event: models.AuditLoginIPBlocked,
+			IP: ip,
+			Details: fmt.Sprintf("%d failed logins within %s, blocked for %s", ipFailures, cfg.Window, cfg.IPBlockTime),
+		})
+	}
+
+	failures, err := g.Store.Increment(ctx, accountKey(email), cfg.Window)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if failures >= cfg.LockoutAfter{
+		if err := g.Store.Block(ctx, lockKey(email), cfg.LockoutDuration); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := g.Store.Reset(ctx, accountKey(email)); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		g.audit(models.AuditEntry{
+			UserID: userID,
+			Event: models.AuditLoginAccountLocked,
+			IP: ip,
+			Details: fmt.Sprintf("%d failed logins for %s, locked for %s", failures, normalizeEmail(email), cfg.LockoutDuration),
+		})
+		return nil
+	}
+
+	if failures >= cfg.BackoffAfter{
+		if err := g.Store.Block(ctx, accountKey(email), backoff(cfg, failures)); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func (g *LoginGuard) RegisterSuccess(ctx context.Context, email string) error{
+	const op = "services.login_guard.RegisterSuccess"
+
+	if err := g.Store.Reset(ctx, accountKey(email)); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (g *LoginGuard) audit(entry models.AuditEntry){
+	if err := g.AuditRepo.SaveEntry(entry); err != nil{
+		log.Printf("warning: failed to write audit entry %s: %v", entry.Event, err)
+	}
+}
+
+func backoff(cfg LoginGuardConfig, failures int64) time.Duration{
+	d := cfg.BaseBackoff << (failures - cfg.BackoffAfter)
+	if d <= 0 || d > cfg.MaxBackoff{
+		return cfg.MaxBackoff
+	}
+	return d
+}
+
+func normalizeEmail(email string) string{
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func accountKey(email string) string{
+	return "login:account:" + normalizeEmail(email)
+}
+
+func lockKey(email string) string{
+	return "login:lock:" + normalizeEmail(email)
+}
+
+func ipKey(ip string) string{
+	return "login:ip:" + ip
+}
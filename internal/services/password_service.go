@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/auth"
+	"github.com/artembliss/go-fitness-tracker/pkg/mailer"
+	"github.com/artembliss/go-fitness-tracker/pkg/ratelimit"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	// Reset requests allowed per resetWindow from one IP and for one email.
+	resetWindow     = time.Hour
+	resetIPLimit    = 20
+	resetEmailLimit = 3
+	// resetSendTimeout bounds the background lookup and email delivery.
+	resetSendTimeout = time.Minute
+)
+
+// ResetLimitedError is returned when password resets are requested too often
+// from an IP or for an email.
+type ResetLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResetLimitedError) Error() string{
+	return fmt.Sprintf("too many password reset requests, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+type PasswordService struct {
+	UserRepo  *repositories.UserRepository
+	ResetRepo *repositories.PasswordResetRepository
+	Mailer    mailer.Mailer
+	Limiter   ratelimit.Store
+}
+
+func NewPasswordService(userRepo *repositories.UserRepository, resetRepo *repositories.PasswordResetRepository, m mailer.Mailer,
+	limiter ratelimit.Store) *PasswordService {
+	return &PasswordService{UserRepo: userRepo, ResetRepo: resetRepo, Mailer: m, Limiter: limiter}
+}
+
+// ChangePassword returns a fresh token for the caller; all other sessions are
+// revoked by the token version bump.
+func (s *PasswordService) ChangePassword(userID int, req models.RequestChangePassword) (string, error){
+	const op = "services.password_service.ChangePassword"
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil{
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if !auth.CheckPassword(req.CurrentPassword, user.PasswordHash){
+		return "", fmt.Errorf("%s: current password is incorrect", op)
+	}
+
+	tokenVersion, err := s.setPassword(user.ID, req.NewPassword)
+	if err != nil{
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.ResetRepo.InvalidateUserTokens(user.ID); err != nil{
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := auth.GenerateJWT(user.Email, tokenVersion)
+	if err != nil{
+		return "", fmt.Errorf("%s: failed to generate token: %w", op, err)
+	}
+	return token, nil
+}
+
+// RequestPasswordReset never reports whether the email exists, so callers
+// cannot use it to enumerate accounts: requests are limited per IP and per
+// email whether or not there is an account, and the lookup and the email
+// happen in the background so the response time is the same either way.
+func (s *PasswordService) RequestPasswordReset(ctx context.Context, email string, ip string) error{
+	const op = "services.password_service.RequestPasswordReset"
+
+	limits := []struct{
+		key   string
+		limit int64
+	}{
+		{"reset:ip:" + ip, resetIPLimit},
+		{"reset:email:" + normalizeEmail(email), resetEmailLimit},
+	}
+	for _, l := range limits{
+		if d, err := s.Limiter.BlockedFor(ctx, l.key); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		} else if d > 0{
+			return &ResetLimitedError{RetryAfter: d}
+		}
+	}
+	for _, l := range limits{
+		n, err := s.Limiter.Increment(ctx, l.key, resetWindow)
+		if err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if n >= l.limit{
+			if err := s.Limiter.Block(ctx, l.key, resetWindow); err != nil{
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	go s.sendPasswordReset(email)
+	return nil
+}
+
+// sendPasswordReset creates a reset token for the account of email, if
+// there is one, and mails it. Failures are only logged: the caller has
+// already been answered.
+func (s *PasswordService) sendPasswordReset(email string){
+	ctx, cancel := context.WithTimeout(context.Background(), resetSendTimeout)
+	defer cancel()
+
+	user, err := s.UserRepo.GetUserByEmail(email)
+	if err != nil{
+		log.Printf("password reset requested for unknown email")
+		return
+	}
+
+	token, err := auth.GenerateRandomToken(32)
+	if err != nil{
+		log.Printf("warning: failed to generate password reset token for user %d: %v", user.ID, err)
+		return
+	}
+
+	if err := s.ResetRepo.SaveToken(user.ID, auth.HashToken(token), time.Now().Add(passwordResetTTL)); err != nil{
+		log.Printf("warning: failed to save password reset token for user %d: %v", user.ID, err)
+		return
+	}
+
+	link := ""
+	if page := os.Getenv("PASSWORD_RESET_URL"); page != ""{
+		link = fmt.Sprintf("You can also open %s?token=%s\n\n", page, url.QueryEscape(token))
+	}
+	msg := mailer.Message{
+		To: user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the token below to reset your password. It expires in %s.\n\n%s\n\n"+
+			"%sIf you did not request a reset you can ignore this email.\n",
+			user.Name, passwordResetTTL, token, link),
+	}
+	if err := s.Mailer.Send(ctx, msg); err != nil{
+		log.Printf("warning: failed to send password reset email to user %d: %v", user.ID, err)
+	}
+}
+
+func (s *PasswordService) ResetPassword(req models.RequestResetPassword) error{
+	const op = "services.password_service.ResetPassword"
+
+	if err := auth.ValidatePassword(req.NewPassword); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := s.ResetRepo.ConsumeToken(auth.HashToken(req.Token))
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := s.setPassword(token.UserID, req.NewPassword); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.ResetRepo.InvalidateUserTokens(token.UserID); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *PasswordService) setPassword(userID int, password string) (int, error){
+	if err := auth.ValidatePassword(password); err != nil{
+		return 0, err
+	}
+
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil{
+		return 0, err
+	}
+
+	return s.UserRepo.UpdatePassword(userID, hashedPassword)
+}
+
+func appBaseURL() string{
+	if url := os.Getenv("APP_BASE_URL"); url != ""{
+		return url
+	}
+	return "http://localhost:8080"
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const (
+	defaultSessionMinutes = 60
+	minSessionMinutes     = 20
+	maxSessionMinutes     = 180
+	maxGeneratedDays      = 6
+	minDayExercises       = 3
+	bodyWeightEquipment   = "body_only"
+)
+
+// generatorDay is a training day template: the muscle groups to train, in
+// the order exercises are picked for them.
+type generatorDay struct {
+	name    string
+	muscles []string
+}
+
+var (
+	fullBodyMuscles = []string{"quadriceps", "chest", "lats", "hamstrings", "middle_back", "glutes", "triceps", "biceps", "abdominals", "calves"}
+	upperMuscles    = []string{"chest", "lats", "middle_back", "chest", "triceps", "biceps", "traps", "forearms"}
+	lowerMuscles    = []string{"quadriceps", "hamstrings", "glutes", "quadriceps", "calves", "abdominals", "adductors", "lower_back"}
+	pushMuscles     = []string{"chest", "chest", "triceps", "chest", "triceps", "abdominals"}
+	pullMuscles     = []string{"lats", "middle_back", "biceps", "lats", "traps", "biceps", "forearms"}
+	legMuscles      = []string{"quadriceps", "hamstrings", "glutes", "calves", "quadriceps", "adductors", "abductors", "abdominals"}
+)
+
+// generatorExerciseTypes are the catalog types each goal picks from.
+var generatorExerciseTypes = map[string][]string{
+	models.GeneratorGoalStrength:    {"strength", "powerlifting", "olympic_weightlifting"},
+	models.GeneratorGoalHypertrophy: {"strength", "powerlifting"},
+	models.GeneratorGoalEndurance:   {"strength", "plyometrics"},
+}
+
+// generatorMinutesPerExercise is the time one exercise takes with its sets
+// and rests, used to fit the exercise count to the session length.
+var generatorMinutesPerExercise = map[string]int{
+	models.GeneratorGoalStrength:    12,
+	models.GeneratorGoalHypertrophy: 9,
+	models.GeneratorGoalEndurance:   6,
+}
+
+type ProgramGeneratorService struct {
+	ExerciseRepo *repositories.ExerciseRepository
+	Programs     *ProgramService
+	Equipment    *EquipmentService
+}
+
+func NewProgramGeneratorService(exerciseRepo *repositories.ExerciseRepository, programs *ProgramService, equipment *EquipmentService) *ProgramGeneratorService{
+	return &ProgramGeneratorService{ExerciseRepo: exerciseRepo, Programs: programs, Equipment: equipment}
+}
+
+// GenerateProgram builds a one-week program from the exercise catalog and,
+// unless the request is a preview, saves it for the user. Without equipment
+// in the request the user's equipment profile decides what is available.
+func (s *ProgramGeneratorService) GenerateProgram(userID int, req models.RequestGenerateProgram, unit units.System) (*models.ResponseGeneratedProgram, error){
+	const op = "internal.servises.GenerateProgram"
+
+	if err := normalizeGenerateRequest(&req); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var profileName string
+	if len(req.Equipment) == 0{
+		equipment, profile, err := s.Equipment.ActiveEquipment(userID, req.ProfileID)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		req.Equipment = equipment
+		if profile != nil{
+			profileName = profile.Name
+		}
+	}
+
+	catalog, err := s.ExerciseRepo.GetAllExercises()
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkEquipment(req.Equipment, catalog); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	seed := time.Now().UnixNano()
+	if req.Seed != nil{
+		seed = *req.Seed
+	}
+	split, programReq, err := planProgram(req, catalog, rand.New(rand.NewSource(seed)))
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	program, err := s.Programs.BuildProgram(userID, programReq, unit)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	program.Visibility = models.ProgramVisibilityPrivate
+
+	resp := models.ResponseGeneratedProgram{Seed: seed, Split: split, EquipmentProfile: profileName}
+	if !req.Preview{
+		id, err := s.Programs.ProgramRepo.SaveProgram(program)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		program.ID = id
+		resp.ID = &id
+	}
+
+	programResp, err := s.Programs.BuildResponseProgram(program, userID, unit)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	resp.Program = *programResp
+	return &resp, nil
+}
+
+func normalizeGenerateRequest(req *models.RequestGenerateProgram) error{
+	req.Goal = strings.ToLower(strings.TrimSpace(req.Goal))
+	if _, ok := generatorExerciseTypes[req.Goal]; !ok{
+		return fmt.Errorf("invalid goal %q, expected strength, hypertrophy or endurance", req.Goal)
+	}
+	if req.DaysPerWeek < 1 || req.DaysPerWeek > maxGeneratedDays{
+		return fmt.Errorf("days_per_week must be between 1 and %d", maxGeneratedDays)
+	}
+	if req.SessionMinutes == 0{
+		req.SessionMinutes = defaultSessionMinutes
+	}
+	if req.SessionMinutes < minSessionMinutes || req.SessionMinutes > maxSessionMinutes{
+		return fmt.Errorf("session_minutes must be between %d and %d", minSessionMinutes, maxSessionMinutes)
+	}
+	req.Experience = strings.ToLower(strings.TrimSpace(req.Experience))
+	switch req.Experience{
+	case "":
+		req.Experience = models.ExperienceBeginner
+	case models.ExperienceBeginner, models.ExperienceIntermediate, models.ExperienceExpert:
+	default:
+		return fmt.Errorf("invalid experience %q, expected beginner, intermediate or expert", req.Experience)
+	}
+	for i, equipment := range req.Equipment{
+		req.Equipment[i] = normalizeEquipment(equipment)
+	}
+	return nil
+}
+
+// normalizeEquipment turns "Kettlebells" or "body only" into the catalog form.
+func normalizeEquipment(equipment string) string{
+	equipment = strings.ToLower(strings.TrimSpace(equipment))
+	equipment = strings.ReplaceAll(equipment, " ", "_")
+	if equipment == "" || equipment == "none"{
+		return bodyWeightEquipment
+	}
+	return equipment
+}
+
+func checkEquipment(equipment []string, catalog []models.Exercise) error{
+	known := map[string]bool{bodyWeightEquipment: true}
+	for _, exercise := range catalog{
+		known[normalizeEquipment(exercise.Equipment)] = true
+	}
+	for _, e := range equipment{
+		if !known[e]{
+			names := make([]string, 0, len(known))
+			for name := range known{
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown equipment %q, expected one of %s", e, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// generatorSplit spreads the muscle groups over the training days.
+func generatorSplit(days int, experience string) (string, []generatorDay){
+	fullBody := func(n int) []generatorDay{
+		split := make([]generatorDay, 0, n)
+		for i := 0; i < n; i++{
+			split = append(split, generatorDay{fmt.Sprintf("Full Body %c", 'A'+i), fullBodyMuscles})
+		}
+		return split
+	}
+	switch{
+	case days <= 2 || (days == 3 && experience == models.ExperienceBeginner):
+		return "full_body", fullBody(days)
+	case days == 3:
+		return "push_pull_legs", []generatorDay{{"Push", pushMuscles}, {"Pull", pullMuscles}, {"Legs", legMuscles}}
+	case days == 4:
+		return "upper_lower", []generatorDay{{"Upper A", upperMuscles}, {"Lower A", lowerMuscles},
+			{"Upper B", upperMuscles}, {"Lower B", lowerMuscles}}
+	case days == 5:
+		return "push_pull_legs_upper_lower", []generatorDay{{"Push", pushMuscles}, {"Pull", pullMuscles},
+			{"Legs", legMuscles}, {"Upper", upperMuscles}, {"Lower", lowerMuscles}}
+	}
+	return "push_pull_legs", []generatorDay{{"Push A", pushMuscles}, {"Pull A", pullMuscles}, {"Legs A", legMuscles},
+		{"Push B", pushMuscles}, {"Pull B", pullMuscles}, {"Legs B", legMuscles}}
+}
+
+// allowedDifficulties keeps exercises at or below the experience level.
+func allowedDifficulties(experience string) map[string]bool{
+	switch experience{
+	case models.ExperienceExpert:
+		return map[string]bool{models.ExperienceBeginner: true, models.ExperienceIntermediate: true, models.ExperienceExpert: true}
+	case models.ExperienceIntermediate:
+		return map[string]bool{models.ExperienceBeginner: true, models.ExperienceIntermediate: true}
+	}
+	return map[string]bool{models.ExperienceBeginner: true}
+}
+
+// exercisePool hands out catalog exercises per muscle group, preferring the
+// ones used least so far so the days of a split differ.
+type exercisePool struct {
+	byMuscle     map[string][]models.Exercise
+	difficulties map[string]bool
+	used         map[int]int
+	rng          *rand.Rand
+}
+
+func newExercisePool(req models.RequestGenerateProgram, catalog []models.Exercise, rng *rand.Rand) *exercisePool{
+	types := make(map[string]bool)
+	for _, t := range generatorExerciseTypes[req.Goal]{
+		types[t] = true
+	}
+	if req.Experience != models.ExperienceExpert{
+		delete(types, "olympic_weightlifting")
+	}
+	equipment := map[string]bool{bodyWeightEquipment: true}
+	for _, e := range req.Equipment{
+		equipment[e] = true
+	}
+
+	sorted := append([]models.Exercise(nil), catalog...)
+	sort.Slice(sorted, func(i, j int) bool{ return sorted[i].ID < sorted[j].ID })
+
+	pool := &exercisePool{
+		byMuscle: make(map[string][]models.Exercise),
+		difficulties: allowedDifficulties(req.Experience),
+		used: make(map[int]int),
+		rng: rng,
+	}
+	for _, exercise := range sorted{
+		if !types[strings.ToLower(exercise.Type)]{
+			continue
+		}
+		if len(req.Equipment) > 0 && !equipment[normalizeEquipment(exercise.Equipment)]{
+			continue
+		}
+		muscle := strings.ToLower(exercise.MuscleGroup)
+		pool.byMuscle[muscle] = append(pool.byMuscle[muscle], exercise)
+	}
+	return pool
+}
+
+// pick chooses an exercise for muscle that is not yet in the day. Exercises
+// above the experience level are only used when nothing else fits, and main
+// lifts prefer powerlifting movements.
+func (p *exercisePool) pick(muscle string, inDay map[int]bool, main bool) (models.Exercise, bool){
+	var fitting, harder []models.Exercise
+	for _, exercise := range p.byMuscle[muscle]{
+		if inDay[exercise.ID]{
+			continue
+		}
+		if p.difficulties[strings.ToLower(exercise.Difficulty)]{
+			fitting = append(fitting, exercise)
+		} else{
+			harder = append(harder, exercise)
+		}
+	}
+	candidates := fitting
+	if len(candidates) == 0{
+		candidates = harder
+	}
+	if len(candidates) == 0{
+		return models.Exercise{}, false
+	}
+
+	if main{
+		var compound []models.Exercise
+		for _, exercise := range candidates{
+			if strings.ToLower(exercise.Type) != "strength"{
+				compound = append(compound, exercise)
+			}
+		}
+		if len(compound) > 0{
+			candidates = compound
+		}
+	}
+
+	leastUsed := p.used[candidates[0].ID]
+	for _, exercise := range candidates{
+		leastUsed = min(leastUsed, p.used[exercise.ID])
+	}
+	var fresh []models.Exercise
+	for _, exercise := range candidates{
+		if p.used[exercise.ID] == leastUsed{
+			fresh = append(fresh, exercise)
+		}
+	}
+
+	chosen := fresh[p.rng.Intn(len(fresh))]
+	p.used[chosen.ID]++
+	return chosen, true
+}
+
+// planProgram turns the request into a program request with one phase and
+// one week. All randomness comes from rng, so a seed reproduces the program.
+func planProgram(req models.RequestGenerateProgram, catalog []models.Exercise, rng *rand.Rand) (string, models.RequestCreateProgram, error){
+	split, templates := generatorSplit(req.DaysPerWeek, req.Experience)
+	pool := newExercisePool(req, catalog, rng)
+	perDay := max(req.SessionMinutes / generatorMinutesPerExercise[req.Goal], minDayExercises)
+
+	week := models.RequestProgramWeek{Name: "Week 1"}
+	for _, template := range templates{
+		day := models.RequestProgramDay{Name: template.name}
+		inDay := make(map[int]bool)
+		for _, muscle := range template.muscles{
+			if len(day.Exercises) == perDay{
+				break
+			}
+			main := len(day.Exercises) == 0 || (req.Goal == models.GeneratorGoalStrength && len(day.Exercises) == 1)
+			exercise, ok := pool.pick(muscle, inDay, main)
+			if !ok{
+				continue
+			}
+			inDay[exercise.ID] = true
+			day.Exercises = append(day.Exercises, generatorSlot(exercise.Name, req.Goal, req.Experience, main))
+		}
+		if len(day.Exercises) < minDayExercises{
+			return "", models.RequestCreateProgram{}, fmt.Errorf("the catalog has too few exercises for %s with this equipment", template.name)
+		}
+		if req.Goal == models.GeneratorGoalEndurance{
+			day.Groups = []models.ExerciseGroup{generatorCircuit(req.Experience)}
+			for i := range day.Exercises{
+				day.Exercises[i].Group = "A"
+			}
+		}
+		week.Days = append(week.Days, day)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == ""{
+		name = fmt.Sprintf("%s %s (%d days)", strings.ToUpper(req.Goal[:1]) + req.Goal[1:],
+			strings.ReplaceAll(split, "_", " "), req.DaysPerWeek)
+	}
+	return split, models.RequestCreateProgram{
+		Name: name,
+		Phases: []models.RequestProgramPhase{{Name: "Base", Weeks: []models.RequestProgramWeek{week}}},
+	}, nil
+}
+
+// generatorSlot sets the scheme of an exercise: heavy low-rep sets for
+// strength, moderate rep ranges for hypertrophy and single high-rep sets per
+// circuit round for endurance.
+func generatorSlot(name string, goal string, experience string, main bool) models.RequestProgramSlot{
+	slot := models.RequestProgramSlot{Name: name}
+	intPtr := func(v int) *int{ return &v }
+
+	switch goal{
+	case models.GeneratorGoalStrength:
+		slot.Sets, slot.Reps, slot.RestSeconds = 3, 8, intPtr(120)
+		if main{
+			slot.Sets, slot.Reps, slot.RestSeconds = 3, 5, intPtr(180)
+			switch experience{
+			case models.ExperienceIntermediate:
+				percent := 80.0
+				slot.Sets, slot.PercentE1RM = 5, &percent
+			case models.ExperienceExpert:
+				percent := 85.0
+				slot.Sets, slot.Reps, slot.PercentE1RM = 5, 3, &percent
+			}
+		}
+	case models.GeneratorGoalHypertrophy:
+		slot.Sets, slot.Reps, slot.RepsMax, slot.RestSeconds = 3, 8, intPtr(12), intPtr(90)
+		if experience != models.ExperienceBeginner{
+			if main{
+				slot.Sets, slot.Reps, slot.RepsMax = 4, 6, intPtr(10)
+			} else{
+				slot.Reps, slot.RepsMax, slot.RestSeconds = 10, intPtr(15), intPtr(60)
+			}
+		}
+	default:
+		slot.Sets, slot.Reps, slot.RepsMax, slot.RestSeconds = 1, 15, intPtr(20), intPtr(0)
+	}
+	return slot
+}
+
+// generatorCircuit is the circuit an endurance day is done as.
+func generatorCircuit(experience string) models.ExerciseGroup{
+	rounds, rest := 3, 90
+	switch experience{
+	case models.ExperienceIntermediate:
+		rounds, rest = 4, 75
+	case models.ExperienceExpert:
+		rounds, rest = 5, 60
+	}
+	return models.ExerciseGroup{Label: "A", Type: models.ExerciseGroupCircuit, Rounds: &rounds, RestSeconds: &rest}
+}
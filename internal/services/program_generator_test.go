@@ -0,0 +1,219 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+// testCatalog has, for every muscle the splits use, a barbell powerlifting
+// lift and strength exercises with a barbell, dumbbells, a cable and body
+// weight, at increasing difficulty.
+func testCatalog() []models.Exercise{
+	muscles := []string{"quadriceps", "chest", "lats", "hamstrings", "middle_back", "glutes", "triceps", "biceps",
+		"abdominals", "calves", "traps", "forearms", "adductors", "abductors", "lower_back"}
+	variants := []struct{
+		kind       string
+		equipment  string
+		difficulty string
+	}{
+		{"powerlifting", "barbell", models.ExperienceIntermediate},
+		{"strength", "barbell", models.ExperienceBeginner},
+		{"strength", "dumbbell", models.ExperienceBeginner},
+		{"strength", "dumbbell", models.ExperienceIntermediate},
+		{"strength", "cable", models.ExperienceBeginner},
+		{"strength", "body only", models.ExperienceBeginner},
+		{"plyometrics", "body only", models.ExperienceBeginner},
+	}
+
+	var catalog []models.Exercise
+	for _, muscle := range muscles{
+		for _, v := range variants{
+			catalog = append(catalog, models.Exercise{
+				ID: len(catalog) + 1,
+				Name: fmt.Sprintf("%s %s %s %d", muscle, v.kind, v.equipment, len(catalog) + 1),
+				Type: v.kind,
+				MuscleGroup: muscle,
+				Equipment: v.equipment,
+				Difficulty: v.difficulty,
+			})
+		}
+	}
+	return catalog
+}
+
+func planTestProgram(t *testing.T, req models.RequestGenerateProgram, catalog []models.Exercise, seed int64) (string, models.RequestCreateProgram){
+	t.Helper()
+	if err := normalizeGenerateRequest(&req); err != nil{
+		t.Fatalf("normalizeGenerateRequest() error = %v", err)
+	}
+	split, program, err := planProgram(req, catalog, rand.New(rand.NewSource(seed)))
+	if err != nil{
+		t.Fatalf("planProgram() error = %v", err)
+	}
+	return split, program
+}
+
+func TestPlanProgramSeed(t *testing.T){
+	catalog := testCatalog()
+	req := models.RequestGenerateProgram{Goal: models.GeneratorGoalHypertrophy, DaysPerWeek: 4, Experience: models.ExperienceIntermediate}
+
+	_, first := planTestProgram(t, req, catalog, 42)
+	_, again := planTestProgram(t, req, catalog, 42)
+	if !reflect.DeepEqual(first, again){
+		t.Fatal("the same seed produced different programs")
+	}
+
+	// the catalog order must not matter either
+	reversed := make([]models.Exercise, len(catalog))
+	for i, exercise := range catalog{
+		reversed[len(catalog)-1-i] = exercise
+	}
+	if _, shuffled := planTestProgram(t, req, reversed, 42); !reflect.DeepEqual(first, shuffled){
+		t.Error("the same seed produced a different program from a reordered catalog")
+	}
+
+	for seed := int64(43); seed < 53; seed++{
+		if _, other := planTestProgram(t, req, catalog, seed); !reflect.DeepEqual(first, other){
+			return
+		}
+	}
+	t.Error("ten other seeds all produced the program of seed 42")
+}
+
+func TestPlanProgramSplit(t *testing.T){
+	tests := []struct{
+		days       int
+		experience string
+		wantSplit  string
+		wantDays   []string
+	}{
+		{1, models.ExperienceBeginner, "full_body", []string{"Full Body A"}},
+		{2, models.ExperienceExpert, "full_body", []string{"Full Body A", "Full Body B"}},
+		{3, models.ExperienceBeginner, "full_body", []string{"Full Body A", "Full Body B", "Full Body C"}},
+		{3, models.ExperienceIntermediate, "push_pull_legs", []string{"Push", "Pull", "Legs"}},
+		{4, models.ExperienceBeginner, "upper_lower", []string{"Upper A", "Lower A", "Upper B", "Lower B"}},
+		{5, models.ExperienceIntermediate, "push_pull_legs_upper_lower", []string{"Push", "Pull", "Legs", "Upper", "Lower"}},
+		{6, models.ExperienceExpert, "push_pull_legs", []string{"Push A", "Pull A", "Legs A", "Push B", "Pull B", "Legs B"}},
+	}
+	catalog := testCatalog()
+	for _, tt := range tests{
+		t.Run(fmt.Sprintf("%d days %s", tt.days, tt.experience), func(t *testing.T){
+			req := models.RequestGenerateProgram{Goal: models.GeneratorGoalStrength, DaysPerWeek: tt.days, Experience: tt.experience}
+			split, program := planTestProgram(t, req, catalog, 1)
+			if split != tt.wantSplit{
+				t.Errorf("split = %q, want %q", split, tt.wantSplit)
+			}
+
+			days := program.Phases[0].Weeks[0].Days
+			var names []string
+			for _, day := range days{
+				names = append(names, day.Name)
+				seen := make(map[string]bool)
+				for _, slot := range day.Exercises{
+					if seen[slot.Name]{
+						t.Errorf("%s has %q twice", day.Name, slot.Name)
+					}
+					seen[slot.Name] = true
+				}
+				// 60 minutes at 12 minutes an exercise
+				if len(day.Exercises) != 5{
+					t.Errorf("%s has %d exercises, want 5", day.Name, len(day.Exercises))
+				}
+			}
+			if !reflect.DeepEqual(names, tt.wantDays){
+				t.Errorf("days = %v, want %v", names, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestPlanProgramEquipment(t *testing.T){
+	catalog := testCatalog()
+	byName := make(map[string]models.Exercise, len(catalog))
+	for _, exercise := range catalog{
+		byName[exercise.Name] = exercise
+	}
+
+	tests := []struct{
+		name      string
+		equipment []string
+		allowed   map[string]bool
+	}{
+		{"dumbbells only", []string{"Dumbbell"}, map[string]bool{"dumbbell": true, "body_only": true}},
+		{"body weight only", []string{"none"}, map[string]bool{"body_only": true}},
+		{"barbell and cable", []string{"barbell", "cable"}, map[string]bool{"barbell": true, "cable": true, "body_only": true}},
+	}
+	for _, tt := range tests{
+		t.Run(tt.name, func(t *testing.T){
+			req := models.RequestGenerateProgram{Goal: models.GeneratorGoalHypertrophy, DaysPerWeek: 3, Experience: models.ExperienceExpert,
+				Equipment: tt.equipment}
+			_, program := planTestProgram(t, req, catalog, 7)
+			for _, day := range program.Phases[0].Weeks[0].Days{
+				for _, slot := range day.Exercises{
+					if equipment := normalizeEquipment(byName[slot.Name].Equipment); !tt.allowed[equipment]{
+						t.Errorf("%s uses %q, which needs %s", day.Name, slot.Name, equipment)
+					}
+				}
+			}
+		})
+	}
+
+	// only the legs have body weight exercises, so a push day can't be filled
+	var legsOnly []models.Exercise
+	for _, exercise := range catalog{
+		if exercise.Equipment == "barbell" || exercise.MuscleGroup == "quadriceps"{
+			legsOnly = append(legsOnly, exercise)
+		}
+	}
+	req := models.RequestGenerateProgram{Goal: models.GeneratorGoalStrength, DaysPerWeek: 3, Experience: models.ExperienceExpert,
+		Equipment: []string{"none"}}
+	if err := normalizeGenerateRequest(&req); err != nil{
+		t.Fatal(err)
+	}
+	if _, _, err := planProgram(req, legsOnly, rand.New(rand.NewSource(1))); err == nil{
+		t.Error("planProgram() succeeded without enough body weight exercises, want an error")
+	}
+}
+
+func TestPlanProgramEnduranceCircuit(t *testing.T){
+	tests := []struct{
+		experience string
+		rounds     int
+		rest       int
+	}{
+		{models.ExperienceBeginner, 3, 90},
+		{models.ExperienceIntermediate, 4, 75},
+		{models.ExperienceExpert, 5, 60},
+	}
+	catalog := testCatalog()
+	for _, tt := range tests{
+		t.Run(tt.experience, func(t *testing.T){
+			req := models.RequestGenerateProgram{Goal: models.GeneratorGoalEndurance, DaysPerWeek: 2, Experience: tt.experience}
+			_, program := planTestProgram(t, req, catalog, 3)
+
+			for _, day := range program.Phases[0].Weeks[0].Days{
+				if len(day.Groups) != 1{
+					t.Fatalf("%s has %d groups, want one circuit", day.Name, len(day.Groups))
+				}
+				group := day.Groups[0]
+				if group.Label != "A" || group.Type != models.ExerciseGroupCircuit || *group.Rounds != tt.rounds || *group.RestSeconds != tt.rest{
+					t.Errorf("%s group = %+v with %d rounds and %d s rest, want circuit A with %d and %d",
+						day.Name, group, *group.Rounds, *group.RestSeconds, tt.rounds, tt.rest)
+				}
+				// 60 minutes at 6 minutes an exercise, capped by the ten full body muscles
+				if len(day.Exercises) != 10{
+					t.Errorf("%s has %d exercises, want 10", day.Name, len(day.Exercises))
+				}
+				for _, slot := range day.Exercises{
+					if slot.Group != "A" || slot.Sets != 1 || slot.Reps != 15 || slot.RepsMax == nil || *slot.RepsMax != 20{
+						t.Errorf("%s slot %+v, want one set of 15-20 in circuit A", day.Name, slot)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+const (
+	maxSlotSets    = 50
+	maxPercentE1RM = 110.0
+)
+
+// setSchemeRe matches "5x5", "3x8-12", "5x3@85%" and "5 x 3 @ 82.5%".
+var setSchemeRe = regexp.MustCompile(`^(\d+)\s*[xX×]\s*(\d+)(?:\s*-\s*(\d+))?(?:\s*@\s*(\d+(?:\.\d+)?)\s*%)?$`)
+
+// applySetScheme fills sets, reps, rep range and percentage from the scheme
+// shorthand. Fields set explicitly in the request must agree with it.
+func applySetScheme(slot *models.RequestProgramSlot) error{
+	scheme := strings.TrimSpace(slot.Scheme)
+	if scheme == ""{
+		return nil
+	}
+	m := setSchemeRe.FindStringSubmatch(scheme)
+	if m == nil{
+		return fmt.Errorf("invalid scheme %q, expected e.g. 5x5, 3x8-12 or 5x3@85%%", slot.Scheme)
+	}
+
+	sets, _ := strconv.Atoi(m[1])
+	reps, _ := strconv.Atoi(m[2])
+	if (slot.Sets != 0 && slot.Sets != sets) || (slot.Reps != 0 && slot.Reps != reps){
+		return fmt.Errorf("scheme %q contradicts sets/reps", slot.Scheme)
+	}
+	slot.Sets, slot.Reps = sets, reps
+
+	if m[3] != ""{
+		repsMax, _ := strconv.Atoi(m[3])
+		if slot.RepsMax != nil && *slot.RepsMax != repsMax{
+			return fmt.Errorf("scheme %q contradicts reps_max", slot.Scheme)
+		}
+		slot.RepsMax = &repsMax
+	}
+	if m[4] != ""{
+		percent, _ := strconv.ParseFloat(m[4], 64)
+		if slot.PercentE1RM != nil && *slot.PercentE1RM != percent{
+			return fmt.Errorf("scheme %q contradicts percent_e1rm", slot.Scheme)
+		}
+		slot.PercentE1RM = &percent
+	}
+	return nil
+}
+
+func validateProgramSlot(slot models.RequestProgramSlot) error{
+	if strings.TrimSpace(slot.Name) == ""{
+		return fmt.Errorf("exercise name is required")
+	}
+	if slot.Sets < 1 || slot.Sets > maxSlotSets{
+		return fmt.Errorf("sets must be between 1 and %d", maxSlotSets)
+	}
+	if slot.Reps < 1{
+		return fmt.Errorf("reps must be at least 1")
+	}
+	if slot.RepsMax != nil && *slot.RepsMax <= slot.Reps{
+		return fmt.Errorf("reps_max must be greater than reps")
+	}
+	if slot.Weight != nil && *slot.Weight < 0{
+		return fmt.Errorf("weight must not be negative")
+	}
+	if slot.PercentE1RM != nil{
+		if *slot.PercentE1RM <= 0 || *slot.PercentE1RM > maxPercentE1RM{
+			return fmt.Errorf("percent_e1rm must be between 0 and %.0f", maxPercentE1RM)
+		}
+		if slot.Weight != nil{
+			return fmt.Errorf("use either weight or percent_e1rm")
+		}
+	}
+	if slot.RestSeconds != nil && *slot.RestSeconds < 0{
+		return fmt.Errorf("rest_seconds must not be negative")
+	}
+	return nil
+}
+
+// formatSetScheme is the inverse of applySetScheme.
+func formatSetScheme(slot models.ProgramSlot) string{
+	scheme := fmt.Sprintf("%dx%d", slot.Sets, slot.Reps)
+	if slot.RepsMax != nil{
+		scheme += fmt.Sprintf("-%d", *slot.RepsMax)
+	}
+	if slot.PercentE1RM != nil{
+		scheme += "@" + strconv.FormatFloat(*slot.PercentE1RM, 'f', -1, 64) + "%"
+	}
+	return scheme
+}
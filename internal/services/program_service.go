@@ -2,22 +2,31 @@ package services
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
 )
 
 type ProgramService struct {
-	ProgramRepo *repositories.ProgramRepository
+	ProgramRepo     *repositories.ProgramRepository
+	ProgressionRepo *repositories.ProgressionRepository
 }
 
-func NewProgramService(repo *repositories.ProgramRepository) *ProgramService{
-	return &ProgramService{ProgramRepo: repo}
+func NewProgramService(repo *repositories.ProgramRepository, progressionRepo *repositories.ProgressionRepository) *ProgramService{
+	return &ProgramService{ProgramRepo: repo, ProgressionRepo: progressionRepo}
 }
 
-func (s *ProgramService) CreateProgram(program models.Program) (int, error){
+func (s *ProgramService) CreateProgram(userID int, req models.RequestCreateProgram, unit units.System) (int, error){
 	const op = "internal.servises.SaveProgram"
 
+	program, err := s.BuildProgram(userID, req, unit)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
 	id, err := s.ProgramRepo.SaveProgram(program)
 	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
@@ -26,14 +35,20 @@ func (s *ProgramService) CreateProgram(program models.Program) (int, error){
 	return id, nil
 }
 
-func (s *ProgramService) UpdateProgram(program models.Program, programID int) (int, error){
+func (s *ProgramService) UpdateProgram(userID int, programID int, req models.RequestCreateProgram, unit units.System) (int, error){
 	const op = "internal.servises.UpdateProgram"
 
-	if err := s.ProgramRepo.DeleteExercisesProgram(programID); err != nil{
-		return 0, fmt.Errorf("%s: %w", op, err)	
+	program, err := s.BuildProgram(userID, req, unit)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rules, err := s.ProgressionRepo.GetRules(programID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	id, err := s.ProgramRepo.UpdateProgram(program, programID)
+	id, err := s.ProgramRepo.UpdateProgram(program, programID, nil, invalidRules(rules, program))
 	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -41,13 +56,132 @@ func (s *ProgramService) UpdateProgram(program models.Program, programID int) (i
 	return id, nil
 }
 
-func (s *ProgramService) GetNameToID(exercises []models.ExerciseRequest) (map[string]int, error){
-	const op = "internal.servises.GetNameToID"
-	names := make([]string, len(exercises))
+// BuildProgram validates a request and resolves exercise names. The flat
+// exercise list becomes a single phase with one week and one day.
+func (s *ProgramService) BuildProgram(userID int, req models.RequestCreateProgram, unit units.System) (models.Program, error){
+	if strings.TrimSpace(req.Name) == ""{
+		return models.Program{}, fmt.Errorf("program name is required")
+	}
+
+	phases := req.Phases
+	switch{
+	case len(req.Exercises) > 0 && len(phases) > 0:
+		return models.Program{}, fmt.Errorf("use either exercises or phases, not both")
+	case len(req.Exercises) > 0:
+		phases = flatToPhases(req.Exercises)
+	case len(phases) == 0:
+		return models.Program{}, fmt.Errorf("a program needs at least one exercise")
+	}
+
+	var names []string
+	for i := range phases{
+		if len(phases[i].Weeks) == 0{
+			return models.Program{}, fmt.Errorf("phase %d has no weeks", i+1)
+		}
+		for j := range phases[i].Weeks{
+			if len(phases[i].Weeks[j].Days) == 0{
+				return models.Program{}, fmt.Errorf("phase %d week %d has no days", i+1, j+1)
+			}
+			for k := range phases[i].Weeks[j].Days{
+				day := &phases[i].Weeks[j].Days[k]
+				if len(day.Exercises) == 0{
+					return models.Program{}, fmt.Errorf("phase %d week %d day %d has no exercises", i+1, j+1, k+1)
+				}
+				for l := range day.Exercises{
+					slot := &day.Exercises[l]
+					if err := applySetScheme(slot); err != nil{
+						return models.Program{}, fmt.Errorf("phase %d week %d day %d exercise %d: %w", i+1, j+1, k+1, l+1, err)
+					}
+					if err := validateProgramSlot(*slot); err != nil{
+						return models.Program{}, fmt.Errorf("phase %d week %d day %d exercise %d: %w", i+1, j+1, k+1, l+1, err)
+					}
+					names = append(names, slot.Name)
+					if slot.SubstitutedFor != ""{
+						names = append(names, slot.SubstitutedFor)
+					}
+				}
+			}
+		}
+	}
 
-	for _, exercise := range exercises{
-		names = append(names, exercise.Name)
+	nameToID, err := s.GetNameToID(names)
+	if err != nil{
+		return models.Program{}, err
+	}
+	var notFound []string
+	for _, name := range names{
+		if _, ok := nameToID[name]; !ok{
+			notFound = append(notFound, name)
+		}
+	}
+	if len(notFound) > 0{
+		return models.Program{}, fmt.Errorf("exercises not found: %s", strings.Join(notFound, ", "))
+	}
+
+	program := models.Program{UserID: userID, Name: req.Name}
+	for i, phaseReq := range phases{
+		phase := models.ProgramPhase{Name: phaseReq.Name}
+		for j, weekReq := range phaseReq.Weeks{
+			week := models.ProgramWeek{Name: weekReq.Name}
+			for k, dayReq := range weekReq.Days{
+				day := models.ProgramDay{Name: dayReq.Name}
+				labels := make([]string, 0, len(dayReq.Exercises))
+				for _, slotReq := range dayReq.Exercises{
+					slot := slotToDB(slotReq, nameToID[slotReq.Name], unit)
+					if slotReq.SubstitutedFor != ""{
+						originalID := nameToID[slotReq.SubstitutedFor]
+						slot.SubstitutedForID = &originalID
+					}
+					day.Slots = append(day.Slots, slot)
+					labels = append(labels, strings.TrimSpace(slotReq.Group))
+				}
+				groups, err := buildExerciseGroups(dayReq.Groups, labels)
+				if err != nil{
+					return models.Program{}, fmt.Errorf("phase %d week %d day %d: %w", i+1, j+1, k+1, err)
+				}
+				day.Groups = groups
+				week.Days = append(week.Days, day)
+			}
+			phase.Weeks = append(phase.Weeks, week)
+		}
+		program.Phases = append(program.Phases, phase)
 	}
+	return program, nil
+}
+
+func flatToPhases(exercises []models.ExerciseRequest) []models.RequestProgramPhase{
+	day := models.RequestProgramDay{Name: "Day 1"}
+	for _, ex := range exercises{
+		slot := models.RequestProgramSlot{Name: ex.Name, Sets: ex.Sets, Reps: ex.Reps}
+		if ex.Weight != 0{
+			weight := ex.Weight
+			slot.Weight = &weight
+		}
+		day.Exercises = append(day.Exercises, slot)
+	}
+	return []models.RequestProgramPhase{{Weeks: []models.RequestProgramWeek{{Days: []models.RequestProgramDay{day}}}}}
+}
+
+func slotToDB(req models.RequestProgramSlot, exerciseID int, unit units.System) models.ProgramSlot{
+	slot := models.ProgramSlot{
+		ExerciseID: exerciseID,
+		Sets: req.Sets,
+		Reps: req.Reps,
+		RepsMax: req.RepsMax,
+		PercentE1RM: req.PercentE1RM,
+		RestSeconds: req.RestSeconds,
+		Notes: req.Notes,
+		Group: groupLabel(req.Group),
+	}
+	if req.Weight != nil{
+		weight := units.ToKilograms(*req.Weight, unit)
+		slot.Weight = &weight
+	}
+	return slot
+}
+
+func (s *ProgramService) GetNameToID(names []string) (map[string]int, error){
+	const op = "internal.servises.GetNameToID"
 
 	found, err := s.ProgramRepo.GetExercisesByNames(names)
 	if err != nil{
@@ -63,28 +197,7 @@ func (s *ProgramService) GetNameToID(exercises []models.ExerciseRequest) (map[st
 	return exerciseMap, nil
 }
 
-func (s *ProgramService) MapToDBExercises(regEx []models.ExerciseRequest, nameToDB map[string]int) ([]models.ExerciseProgramDB, []string){
-	var result []models.ExerciseProgramDB
-	var notFound []string
-
-	for _, ex := range regEx{
-		id, ok := nameToDB[ex.Name]
-        if !ok {
-            notFound = append(notFound, ex.Name)
-            continue
-        }
-		result = append(result, models.ExerciseProgramDB{
-            ExerciseID: id,
-            Sets:       ex.Sets,
-            Reps:       ex.Reps,
-            Weight:     ex.Weight,
-        })
-	}
-	
-	return result, notFound
-}
-
-func (s *ProgramService) GetProgram(programID int, userID int) (*models.RequestGetProgram, error){
+func (s *ProgramService) GetProgram(programID int, userID int, unit units.System) (*models.RequestGetProgram, error){
 	const op = "internal.servises.GetPrograms"
 
 	var program *models.RequestGetProgram
@@ -94,21 +207,19 @@ func (s *ProgramService) GetProgram(programID int, userID int) (*models.RequestG
 		return nil, fmt.Errorf("%s: failed to get programs by id: %w", op, err)
 	}
 
-	program, err = s.BuildResponseExercises(*programDB)
+	program, err = s.BuildResponseProgram(*programDB, userID, unit)
 	if err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	if programDB.ShareToken != nil && programDB.Visibility != models.ProgramVisibilityPrivate{
+		program.ShareURL = programShareURL(*programDB.ShareToken)
+	}
 
 	return program, nil
 }
 
-func (s *ProgramService) GetIdToName(exercises []models.ExerciseProgramDB) (map[int]string, error){
+func (s *ProgramService) GetIdToName(idSlice []int) (map[int]string, error){
 	const op = "internal.servises.GetIdToName"
-	idSlice := make([]int, 0, len(exercises))
-
-	for _, exercise := range exercises{
-		idSlice = append(idSlice, exercise.ExerciseID)
-	}
 
 	found, err := s.ProgramRepo.GetExercisesByID(idSlice)
 	if err != nil{
@@ -124,49 +235,303 @@ func (s *ProgramService) GetIdToName(exercises []models.ExerciseProgramDB) (map[
 	return exerciseMap, nil
 }
 
-func (s *ProgramService) MapToResponseExercises(dbEx []models.ExerciseProgramDB, idToName map[int]string) (*[]models.ExerciseRequest, []int){
-	var result []models.ExerciseRequest
-	var notFound []int
+// BuildResponseProgram converts weights to unit and works out target weights
+// for percentage-based slots from the estimated one-rep maxes of viewerID;
+// a viewerID of 0 leaves target weights out.
+func (s *ProgramService) BuildResponseProgram(programDB models.Program, viewerID int, unit units.System) (*models.RequestGetProgram, error){
+	const op = "internal.servises.BuildResponseProgram"
+
+	idSet := make(map[int]bool)
+	percentIDs := make(map[int]bool)
+	dayCount := 0
+	for _, phase := range programDB.Phases{
+		for _, week := range phase.Weeks{
+			for _, day := range week.Days{
+				dayCount++
+				for _, slot := range day.Slots{
+					idSet[slot.ExerciseID] = true
+					if slot.SubstitutedForID != nil{
+						idSet[*slot.SubstitutedForID] = true
+					}
+					if slot.PercentE1RM != nil{
+						percentIDs[slot.ExerciseID] = true
+					}
+				}
+			}
+		}
+	}
 
-	for _, ex := range dbEx{
-		name, ok := idToName[ex.ExerciseID]
-        if !ok {
-            notFound = append(notFound, ex.ExerciseID)
-            continue
-        }
-		result = append(result, models.ExerciseRequest{
-            Name: name,
-            Sets:       ex.Sets,
-            Reps:       ex.Reps,
-            Weight:     ex.Weight,
-        })
+	idToName, err := s.GetIdToName(sortedKeys(idSet))
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	e1rm := map[int]float64{}
+	if len(percentIDs) > 0 && viewerID != 0{
+		e1rm, err = s.ProgramRepo.GetEstimatedOneRepMaxes(viewerID, sortedKeys(percentIDs))
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	programsResp := models.RequestGetProgram{
+		ID: programDB.ID,
+		UserID: programDB.UserID,
+		Name: programDB.Name,
+		Phases: []models.RequestProgramPhase{},
+		Unit: unit.WeightUnit(),
+		Visibility: programDB.Visibility,
+		Tags: []string(programDB.Tags),
+		CreatedAt: programDB.CreatedAt,
+	}
+	if programsResp.Tags == nil{
+		programsResp.Tags = []string{}
+	}
+	if programDB.ForkedFromName != nil{
+		programsResp.ForkedFrom = &models.ProgramAttribution{ProgramID: programDB.ForkedFromID, Name: *programDB.ForkedFromName}
+		if programDB.ForkedFromAuthor != nil{
+			programsResp.ForkedFrom.Author = *programDB.ForkedFromAuthor
+		}
+	}
+
+	var notFound []int
+	for _, phase := range programDB.Phases{
+		phaseResp := models.RequestProgramPhase{Name: phase.Name}
+		for _, week := range phase.Weeks{
+			weekResp := models.RequestProgramWeek{Name: week.Name}
+			for _, day := range week.Days{
+				dayResp := models.RequestProgramDay{Name: day.Name, Groups: day.Groups}
+				for _, slot := range day.Slots{
+					name, ok := idToName[slot.ExerciseID]
+					if !ok{
+						notFound = append(notFound, slot.ExerciseID)
+						continue
+					}
+					slotResp := slotToResponse(slot, name, e1rm, unit)
+					if slot.SubstitutedForID != nil{
+						slotResp.SubstitutedFor = idToName[*slot.SubstitutedForID]
+					}
+					dayResp.Exercises = append(dayResp.Exercises, slotResp)
+
+					if dayCount == 1{
+						flat := models.ExerciseRequest{Name: name, Sets: slot.Sets, Reps: slot.Reps}
+						if slotResp.Weight != nil{
+							flat.Weight = *slotResp.Weight
+						}
+						programsResp.Exercises = append(programsResp.Exercises, flat)
+					}
+				}
+				weekResp.Days = append(weekResp.Days, dayResp)
+			}
+			phaseResp.Weeks = append(phaseResp.Weeks, weekResp)
+		}
+		programsResp.Phases = append(programsResp.Phases, phaseResp)
+	}
+	if len(notFound) > 0{
+		return nil, fmt.Errorf("%s: some exercises not found: %v", op, notFound)
 	}
 	
-	return &result, notFound
+	return &programsResp, nil
 }
 
-func (s *ProgramService) BuildResponseExercises(programDB models.Program) (*models.RequestGetProgram, error){
-	const op = "internal.servises.BuildResponseExercises"
+func slotToResponse(slot models.ProgramSlot, name string, e1rm map[int]float64, unit units.System) models.RequestProgramSlot{
+	resp := models.RequestProgramSlot{
+		Name: name,
+		Scheme: formatSetScheme(slot),
+		Sets: slot.Sets,
+		Reps: slot.Reps,
+		RepsMax: slot.RepsMax,
+		PercentE1RM: slot.PercentE1RM,
+		RestSeconds: slot.RestSeconds,
+		Notes: slot.Notes,
+	}
+	if slot.Group != nil{
+		resp.Group = *slot.Group
+	}
+	if slot.Weight != nil{
+		weight := units.FromKilograms(*slot.Weight, unit)
+		resp.Weight = &weight
+	}
+	if best, ok := e1rm[slot.ExerciseID]; ok && slot.PercentE1RM != nil{
+		target := units.FromKilograms(best * *slot.PercentE1RM / 100, unit)
+		resp.TargetWeight = &target
+	}
+	return resp
+}
 
-	idToName, err := s.GetIdToName(programDB.Exercises)
+func sortedKeys(set map[int]bool) []int{
+	keys := make([]int, 0, len(set))
+	for k := range set{
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func (s *ProgramService) ListPrograms(userID int, search string) ([]models.ProgramSummary, error){
+	const op = "internal.servises.ListPrograms"
+
+	programs, err := s.ProgramRepo.ListPrograms(userID, strings.TrimSpace(search))
 	if err != nil{
-	 return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	exercisesResp, notFound := s.MapToResponseExercises(programDB.Exercises, idToName)
-	if len(notFound) > 0{
-		return nil, fmt.Errorf("%s: some exercises not found: %v", op, notFound)
+	return programs, nil
+}
+
+// CloneProgram copies the structure of one of the user's programs into a
+// new program and returns its id.
+func (s *ProgramService) CloneProgram(userID int, programID int, name string) (int, error){
+	const op = "internal.servises.CloneProgram"
+
+	program, err := s.ProgramRepo.GetProgramByID(programID, userID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	programsResp := models.RequestGetProgram{
-			ID: programDB.ID,
-			UserID: programDB.UserID,
-			Name: programDB.Name,
-			Exercises: *exercisesResp,
-			CreatedAt: programDB.CreatedAt,
+	name = strings.TrimSpace(name)
+	if name == ""{
+		name = program.Name + " (copy)"
+	}
+	program.Name = name
+
+	id, err := s.ProgramRepo.SaveProgram(*program)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// SwapExercise replaces an exercise in the slots of a program, keeping their
+// set schemes, and stores the result as a new version. Each slot remembers
+// the exercise it originally had; swapping back to it clears the link.
+func (s *ProgramService) SwapExercise(userID int, programID int, req models.RequestSwapProgramExercise) (int, error){
+	const op = "internal.servises.SwapProgramExercise"
+
+	program, err := s.ProgramRepo.GetProgramByID(programID, userID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	fromID, toID, err := s.resolveSwap(req.Exercise, req.Replacement)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	swapped := 0
+	for i := range program.Phases{
+		if req.Phase != 0 && req.Phase != i+1{
+			continue
 		}
-	
-	return &programsResp, nil
+		for j := range program.Phases[i].Weeks{
+			if req.Week != 0 && req.Week != j+1{
+				continue
+			}
+			for k := range program.Phases[i].Weeks[j].Days{
+				if req.Day != 0 && req.Day != k+1{
+					continue
+				}
+				slots := program.Phases[i].Weeks[j].Days[k].Slots
+				for l := range slots{
+					if slots[l].ExerciseID != fromID{
+						continue
+					}
+					slots[l].SubstitutedForID = substitutionLink(slots[l].SubstitutedForID, fromID, toID)
+					slots[l].ExerciseID = toID
+					swapped++
+				}
+			}
+		}
+	}
+	if swapped == 0{
+		return 0, fmt.Errorf("%s: %q is not in the selected part of the program", op, req.Exercise)
+	}
+
+	// once the exercise has left the program, its rule follows the replacement
+	rules, err := s.ProgressionRepo.GetRules(programID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	var moveRules map[int]int
+	if _, remains := firstSlots(*program)[fromID]; !remains{
+		fromRule, toRule := -1, -1
+		for i, rule := range rules{
+			switch rule.ExerciseID{
+			case fromID:
+				fromRule = i
+			case toID:
+				toRule = i
+			}
+		}
+		if fromRule >= 0 && toRule >= 0{
+			return 0, fmt.Errorf("%s: %q and %q both have a progression rule; delete one of them first", op, req.Exercise, req.Replacement)
+		}
+		if fromRule >= 0{
+			moveRules = map[int]int{fromID: toID}
+			rules[fromRule].ExerciseID = toID
+		}
+	}
+
+	if _, err := s.ProgramRepo.UpdateProgram(*program, programID, moveRules, invalidRules(rules, *program)); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return swapped, nil
+}
+
+// invalidRules lists the exercises whose progression rules no longer fit
+// the program, e.g. a double progression whose slot lost its rep range.
+// Rules of exercises that left the program are kept; they are skipped
+// until the exercise comes back.
+func invalidRules(rules []models.ProgressionRule, program models.Program) []int{
+	slots := firstSlots(program)
+	var invalid []int
+	for _, rule := range rules{
+		if slot, ok := slots[rule.ExerciseID]; ok && validateProgressionRule(rule, slot) != nil{
+			invalid = append(invalid, rule.ExerciseID)
+		}
+	}
+	return invalid
+}
+
+func (s *ProgramService) resolveSwap(exercise string, replacement string) (int, int, error){
+	exercise, replacement = strings.TrimSpace(exercise), strings.TrimSpace(replacement)
+	if exercise == "" || replacement == ""{
+		return 0, 0, fmt.Errorf("exercise and replacement are required")
+	}
+	if exercise == replacement{
+		return 0, 0, fmt.Errorf("the replacement must be a different exercise")
+	}
+	found, err := s.ProgramRepo.GetExercisesByNames([]string{exercise, replacement})
+	if err != nil{
+		return 0, 0, err
+	}
+	byName := make(map[string]models.Exercise, len(found))
+	for _, e := range found{
+		byName[e.Name] = e
+	}
+	for _, name := range []string{exercise, replacement}{
+		if _, ok := byName[name]; !ok{
+			return 0, 0, fmt.Errorf("exercise %q not found", name)
+		}
+	}
+	from, to := byName[exercise], byName[replacement]
+	if (from.Type == models.ExerciseTypeCardio) != (to.Type == models.ExerciseTypeCardio){
+		return 0, 0, fmt.Errorf("cardio and strength exercises can not replace each other")
+	}
+	return from.ID, to.ID, nil
+}
+
+// substitutionLink returns the original exercise to remember after swapping
+// fromID for toID; nil once the original is back in place.
+func substitutionLink(current *int, fromID int, toID int) *int{
+	original := fromID
+	if current != nil{
+		original = *current
+	}
+	if original == toID{
+		return nil
+	}
+	return &original
 }
 
 func (s *ProgramService) DeleteProgram(programID int, userID int) (int, error){
@@ -176,9 +541,6 @@ func (s *ProgramService) DeleteProgram(programID int, userID int) (int, error){
 	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-	if err := s.ProgramRepo.DeleteExercisesProgram(programID); err != nil{
-		return 0, fmt.Errorf("%s: %w", op, err)	
-	}
 	
 	return deletedID, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+func (s *ProgramService) ListVersions(userID int, programID int) ([]models.ProgramVersionSummary, error){
+	const op = "internal.servises.ListVersions"
+
+	if _, err := s.ProgramRepo.GetProgramByID(programID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	versions, err := s.ProgramRepo.ListProgramVersions(programID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return versions, nil
+}
+
+// GetVersion returns the program as it was in the given version.
+func (s *ProgramService) GetVersion(userID int, programID int, version int, unit units.System) (*models.ResponseProgramVersion, error){
+	const op = "internal.servises.GetVersion"
+
+	program, err := s.ProgramRepo.GetProgramByID(programID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	programVersion, err := s.ProgramRepo.GetProgramVersion(programID, version)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	program.Name = programVersion.Name
+	program.Phases = programVersion.Structure
+	resp, err := s.BuildResponseProgram(*program, userID, unit)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &models.ResponseProgramVersion{
+		RequestGetProgram: *resp,
+		Version: programVersion.Version,
+		VersionCreatedAt: programVersion.CreatedAt,
+	}, nil
+}
+
+// DiffVersions compares two versions of a program. A zero to means the
+// current version and a zero from the one before to.
+func (s *ProgramService) DiffVersions(userID int, programID int, from int, to int, unit units.System) (*models.ProgramVersionDiff, error){
+	const op = "internal.servises.DiffVersions"
+
+	versions, err := s.ListVersions(userID, programID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if to == 0 && len(versions) > 0{
+		to = versions[0].Version
+	}
+	if from == 0{
+		from = to - 1
+	}
+	if from < 1 || from == to{
+		return nil, fmt.Errorf("%s: choose two different versions to compare", op)
+	}
+
+	fromVersion, err := s.ProgramRepo.GetProgramVersion(programID, from)
+	if err != nil{
+		return nil, fmt.Errorf("%s: version %d: %w", op, from, err)
+	}
+	toVersion, err := s.ProgramRepo.GetProgramVersion(programID, to)
+	if err != nil{
+		return nil, fmt.Errorf("%s: version %d: %w", op, to, err)
+	}
+
+	idSet := make(map[int]bool)
+	for _, structure := range []models.ProgramStructure{fromVersion.Structure, toVersion.Structure}{
+		for _, day := range flattenDays(structure){
+			for _, slot := range day.Slots{
+				idSet[slot.ExerciseID] = true
+			}
+		}
+	}
+	idToName, err := s.GetIdToName(sortedKeys(idSet))
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	diff := diffProgramVersions(*fromVersion, *toVersion, idToName, unit)
+	return &diff, nil
+}
+
+// dayKey is the 1-based phase, week and day position of a training day.
+type dayKey [3]int
+
+func flattenDays(structure models.ProgramStructure) map[dayKey]models.ProgramDay{
+	days := make(map[dayKey]models.ProgramDay)
+	for i, phase := range structure{
+		for j, week := range phase.Weeks{
+			for k, day := range week.Days{
+				days[dayKey{i + 1, j + 1, k + 1}] = day
+			}
+		}
+	}
+	return days
+}
+
+func diffProgramVersions(from models.ProgramVersion, to models.ProgramVersion, idToName map[int]string, unit units.System) models.ProgramVersionDiff{
+	diff := models.ProgramVersionDiff{
+		From: from.Version,
+		To: to.Version,
+		Days: []models.ProgramDayDiff{},
+		Unit: unit.WeightUnit(),
+	}
+	if from.Name != to.Name{
+		diff.Name = &models.ProgramNameChange{From: from.Name, To: to.Name}
+	}
+
+	fromDays := flattenDays(from.Structure)
+	toDays := flattenDays(to.Structure)
+	keySet := make(map[dayKey]bool)
+	for key := range fromDays{
+		keySet[key] = true
+	}
+	for key := range toDays{
+		keySet[key] = true
+	}
+	keys := make([]dayKey, 0, len(keySet))
+	for key := range keySet{
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool{
+		for n := 0; n < 3; n++{
+			if keys[i][n] != keys[j][n]{
+				return keys[i][n] < keys[j][n]
+			}
+		}
+		return false
+	})
+
+	for _, key := range keys{
+		fromDay, inFrom := fromDays[key]
+		toDay, inTo := toDays[key]
+		dayDiff := models.ProgramDayDiff{Phase: key[0], Week: key[1], Day: key[2], Name: toDay.Name}
+		switch{
+		case !inFrom:
+			dayDiff.Change = models.ProgramChangeAdded
+			dayDiff.Exercises = diffDaySlots(nil, toDay.Slots, idToName, unit)
+		case !inTo:
+			dayDiff.Name = fromDay.Name
+			dayDiff.Change = models.ProgramChangeRemoved
+			dayDiff.Exercises = diffDaySlots(fromDay.Slots, nil, idToName, unit)
+		default:
+			dayDiff.Exercises = diffDaySlots(fromDay.Slots, toDay.Slots, idToName, unit)
+			if fromDay.Name != toDay.Name{
+				dayDiff.Fields = append(dayDiff.Fields, "name")
+			}
+			if !equalExerciseGroups(fromDay.Groups, toDay.Groups){
+				dayDiff.Fields = append(dayDiff.Fields, "groups")
+			}
+			if len(dayDiff.Exercises) == 0 && len(dayDiff.Fields) == 0{
+				continue
+			}
+			dayDiff.Change = models.ProgramChangeChanged
+		}
+		diff.Days = append(diff.Days, dayDiff)
+	}
+	return diff
+}
+
+// slotKey matches the n-th occurrence of an exercise within a day.
+type slotKey struct {
+	exerciseID int
+	occurrence int
+}
+
+func diffDaySlots(from []models.ProgramSlot, to []models.ProgramSlot, idToName map[int]string, unit units.System) []models.ProgramSlotDiff{
+	var diffs []models.ProgramSlotDiff
+
+	fromByKey := make(map[slotKey]int)
+	seen := make(map[int]int)
+	for i, slot := range from{
+		fromByKey[slotKey{slot.ExerciseID, seen[slot.ExerciseID]}] = i
+		seen[slot.ExerciseID]++
+	}
+
+	matched := make(map[int]bool)
+	seen = make(map[int]int)
+	for i, slot := range to{
+		key := slotKey{slot.ExerciseID, seen[slot.ExerciseID]}
+		seen[slot.ExerciseID]++
+		toResp := slotToResponse(slot, idToName[slot.ExerciseID], nil, unit)
+
+		j, ok := fromByKey[key]
+		if !ok{
+			diffs = append(diffs, models.ProgramSlotDiff{Exercise: toResp.Name, Change: models.ProgramChangeAdded, To: &toResp})
+			continue
+		}
+		matched[j] = true
+		fields := changedSlotFields(from[j], slot)
+		if i != j{
+			fields = append(fields, "position")
+		}
+		if len(fields) == 0{
+			continue
+		}
+		fromResp := slotToResponse(from[j], idToName[slot.ExerciseID], nil, unit)
+		diffs = append(diffs, models.ProgramSlotDiff{
+			Exercise: toResp.Name,
+			Change: models.ProgramChangeChanged,
+			Fields: fields,
+			From: &fromResp,
+			To: &toResp,
+		})
+	}
+
+	for j, slot := range from{
+		if matched[j]{
+			continue
+		}
+		fromResp := slotToResponse(slot, idToName[slot.ExerciseID], nil, unit)
+		diffs = append(diffs, models.ProgramSlotDiff{Exercise: fromResp.Name, Change: models.ProgramChangeRemoved, From: &fromResp})
+	}
+	return diffs
+}
+
+func changedSlotFields(a models.ProgramSlot, b models.ProgramSlot) []string{
+	var fields []string
+	if a.Sets != b.Sets{
+		fields = append(fields, "sets")
+	}
+	if a.Reps != b.Reps{
+		fields = append(fields, "reps")
+	}
+	if !equalPtr(a.RepsMax, b.RepsMax){
+		fields = append(fields, "reps_max")
+	}
+	if !equalPtr(a.Weight, b.Weight){
+		fields = append(fields, "weight")
+	}
+	if !equalPtr(a.PercentE1RM, b.PercentE1RM){
+		fields = append(fields, "percent_e1rm")
+	}
+	if !equalPtr(a.RestSeconds, b.RestSeconds){
+		fields = append(fields, "rest_seconds")
+	}
+	if a.Notes != b.Notes{
+		fields = append(fields, "notes")
+	}
+	if !equalPtr(a.Group, b.Group){
+		fields = append(fields, "group")
+	}
+	return fields
+}
+
+func equalPtr[T comparable](a *T, b *T) bool{
+	if a == nil || b == nil{
+		return a == b
+	}
+	return *a == *b
+}
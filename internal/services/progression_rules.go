@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+const (
+	defaultDeloadPercent = 10.0
+	maxDeloadPercent     = 50.0
+	maxWaveSteps         = 12
+	// weightTolerance absorbs rounding when a target converted from pounds is
+	// compared with the weight that was actually logged.
+	weightTolerance = 0.05
+)
+
+func validateProgressionRule(rule models.ProgressionRule, slot models.ProgramSlot) error{
+	switch rule.Type{
+	case models.ProgressionLinear, models.ProgressionDouble:
+		if rule.Increment <= 0{
+			return fmt.Errorf("increment must be positive")
+		}
+		if len(rule.WavePercents) > 0{
+			return fmt.Errorf("wave_percents is only allowed for wave rules")
+		}
+	case models.ProgressionWave:
+		if rule.Increment < 0{
+			return fmt.Errorf("increment must not be negative")
+		}
+		if len(rule.WavePercents) == 0 || len(rule.WavePercents) > maxWaveSteps{
+			return fmt.Errorf("wave rules need 1 to %d wave_percents", maxWaveSteps)
+		}
+		for _, p := range rule.WavePercents{
+			if p <= 0 || p > maxPercentE1RM{
+				return fmt.Errorf("wave percents must be between 0 and %.0f", maxPercentE1RM)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown progression type %q, expected linear, double or wave", rule.Type)
+	}
+	if rule.Type == models.ProgressionDouble && slot.RepsMax == nil{
+		return fmt.Errorf("double progression needs a rep range in the program, e.g. 3x8-12")
+	}
+	if rule.DeloadAfter < 0{
+		return fmt.Errorf("deload_after must not be negative")
+	}
+	if rule.DeloadPercent <= 0 || rule.DeloadPercent > maxDeloadPercent{
+		return fmt.Errorf("deload_percent must be between 0 and %.0f", maxDeloadPercent)
+	}
+	return nil
+}
+
+// progressionTarget is what the next session asks for: slot.Sets sets of
+// reps at weight kilograms.
+func progressionTarget(rule models.ProgressionRule, state models.ProgressionState) (int, float64){
+	if rule.Type == models.ProgressionWave{
+		step := state.WaveStep % len(rule.WavePercents)
+		return state.Reps, roundWeight(state.Weight * rule.WavePercents[step] / 100)
+	}
+	return state.Reps, state.Weight
+}
+
+// evaluateProgression applies the rule to the sets logged for the exercise
+// and returns the new state with a history entry explaining the change.
+func evaluateProgression(rule models.ProgressionRule, state models.ProgressionState, slot models.ProgramSlot,
+	entry models.ExerciseEntry) (models.ProgressionState, models.ProgressionEvent){
+	reps, weight := progressionTarget(rule, state)
+	next := state
+	event := models.ProgressionEvent{
+		ProgramID: rule.ProgramID,
+		ExerciseID: rule.ExerciseID,
+		PreviousWeight: state.Weight,
+		PreviousReps: state.Reps,
+	}
+
+	hit := setsAtTarget(entry, reps, weight)
+	if hit >= slot.Sets{
+		next.Failures = 0
+		switch rule.Type{
+		case models.ProgressionLinear:
+			next.Weight = roundWeight(state.Weight + rule.Increment)
+			event.Action = models.ProgressionActionIncrease
+			event.Reason = fmt.Sprintf("completed %d of %d sets of %d reps at the target weight", hit, slot.Sets, reps)
+		case models.ProgressionDouble:
+			if reps >= *slot.RepsMax{
+				next.Weight = roundWeight(state.Weight + rule.Increment)
+				next.Reps = slot.Reps
+				event.Action = models.ProgressionActionIncrease
+				event.Reason = fmt.Sprintf("reached the top of the %d-%d rep range on all %d sets; reps reset to %d",
+					slot.Reps, *slot.RepsMax, slot.Sets, slot.Reps)
+			} else{
+				next.Reps = reps + 1
+				event.Action = models.ProgressionActionAddReps
+				event.Reason = fmt.Sprintf("completed %d of %d sets of %d reps; aiming for %d of %d-%d next",
+					hit, slot.Sets, reps, next.Reps, slot.Reps, *slot.RepsMax)
+			}
+		case models.ProgressionWave:
+			next.WaveStep = state.WaveStep + 1
+			if next.WaveStep >= len(rule.WavePercents){
+				next.WaveStep = 0
+				next.Weight = roundWeight(state.Weight + rule.Increment)
+				event.Action = models.ProgressionActionIncrease
+				event.Reason = fmt.Sprintf("completed the last step of the %d-step wave; training max raised and wave restarted",
+					len(rule.WavePercents))
+			} else{
+				event.Action = models.ProgressionActionAdvance
+				event.Reason = fmt.Sprintf("completed wave step %d of %d at %g%%; next step at %g%%", state.WaveStep+1,
+					len(rule.WavePercents), rule.WavePercents[state.WaveStep], rule.WavePercents[next.WaveStep])
+			}
+		}
+	} else{
+		next.Failures = state.Failures + 1
+		missed := fmt.Sprintf("completed %d of %d sets of %d reps at the target weight", hit, slot.Sets, reps)
+		if rule.DeloadAfter > 0 && next.Failures >= rule.DeloadAfter{
+			next.Weight = roundWeight(state.Weight * (1 - rule.DeloadPercent / 100))
+			next.Reps = slot.Reps
+			next.WaveStep = 0
+			next.Failures = 0
+			event.Action = models.ProgressionActionDeload
+			event.Reason = fmt.Sprintf("%s; targets missed in %d sessions in a row, load reduced by %g%%",
+				missed, rule.DeloadAfter, rule.DeloadPercent)
+		} else{
+			event.Action = models.ProgressionActionHold
+			event.Reason = missed
+			if rule.DeloadAfter > 0{
+				event.Reason += fmt.Sprintf("; miss %d of %d before a deload", next.Failures, rule.DeloadAfter)
+			}
+		}
+	}
+
+	event.NewWeight = next.Weight
+	event.NewReps = next.Reps
+	return next, event
+}
+
+// setsAtTarget counts the logged sets with at least reps repetitions at
+// weight or more. Sets without a logged weight count only for bodyweight
+// targets.
+func setsAtTarget(entry models.ExerciseEntry, reps int, weight float64) int{
+	hit := 0
+	for i, r := range entry.Reps{
+		w := 0.0
+		if i < len(entry.Weight){
+			w = entry.Weight[i]
+		}
+		if int(r) >= reps && w >= weight - weightTolerance{
+			hit++
+		}
+	}
+	return hit
+}
+
+func roundWeight(kg float64) float64{
+	return math.Round(kg * 100) / 100
+}
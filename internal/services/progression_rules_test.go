@@ -0,0 +1,109 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+)
+
+func intPtr(v int) *int{
+	return &v
+}
+
+func entryOf(reps []int64, weight []float64) models.ExerciseEntry{
+	return models.ExerciseEntry{Reps: reps, Weight: weight}
+}
+
+func TestEvaluateProgression(t *testing.T){
+	fixed := models.ProgramSlot{Sets: 3, Reps: 5}
+	ranged := models.ProgramSlot{Sets: 3, Reps: 8, RepsMax: intPtr(10)}
+	linear := models.ProgressionRule{Type: models.ProgressionLinear, Increment: 2.5, DeloadAfter: 2, DeloadPercent: 10}
+	double := models.ProgressionRule{Type: models.ProgressionDouble, Increment: 2, DeloadPercent: 10}
+	wave := models.ProgressionRule{Type: models.ProgressionWave, Increment: 5, DeloadPercent: 10, WavePercents: []float64{70, 80}}
+
+	tests := []struct{
+		name       string
+		rule       models.ProgressionRule
+		state      models.ProgressionState
+		slot       models.ProgramSlot
+		entry      models.ExerciseEntry
+		wantAction string
+		wantState  models.ProgressionState
+	}{
+		{
+			"linear increase", linear, models.ProgressionState{Weight: 100, Reps: 5}, fixed,
+			entryOf([]int64{5, 5, 6}, []float64{100, 100, 100}),
+			models.ProgressionActionIncrease, models.ProgressionState{Weight: 102.5, Reps: 5},
+		},
+		{
+			"pound rounding counts as the target", linear, models.ProgressionState{Weight: 100, Reps: 5}, fixed,
+			entryOf([]int64{5, 5, 5}, []float64{99.97, 99.97, 99.97}),
+			models.ProgressionActionIncrease, models.ProgressionState{Weight: 102.5, Reps: 5},
+		},
+		{
+			"linear miss", linear, models.ProgressionState{Weight: 100, Reps: 5}, fixed,
+			entryOf([]int64{5, 5, 4}, []float64{100, 100, 100}),
+			models.ProgressionActionHold, models.ProgressionState{Weight: 100, Reps: 5, Failures: 1},
+		},
+		{
+			"deload after repeated misses", linear, models.ProgressionState{Weight: 100, Reps: 5, Failures: 1}, fixed,
+			entryOf([]int64{5, 5}, []float64{100, 100}),
+			models.ProgressionActionDeload, models.ProgressionState{Weight: 90, Reps: 5},
+		},
+		{
+			"double adds reps", double, models.ProgressionState{Weight: 40, Reps: 8}, ranged,
+			entryOf([]int64{8, 8, 8}, []float64{40, 40, 40}),
+			models.ProgressionActionAddReps, models.ProgressionState{Weight: 40, Reps: 9},
+		},
+		{
+			"double adds weight at the top of the range", double, models.ProgressionState{Weight: 40, Reps: 10}, ranged,
+			entryOf([]int64{10, 10, 10}, []float64{40, 40, 40}),
+			models.ProgressionActionIncrease, models.ProgressionState{Weight: 42, Reps: 8},
+		},
+		{
+			"double without deload holds", double, models.ProgressionState{Weight: 40, Reps: 9, Failures: 4}, ranged,
+			entryOf([]int64{9, 9, 7}, []float64{40, 40, 40}),
+			models.ProgressionActionHold, models.ProgressionState{Weight: 40, Reps: 9, Failures: 5},
+		},
+		{
+			"wave advances", wave, models.ProgressionState{Weight: 100, Reps: 5}, fixed,
+			entryOf([]int64{5, 5, 5}, []float64{70, 70, 70}),
+			models.ProgressionActionAdvance, models.ProgressionState{Weight: 100, Reps: 5, WaveStep: 1},
+		},
+		{
+			"wave restarts with a higher training max", wave, models.ProgressionState{Weight: 100, Reps: 5, WaveStep: 1}, fixed,
+			entryOf([]int64{5, 5, 5}, []float64{80, 80, 80}),
+			models.ProgressionActionIncrease, models.ProgressionState{Weight: 105, Reps: 5},
+		},
+		{
+			"wave under the step weight", wave, models.ProgressionState{Weight: 100, Reps: 5, WaveStep: 1}, fixed,
+			entryOf([]int64{5, 5, 5}, []float64{70, 70, 70}),
+			models.ProgressionActionHold, models.ProgressionState{Weight: 100, Reps: 5, WaveStep: 1, Failures: 1},
+		},
+	}
+	for _, tt := range tests{
+		t.Run(tt.name, func(t *testing.T){
+			next, event := evaluateProgression(tt.rule, tt.state, tt.slot, tt.entry)
+			if next != tt.wantState{
+				t.Errorf("state = %+v, want %+v", next, tt.wantState)
+			}
+			if event.Action != tt.wantAction{
+				t.Errorf("action = %q, want %q (%s)", event.Action, tt.wantAction, event.Reason)
+			}
+			if event.PreviousWeight != tt.state.Weight || event.NewWeight != next.Weight || event.NewReps != next.Reps{
+				t.Errorf("event = %+v does not match %+v -> %+v", event, tt.state, next)
+			}
+		})
+	}
+}
+
+func TestValidateProgressionRuleNeedsRepRange(t *testing.T){
+	rule := models.ProgressionRule{Type: models.ProgressionDouble, Increment: 2, DeloadPercent: 10}
+	if err := validateProgressionRule(rule, models.ProgramSlot{Sets: 3, Reps: 8, RepsMax: intPtr(12)}); err != nil{
+		t.Errorf("validateProgressionRule() with a rep range: %v", err)
+	}
+	// a slot changed to a fixed scheme after the rule was saved
+	if err := validateProgressionRule(rule, models.ProgramSlot{Sets: 3, Reps: 8}); err == nil{
+		t.Error("validateProgressionRule() accepted double progression without a rep range")
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+	"github.com/lib/pq"
+)
+
+// waveTrainingMaxPercent is the share of the estimated one-rep max used as
+// the starting training max of a wave rule.
+const waveTrainingMaxPercent = 90.0
+
+type ProgressionService struct {
+	ProgressionRepo *repositories.ProgressionRepository
+	ProgramRepo     *repositories.ProgramRepository
+	Equipment       *EquipmentService
+}
+
+func NewProgressionService(repo *repositories.ProgressionRepository, programRepo *repositories.ProgramRepository, equipment *EquipmentService) *ProgressionService{
+	return &ProgressionService{ProgressionRepo: repo, ProgramRepo: programRepo, Equipment: equipment}
+}
+
+// SaveRule creates or replaces the rule for one exercise of the program and
+// restarts its progression from the starting weight. Linear and double rules
+// without an increment use the one of the default equipment profile.
+func (s *ProgressionService) SaveRule(userID int, programID int, req models.RequestProgressionRule, unit units.System) error{
+	const op = "internal.servises.SaveRule"
+
+	program, err := s.ProgramRepo.GetProgramByID(programID, userID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	exerciseID, err := s.exerciseID(req.Exercise)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	slot, ok := firstSlots(*program)[exerciseID]
+	if !ok{
+		return fmt.Errorf("%s: exercise %q is not part of the program", op, req.Exercise)
+	}
+
+	rule := models.ProgressionRule{
+		ProgramID: programID,
+		ExerciseID: exerciseID,
+		Type: req.Type,
+		Increment: units.ToKilograms(req.Increment, unit),
+		DeloadAfter: req.DeloadAfter,
+		DeloadPercent: defaultDeloadPercent,
+		WavePercents: pq.Float64Array(req.WavePercents),
+	}
+	if req.DeloadPercent != nil{
+		rule.DeloadPercent = *req.DeloadPercent
+	}
+	if rule.Increment == 0 && (rule.Type == models.ProgressionLinear || rule.Type == models.ProgressionDouble){
+		rule.Increment, err = s.Equipment.DefaultIncrement(userID, exerciseID)
+		if err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := validateProgressionRule(rule, slot); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	weight, err := s.startWeight(userID, rule, slot, req.StartWeight, unit)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	state := models.ProgressionState{
+		ProgramID: programID,
+		ExerciseID: exerciseID,
+		Weight: weight,
+		Reps: slot.Reps,
+	}
+	if err := s.ProgressionRepo.SaveRule(rule, state); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// startWeight picks the first target in kilograms: the requested weight, the
+// slot's fixed weight, or a share of the estimated one-rep max.
+func (s *ProgressionService) startWeight(userID int, rule models.ProgressionRule, slot models.ProgramSlot,
+	requested *float64, unit units.System) (float64, error){
+	if requested != nil{
+		if *requested < 0{
+			return 0, fmt.Errorf("start_weight must not be negative")
+		}
+		return units.ToKilograms(*requested, unit), nil
+	}
+	if rule.Type != models.ProgressionWave && slot.Weight != nil{
+		return *slot.Weight, nil
+	}
+
+	e1rm, err := s.ProgramRepo.GetEstimatedOneRepMaxes(userID, []int{rule.ExerciseID})
+	if err != nil{
+		return 0, err
+	}
+	best, ok := e1rm[rule.ExerciseID]
+	switch{
+	case ok && rule.Type == models.ProgressionWave:
+		return roundWeight(best * waveTrainingMaxPercent / 100), nil
+	case ok && slot.PercentE1RM != nil:
+		return roundWeight(best * *slot.PercentE1RM / 100), nil
+	}
+	return 0, fmt.Errorf("start_weight is required when the program has no weight and no history for the exercise")
+}
+
+func (s *ProgressionService) GetRules(userID int, programID int, unit units.System) ([]models.ResponseProgressionRule, error){
+	const op = "internal.servises.GetRules"
+
+	program, err := s.ProgramRepo.GetProgramByID(programID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rules, err := s.ProgressionRepo.GetRules(programID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	states, err := s.ProgressionRepo.GetStates(programID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ids := make([]int, 0, len(rules))
+	for _, rule := range rules{
+		ids = append(ids, rule.ExerciseID)
+	}
+	idToName, err := s.exerciseNames(ids)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	slots := firstSlots(*program)
+	resp := make([]models.ResponseProgressionRule, 0, len(rules))
+	for _, rule := range rules{
+		state := states[rule.ExerciseID]
+		reps, weight := progressionTarget(rule, state)
+		item := models.ResponseProgressionRule{
+			Exercise: idToName[rule.ExerciseID],
+			Type: rule.Type,
+			Increment: units.FromKilograms(rule.Increment, unit),
+			DeloadAfter: rule.DeloadAfter,
+			DeloadPercent: rule.DeloadPercent,
+			WavePercents: rule.WavePercents,
+			Next: models.ProgressionTarget{
+				Sets: slots[rule.ExerciseID].Sets,
+				Reps: reps,
+				Weight: units.FromKilograms(weight, unit),
+			},
+			Failures: state.Failures,
+			Unit: unit.WeightUnit(),
+		}
+		if rule.Type == models.ProgressionWave{
+			trainingMax := units.FromKilograms(state.Weight, unit)
+			step := state.WaveStep + 1
+			item.Next.TrainingMax = &trainingMax
+			item.Next.WaveStep = &step
+		}
+		resp = append(resp, item)
+	}
+	return resp, nil
+}
+
+func (s *ProgressionService) DeleteRule(userID int, programID int, exercise string) (bool, error){
+	const op = "internal.servises.DeleteRule"
+
+	if _, err := s.ProgramRepo.GetProgramByID(programID, userID); err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	exerciseID, err := s.exerciseID(exercise)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	deleted, err := s.ProgressionRepo.DeleteRule(programID, exerciseID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return deleted, nil
+}
+
+// GetHistory lists the changes made by the rules of a program, optionally
+// for a single exercise.
+func (s *ProgressionService) GetHistory(userID int, programID int, exercise string, unit units.System) ([]models.ResponseProgressionEvent, error){
+	const op = "internal.servises.GetHistory"
+
+	if _, err := s.ProgramRepo.GetProgramByID(programID, userID); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	exerciseID := 0
+	if exercise != ""{
+		id, err := s.exerciseID(exercise)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		exerciseID = id
+	}
+
+	events, err := s.ProgressionRepo.GetHistory(programID, exerciseID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ids := make([]int, 0, len(events))
+	for _, event := range events{
+		ids = append(ids, event.ExerciseID)
+	}
+	idToName, err := s.exerciseNames(ids)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	resp := make([]models.ResponseProgressionEvent, 0, len(events))
+	for _, event := range events{
+		resp = append(resp, models.ResponseProgressionEvent{
+			Exercise: idToName[event.ExerciseID],
+			WorkoutID: event.WorkoutID,
+			Action: event.Action,
+			PreviousWeight: units.FromKilograms(event.PreviousWeight, unit),
+			NewWeight: units.FromKilograms(event.NewWeight, unit),
+			PreviousReps: event.PreviousReps,
+			NewReps: event.NewReps,
+			Reason: event.Reason,
+			Unit: unit.WeightUnit(),
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return resp, nil
+}
+
+// Evaluate runs the program's rules against a workout that was just logged
+// for it. Exercises without a rule, or not performed, are left unchanged.
+func (s *ProgressionService) Evaluate(userID int, programID int, workoutID int, entries []models.ExerciseEntry) error{
+	const op = "internal.servises.Evaluate"
+
+	program, err := s.ProgramRepo.GetProgramByID(programID, userID)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return nil
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	rules, err := s.ProgressionRepo.GetRules(programID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(rules) == 0{
+		return nil
+	}
+	states, err := s.ProgressionRepo.GetStates(programID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	performed := make(map[int]models.ExerciseEntry)
+	for _, entry := range entries{
+		merged := performed[entry.ExerciseID]
+		merged.ExerciseID = entry.ExerciseID
+		merged.Reps = append(merged.Reps, entry.Reps...)
+		merged.Weight = append(merged.Weight, entry.Weight...)
+		performed[entry.ExerciseID] = merged
+	}
+
+	slots := firstSlots(*program)
+	var newStates []models.ProgressionState
+	var events []models.ProgressionEvent
+	for _, rule := range rules{
+		entry, done := performed[rule.ExerciseID]
+		state, hasState := states[rule.ExerciseID]
+		slot, inProgram := slots[rule.ExerciseID]
+		if !done || !hasState || !inProgram{
+			continue
+		}
+		// the slot may have changed since the rule was saved
+		if err := validateProgressionRule(rule, slot); err != nil{
+			log.Printf("warning: skipping progression rule for exercise %d of program %d: %v", rule.ExerciseID, programID, err)
+			continue
+		}
+		next, event := evaluateProgression(rule, state, slot, entry)
+		event.WorkoutID = &workoutID
+		newStates = append(newStates, next)
+		events = append(events, event)
+	}
+	if len(events) == 0{
+		return nil
+	}
+
+	if err := s.ProgressionRepo.SaveEvaluation(newStates, events); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *ProgressionService) exerciseID(name string) (int, error){
+	found, err := s.ProgramRepo.GetExercisesByNames([]string{name})
+	if err != nil{
+		return 0, err
+	}
+	if len(found) == 0{
+		return 0, fmt.Errorf("exercise %q not found", name)
+	}
+	return found[0].ID, nil
+}
+
+func (s *ProgressionService) exerciseNames(ids []int) (map[int]string, error){
+	idToName := make(map[int]string, len(ids))
+	if len(ids) == 0{
+		return idToName, nil
+	}
+	found, err := s.ProgramRepo.GetExercisesByID(ids)
+	if err != nil{
+		return nil, err
+	}
+	for _, e := range found{
+		idToName[e.ID] = e.Name
+	}
+	return idToName, nil
+}
+
+// firstSlots returns the first slot of each exercise in program order; its
+// sets and rep range are the targets the rules progress.
+func firstSlots(program models.Program) map[int]models.ProgramSlot{
+	slots := make(map[int]models.ProgramSlot)
+	for _, phase := range program.Phases{
+		for _, week := range phase.Weeks{
+			for _, day := range week.Days{
+				for _, slot := range day.Slots{
+					if _, ok := slots[slot.ExerciseID]; !ok{
+						slots[slot.ExerciseID] = slot
+					}
+				}
+			}
+		}
+	}
+	return slots
+}
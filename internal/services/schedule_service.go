@@ -0,0 +1,593 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/lib/pq"
+)
+
+const (
+	// scheduleHorizon is how far ahead recurring sessions are created.
+	scheduleHorizon = 8 * 7 * 24 * time.Hour
+	maxScheduleRange = 366 * 24 * time.Hour
+	// rescheduleWindow is how long after its original date a missed session
+	// may be moved by the next_free_day policy.
+	rescheduleWindow = 7 * 24 * time.Hour
+	// completionWindow is how far back a workout may complete a planned session.
+	completionWindow = 7 * 24 * time.Hour
+	scheduleWorkerInterval = time.Hour
+)
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+type ScheduleService struct {
+	ScheduleRepo *repositories.ScheduleRepository
+	ProgramRepo  *repositories.ProgramRepository
+	UserRepo     *repositories.UserRepository
+}
+
+func NewScheduleService(repo *repositories.ScheduleRepository, programRepo *repositories.ProgramRepository, userRepo *repositories.UserRepository) *ScheduleService{
+	return &ScheduleService{ScheduleRepo: repo, ProgramRepo: programRepo, UserRepo: userRepo}
+}
+
+// dateOnly drops the clock time; scheduled dates are calendar days in UTC.
+func dateOnly(t time.Time) time.Time{
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func today() time.Time{
+	return dateOnly(time.Now())
+}
+
+// parseWeekdays accepts "mon" or "monday" in any case and returns the
+// sorted weekday numbers with Sunday as 0.
+func parseWeekdays(names []string) (pq.Int64Array, error){
+	seen := make(map[int64]bool)
+	var days pq.Int64Array
+	for _, name := range names{
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for i, short := range weekdayNames{
+			if name == short || name == strings.ToLower(time.Weekday(i).String()){
+				if !seen[int64(i)]{
+					seen[int64(i)] = true
+					days = append(days, int64(i))
+				}
+				found = true
+				break
+			}
+		}
+		if !found{
+			return nil, fmt.Errorf("invalid weekday %q, expected mon, tue, wed, thu, fri, sat or sun", name)
+		}
+	}
+	if len(days) == 0{
+		return nil, fmt.Errorf("at least one weekday is required")
+	}
+	sort.Slice(days, func(i, j int) bool{ return days[i] < days[j] })
+	return days, nil
+}
+
+func formatWeekdays(days pq.Int64Array) []string{
+	names := make([]string, 0, len(days))
+	for _, d := range days{
+		names = append(names, weekdayNames[d])
+	}
+	return names
+}
+
+// programDayNames lists the program's training days in order across phases
+// and weeks; a schedule's day index points into it.
+func (s *ScheduleService) programDayNames(programID int) ([]string, error){
+	phases, err := s.ProgramRepo.GetProgramStructure(programID)
+	if err != nil{
+		return nil, err
+	}
+	var names []string
+	for _, phase := range phases{
+		for _, week := range phase.Weeks{
+			for _, day := range week.Days{
+				name := day.Name
+				if name == ""{
+					name = fmt.Sprintf("Day %d", len(names)+1)
+				}
+				names = append(names, name)
+			}
+		}
+	}
+	return names, nil
+}
+
+func (s *ScheduleService) checkProgramDay(userID int, programID int, day int) error{
+	if _, err := s.ProgramRepo.GetProgramByID(programID, userID); err != nil{
+		return err
+	}
+	names, err := s.programDayNames(programID)
+	if err != nil{
+		return err
+	}
+	if day < 1 || day > len(names){
+		return fmt.Errorf("day must be between 1 and %d for this program", len(names))
+	}
+	return nil
+}
+
+// ScheduleSession plans a single program day on a date.
+func (s *ScheduleService) ScheduleSession(userID int, req models.RequestScheduleSession) (int, error){
+	const op = "internal.servises.ScheduleSession"
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil{
+		return 0, fmt.Errorf("%s: invalid date, expected YYYY-MM-DD", op)
+	}
+	if req.Day == 0{
+		req.Day = 1
+	}
+	if err := s.checkProgramDay(userID, req.ProgramID, req.Day); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.ScheduleRepo.CreateSession(models.ScheduledSession{
+		UserID: userID,
+		ProgramID: req.ProgramID,
+		DayIndex: req.Day,
+		Date: date,
+	})
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+// CreateRule plans a program on recurring weekdays and creates its sessions
+// for the coming weeks.
+func (s *ScheduleService) CreateRule(userID int, req models.RequestScheduleRule) (int, error){
+	const op = "internal.servises.CreateRule"
+
+	weekdays, err := parseWeekdays(req.Weekdays)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	rule := models.ScheduleRule{UserID: userID, ProgramID: req.ProgramID, Weekdays: weekdays, StartDate: today(), DayIndex: req.Day}
+	if req.StartDate != ""{
+		rule.StartDate, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil{
+			return 0, fmt.Errorf("%s: invalid start_date, expected YYYY-MM-DD", op)
+		}
+	}
+	if req.EndDate != ""{
+		endDate, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil{
+			return 0, fmt.Errorf("%s: invalid end_date, expected YYYY-MM-DD", op)
+		}
+		if endDate.Before(rule.StartDate){
+			return 0, fmt.Errorf("%s: end_date must not be before start_date", op)
+		}
+		rule.EndDate = &endDate
+	}
+
+	day := 1
+	if req.Day != nil{
+		day = *req.Day
+	}
+	if err := s.checkProgramDay(userID, req.ProgramID, day); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := s.ScheduleRepo.CreateRule(rule)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.materialize(userID, today().Add(scheduleHorizon)); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s *ScheduleService) ListRules(userID int) ([]models.ResponseScheduleRule, error){
+	const op = "internal.servises.ListRules"
+
+	rules, err := s.ScheduleRepo.GetRules(userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	programs, err := s.ProgramRepo.ListPrograms(userID, "")
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	programNames := make(map[int]string, len(programs))
+	for _, program := range programs{
+		programNames[program.ID] = program.Name
+	}
+
+	resp := make([]models.ResponseScheduleRule, 0, len(rules))
+	for _, rule := range rules{
+		resp = append(resp, models.ResponseScheduleRule{
+			ID: rule.ID,
+			ProgramID: rule.ProgramID,
+			Program: programNames[rule.ProgramID],
+			Weekdays: formatWeekdays(rule.Weekdays),
+			StartDate: rule.StartDate,
+			EndDate: rule.EndDate,
+			Day: rule.DayIndex,
+		})
+	}
+	return resp, nil
+}
+
+// DeleteRule stops a recurring schedule; its planned sessions from today on
+// are removed.
+func (s *ScheduleService) DeleteRule(userID int, ruleID int) (bool, error){
+	const op = "internal.servises.DeleteRule"
+
+	deleted, err := s.ScheduleRepo.DeleteRule(ruleID, userID, today())
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return deleted, nil
+}
+
+func (s *ScheduleService) SkipSession(userID int, sessionID int) (bool, error){
+	const op = "internal.servises.SkipSession"
+
+	skipped, err := s.ScheduleRepo.SkipSession(sessionID, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return skipped, nil
+}
+
+func (s *ScheduleService) DeleteSession(userID int, sessionID int) (bool, error){
+	const op = "internal.servises.DeleteSession"
+
+	deleted, err := s.ScheduleRepo.DeleteSession(sessionID, userID)
+	if err != nil{
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return deleted, nil
+}
+
+// GetSchedule merges scheduled sessions with workouts that were logged
+// without one, for the dates from from to to inclusive. It doesn't write:
+// recurring sessions the worker hasn't created yet are computed on the fly
+// and have no session ID.
+func (s *ScheduleService) GetSchedule(userID int, from time.Time, to time.Time) ([]models.ScheduleEntry, error){
+	const op = "internal.servises.GetSchedule"
+
+	from, to = dateOnly(from), dateOnly(to)
+	if to.Before(from){
+		return nil, fmt.Errorf("%s: from must not be after to", op)
+	}
+	if to.Sub(from) > maxScheduleRange{
+		return nil, fmt.Errorf("%s: the range must not be longer than a year", op)
+	}
+
+	sessions, err := s.ScheduleRepo.GetSessions(userID, from, to)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	projected, err := s.projectSessions(userID, from, to)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	sessions = append(sessions, projected...)
+	workouts, err := s.ScheduleRepo.GetUnscheduledWorkouts(userID, from, to)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	dayNames := make(map[int][]string)
+	entries := make([]models.ScheduleEntry, 0, len(sessions)+len(workouts))
+	for _, session := range sessions{
+		names, ok := dayNames[session.ProgramID]
+		if !ok{
+			names, err = s.programDayNames(session.ProgramID)
+			if err != nil{
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			dayNames[session.ProgramID] = names
+		}
+		entry := models.ScheduleEntry{
+			Date: session.Date,
+			Kind: models.ScheduleEntrySession,
+			Status: session.Status,
+			RuleID: session.RuleID,
+			ProgramID: &session.ProgramID,
+			Program: session.ProgramName,
+			Day: &session.DayIndex,
+			DayName: fmt.Sprintf("Day %d", session.DayIndex),
+			WorkoutID: session.WorkoutID,
+			RescheduledFrom: session.RescheduledFrom,
+		}
+		if session.ID != 0{
+			entry.SessionID = &session.ID
+		}
+		if session.DayIndex <= len(names){
+			entry.DayName = names[session.DayIndex-1]
+		}
+		entries = append(entries, entry)
+	}
+	for _, workout := range workouts{
+		entry := models.ScheduleEntry{
+			Date: workout.Date,
+			Kind: models.ScheduleEntryWorkout,
+			Status: models.SessionStatusCompleted,
+			ProgramID: workout.ProgramID,
+			WorkoutID: &workout.ID,
+		}
+		if workout.ProgramName != nil{
+			entry.Program = *workout.ProgramName
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool{
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries, nil
+}
+
+// projectSessions computes the planned sessions of recurring rules from
+// from to to that are not stored yet, without saving them.
+func (s *ScheduleService) projectSessions(userID int, from time.Time, to time.Time) ([]models.ScheduleSessionRow, error){
+	rules, err := s.ScheduleRepo.GetRules(userID)
+	if err != nil{
+		return nil, err
+	}
+
+	var programNames map[int]string
+	var rows []models.ScheduleSessionRow
+	for _, rule := range rules{
+		end := to
+		if rule.EndDate != nil && rule.EndDate.Before(end){
+			end = *rule.EndDate
+		}
+		if end.Before(from) || (rule.MaterializedUntil != nil && !rule.MaterializedUntil.Before(end)){
+			continue
+		}
+
+		dayCount := 1
+		if rule.DayIndex == nil{
+			names, err := s.programDayNames(rule.ProgramID)
+			if err != nil{
+				return nil, err
+			}
+			dayCount = len(names)
+		}
+		if dayCount == 0{
+			continue
+		}
+
+		if programNames == nil{
+			programs, err := s.ProgramRepo.ListPrograms(userID, "")
+			if err != nil{
+				return nil, err
+			}
+			programNames = make(map[int]string, len(programs))
+			for _, program := range programs{
+				programNames[program.ID] = program.Name
+			}
+		}
+		for _, session := range ruleSessions(rule, dayCount, end){
+			if session.Date.Before(from){
+				continue
+			}
+			session.Status = models.SessionStatusPlanned
+			rows = append(rows, models.ScheduleSessionRow{ScheduledSession: session, ProgramName: programNames[rule.ProgramID]})
+		}
+	}
+	return rows, nil
+}
+
+// Refresh creates recurring sessions up to the schedule horizon and applies
+// the user's reschedule policy to missed sessions.
+func (s *ScheduleService) Refresh(userID int) error{
+	if err := s.materialize(userID, today().Add(scheduleHorizon)); err != nil{
+		return err
+	}
+	return s.rescheduleMissed(userID)
+}
+
+func (s *ScheduleService) materialize(userID int, until time.Time) error{
+	rules, err := s.ScheduleRepo.GetRulesToMaterialize(userID, until)
+	if err != nil{
+		return err
+	}
+	for _, rule := range rules{
+		dayCount := 1
+		if rule.DayIndex == nil{
+			names, err := s.programDayNames(rule.ProgramID)
+			if err != nil{
+				return err
+			}
+			dayCount = len(names)
+		}
+		if dayCount == 0{
+			continue
+		}
+		end := until
+		if rule.EndDate != nil && rule.EndDate.Before(end){
+			end = *rule.EndDate
+		}
+		if err := s.ScheduleRepo.SaveMaterialized(rule.ID, ruleSessions(rule, dayCount, end), end); err != nil{
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleSessions lists the sessions of a rule from the day after it was last
+// materialized up to until. Occurrences are counted from the start date so
+// the day rotation stays stable however often this runs.
+func ruleSessions(rule models.ScheduleRule, dayCount int, until time.Time) []models.ScheduledSession{
+	onDay := make(map[time.Weekday]bool)
+	for _, d := range rule.Weekdays{
+		onDay[time.Weekday(d)] = true
+	}
+
+	var sessions []models.ScheduledSession
+	occurrence := 0
+	for date := dateOnly(rule.StartDate); !date.After(until); date = date.AddDate(0, 0, 1){
+		if !onDay[date.Weekday()]{
+			continue
+		}
+		if rule.MaterializedUntil == nil || date.After(*rule.MaterializedUntil){
+			day := ((occurrence - rule.IndexOffset) % dayCount + dayCount) % dayCount + 1
+			if rule.DayIndex != nil{
+				day = *rule.DayIndex
+			}
+			n := occurrence
+			ruleID := rule.ID
+			sessions = append(sessions, models.ScheduledSession{
+				UserID: rule.UserID,
+				ProgramID: rule.ProgramID,
+				RuleID: &ruleID,
+				Occurrence: &n,
+				DayIndex: day,
+				Date: date,
+			})
+		}
+		occurrence++
+	}
+	return sessions
+}
+
+// rescheduleMissed handles planned sessions whose date has passed according
+// to the user's reschedule policy.
+func (s *ScheduleService) rescheduleMissed(userID int) error{
+	now := today()
+	sessions, err := s.ScheduleRepo.GetOverdueSessions(userID, now)
+	if err != nil || len(sessions) == 0{
+		return err
+	}
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil{
+		return err
+	}
+
+	for _, session := range sessions{
+		switch user.ReschedulePolicy{
+		case models.ReschedulePolicyShift:
+			shifted, err := s.shiftRule(userID, session, now)
+			if err != nil{
+				return err
+			}
+			if shifted{
+				continue
+			}
+			fallthrough
+		case models.ReschedulePolicyNextFreeDay:
+			moved, err := s.moveToFreeDay(userID, session, now)
+			if err != nil{
+				return err
+			}
+			if moved{
+				continue
+			}
+		}
+		if err := s.ScheduleRepo.MarkMissed(session.ID); err != nil{
+			return err
+		}
+	}
+	return nil
+}
+
+// shiftRule marks a missed session of a rotating rule and gives its program
+// day to the next planned date of the rule. It reports false for sessions
+// the shift policy does not apply to.
+func (s *ScheduleService) shiftRule(userID int, session models.ScheduledSession, now time.Time) (bool, error){
+	if session.RuleID == nil || session.Occurrence == nil{
+		return false, nil
+	}
+	rule, err := s.ScheduleRepo.GetRule(*session.RuleID, userID)
+	if err != nil || rule.DayIndex != nil{
+		return false, err
+	}
+	names, err := s.programDayNames(rule.ProgramID)
+	if err != nil || len(names) == 0{
+		return false, err
+	}
+
+	if err := s.ScheduleRepo.MarkMissed(session.ID); err != nil{
+		return false, err
+	}
+	if err := s.ScheduleRepo.ShiftRule(rule.ID, len(names), now); err != nil{
+		return false, err
+	}
+	return true, nil
+}
+
+// moveToFreeDay moves a missed session to the first day from today on that
+// has no other session, within rescheduleWindow of its original date.
+func (s *ScheduleService) moveToFreeDay(userID int, session models.ScheduledSession, now time.Time) (bool, error){
+	original := session.Date
+	if session.RescheduledFrom != nil{
+		original = *session.RescheduledFrom
+	}
+	last := dateOnly(original).Add(rescheduleWindow)
+
+	for date := now; !date.After(last); date = date.AddDate(0, 0, 1){
+		busy, err := s.ScheduleRepo.HasSessionOn(userID, date)
+		if err != nil{
+			return false, err
+		}
+		if busy{
+			continue
+		}
+		if err := s.ScheduleRepo.MoveSession(session.ID, date); err != nil{
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// CompleteSession links a workout logged for a program to the most recent
+// planned session of that program in the last week.
+func (s *ScheduleService) CompleteSession(userID int, programID int, workoutID int) error{
+	now := today()
+	if _, err := s.ScheduleRepo.LinkWorkout(userID, programID, workoutID, now.Add(-completionWindow), now); err != nil{
+		return err
+	}
+	return nil
+}
+
+// ReopenSession puts a session completed by a workout that is being deleted
+// back to planned.
+func (s *ScheduleService) ReopenSession(userID int, workoutID int) error{
+	return s.ScheduleRepo.UnlinkWorkout(workoutID, userID)
+}
+
+// RunWorker keeps recurring schedules materialized and applies reschedule
+// policies, once right away and then every scheduleWorkerInterval until ctx
+// is done.
+func (s *ScheduleService) RunWorker(ctx context.Context){
+	ticker := time.NewTicker(scheduleWorkerInterval)
+	defer ticker.Stop()
+
+	for{
+		now := today()
+		userIDs, err := s.ScheduleRepo.GetUsersWithPendingSchedule(now, now.Add(scheduleHorizon))
+		if err != nil{
+			log.Printf("warning: failed to load pending schedules: %v", err)
+		}
+		for _, userID := range userIDs{
+			if err := s.Refresh(userID); err != nil{
+				log.Printf("warning: failed to refresh schedule of user %d: %v", userID, err)
+			}
+		}
+
+		select{
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
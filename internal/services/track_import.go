@@ -0,0 +1,219 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/pkg/fit"
+	"github.com/artembliss/go-fitness-tracker/pkg/trackfile"
+	"github.com/lib/pq"
+)
+
+// sportKeywords picks a catalog exercise for an activity when the client
+// doesn't name one. Keywords are matched against cardio exercise names.
+var sportKeywords = map[string][]string{
+	"running": {"running", "run", "jog"},
+	"walking": {"walking", "walk"},
+	"hiking":  {"hiking", "hike", "walking"},
+	"biking":  {"cycling", "bicycl", "bike", "biking"},
+	"cycling": {"cycling", "bicycl", "bike", "biking"},
+	"swimming": {"swim"},
+	"rowing":  {"rowing", "rower"},
+}
+
+// uniqueViolation is the Postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
+// DuplicateImportError is returned when the same file was imported before.
+type DuplicateImportError struct {
+	WorkoutID int
+}
+
+func (e *DuplicateImportError) Error() string{
+	return fmt.Sprintf("this file was already imported as workout %d", e.WorkoutID)
+}
+
+// ImportTrack creates a workout from a GPX, TCX or FIT file. GPS activities
+// become a single cardio entry whose intervals are the laps (or kilometre
+// splits); strength sets of FIT files become entries grouped by exercise.
+// The raw file is kept next to the workout and uploading the same file twice
+// is detected by its content hash.
+func (s *WorkoutService) ImportTrack(userID int, data []byte, exerciseName string) (*models.ResponseImportWorkout, error){
+	const op = "internal.servises.ImportTrack"
+
+	hash := contentHash(data)
+	if existingID, ok, err := s.WorkoutRepo.GetWorkoutIDByTrackHash(userID, hash); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	} else if ok{
+		return nil, &DuplicateImportError{WorkoutID: existingID}
+	}
+
+	format, err := trackfile.DetectFormat(data)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var activity *trackfile.Activity
+	var sets []fit.Set
+	if format == trackfile.FormatFIT{
+		file, err := fit.Decode(data)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		activity, sets = trackfile.FromFIT(file), file.Sets
+	} else{
+		activity, err = trackfile.Parse(data)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	result := &models.ResponseImportWorkout{Format: activity.Format, Sport: activity.Sport}
+	summary := activity.Summary()
+	var entries []models.ExerciseEntry
+
+	if len(sets) > 0{
+		strength, unmapped, err := s.mapFITSets(sets)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, strength...)
+		result.Unmapped = unmapped
+	}
+
+	// strength sessions carry no distance; only add a cardio entry when there was movement
+	if len(sets) == 0 || summary.Distance > 0{
+		exercise, err := s.resolveCardioExercise(exerciseName, activity.Sport)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entry := cardioEntryFromActivity(exercise.ID, activity, summary)
+		if err := validateExerciseEntry(exercise.Name, entry, exercise.Type); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, entry)
+		result.Exercise = exercise.Name
+	}
+
+	if len(entries) == 0{
+		return nil, fmt.Errorf("%s: no set of the file could be mapped to a catalog exercise: %v", op, result.Unmapped)
+	}
+
+	date := activity.Start
+	if date.IsZero() && len(sets) > 0{
+		date = sets[0].Start
+	}
+	if date.IsZero(){
+		date = time.Now()
+	}
+	duration := time.Duration(summary.DurationSeconds) * time.Second
+	if duration == 0 && len(sets) > 0{
+		duration = fitSetsDuration(sets)
+	}
+
+	workout := models.Workout{
+		UserID: userID,
+		Date: date,
+		Exercises: entries,
+		Duration: duration,
+	}
+	if err := s.ApplyCalories(&workout, nil); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	track := models.WorkoutTrack{UserID: userID, Format: activity.Format, ContentHash: hash, Data: data}
+	result.WorkoutID, err = s.WorkoutRepo.ImportWorkout(workout, &track)
+	if err != nil{
+		// a concurrent upload of the same file won the race
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation{
+			if existingID, ok, lookupErr := s.WorkoutRepo.GetWorkoutIDByTrackHash(userID, hash); lookupErr == nil && ok{
+				return nil, &DuplicateImportError{WorkoutID: existingID}
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// contentHash identifies an uploaded file for duplicate detection.
+func contentHash(data []byte) string{
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func cardioEntryFromActivity(exerciseID int, activity *trackfile.Activity, summary trackfile.Summary) models.ExerciseEntry{
+	entry := models.ExerciseEntry{ExerciseID: exerciseID, Reps: pq.Int64Array{}}
+	if summary.Distance > 0{
+		entry.Distance = &summary.Distance
+	}
+	if summary.DurationSeconds > 0{
+		entry.DurationSeconds = &summary.DurationSeconds
+	}
+	if summary.ElevationGain > 0{
+		entry.ElevationGain = &summary.ElevationGain
+	}
+	if summary.AvgHeartRate > 0{
+		entry.AvgHeartRate = &summary.AvgHeartRate
+	}
+	if summary.MaxHeartRate > 0{
+		entry.MaxHeartRate = &summary.MaxHeartRate
+	}
+	if summary.AvgCadence > 0{
+		entry.AvgCadence = &summary.AvgCadence
+	}
+	for _, split := range activity.Splits(){
+		entry.Intervals = append(entry.Intervals, models.Interval{
+			Type: split.Type,
+			Distance: split.Distance,
+			DurationSeconds: split.DurationSeconds,
+			AvgHeartRate: split.AvgHeartRate,
+			AvgCadence: split.AvgCadence,
+		})
+	}
+	return entry
+}
+
+func (s *WorkoutService) GetTrack(workoutID int, userID int) (*models.WorkoutTrack, error){
+	const op = "internal.servises.GetTrack"
+
+	track, err := s.WorkoutRepo.GetTrack(workoutID, userID)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return track, nil
+}
+
+func (s *WorkoutService) resolveCardioExercise(name, sport string) (*models.Exercise, error){
+	if name != ""{
+		found, err := s.WorkoutRepo.GetExercisesByNames([]string{name})
+		if err != nil{
+			return nil, err
+		}
+		if len(found) == 0{
+			return nil, fmt.Errorf("exercise %q not found", name)
+		}
+		exercises, err := s.GetExercisesForEntries([]models.ExerciseEntry{{ExerciseID: found[0].ID}})
+		if err != nil{
+			return nil, err
+		}
+		exercise := exercises[found[0].ID]
+		return &exercise, nil
+	}
+
+	keywords, ok := sportKeywords[strings.ToLower(strings.TrimSpace(sport))]
+	if !ok{
+		keywords = sportKeywords["running"]
+	}
+	exercise, err := s.WorkoutRepo.FindExerciseByKeywords(keywords, models.ExerciseTypeCardio)
+	if err != nil{
+		return nil, fmt.Errorf("no catalog exercise for sport %q, pass the exercise name: %w", sport, err)
+	}
+	return exercise, nil
+}
@@ -1,22 +1,43 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/repositories"
 	"github.com/artembliss/go-fitness-tracker/pkg/auth"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
 )
 type UserService struct {
-	UserRepo *repositories.UserRepository
+	UserRepo     *repositories.UserRepository
+	MetricRepo   *repositories.BodyMetricRepository
+	Verification *VerificationService
 }
 
-func NewUserService(repo *repositories.UserRepository) *UserService {
-	return &UserService{UserRepo: repo}
+func NewUserService(repo *repositories.UserRepository, metricRepo *repositories.BodyMetricRepository, verification *VerificationService) *UserService {
+	return &UserService{UserRepo: repo, MetricRepo: metricRepo, Verification: verification}
 }
 
-func (s *UserService) RegisterUserService(reqUser *models.RequestCreateUser) (models.User, error){
+// RegisterUserService creates an unverified account and sends the verification
+// email. A failed email is logged rather than failing registration; the user can resend it.
+func (s *UserService) RegisterUserService(ctx context.Context, reqUser *models.RequestCreateUser) (models.User, error){
 	const op = "services.RegisterUserService"
+
+	email, err := normalizeEmailAddress(reqUser.Email)
+	if err != nil{
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+	reqUser.Email = email
+
+	unit, err := units.Resolve(reqUser.Unit)
+	if err != nil{
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
 	
 	hashedPassword, err := auth.HashPassword(reqUser.Password)
 	if err != nil{
@@ -30,7 +51,8 @@ func (s *UserService) RegisterUserService(reqUser *models.RequestCreateUser) (mo
 		Age: reqUser.Age,
 		Gender: reqUser.Gender,
 		Height: reqUser.Height,
-		Weight: reqUser.Weight,
+		Weight: units.ToKilograms(reqUser.Weight, unit),
+		UnitSystem: string(unit),
 	}
 
 	userID, err := s.UserRepo.RegisterUserRepository(user)
@@ -39,6 +61,16 @@ func (s *UserService) RegisterUserService(reqUser *models.RequestCreateUser) (mo
 	}
 	user.ID = userID
 
+	if user.Weight > 0{
+		if _, err := s.MetricRepo.SaveMetric(models.BodyMetric{UserID: user.ID, MeasuredAt: time.Now(), Weight: &user.Weight}); err != nil{
+			log.Printf("warning: failed to log initial weight of user %d: %v", user.ID, err)
+		}
+	}
+
+	if err := s.Verification.SendVerification(ctx, user); err != nil{
+		log.Printf("warning: failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
@@ -52,13 +84,133 @@ func (s *UserService) GetUserByEmail(email string) (*models.User, error){
 	return user, nil
 }
 
-func (s *UserService) DeleteUser(email string, userID int) (int, error){
-	const op = "services.DeleteUser"
+var allowedGenders = map[string]bool{"male": true, "female": true, "other": true}
+
+// UpdateUser applies a partial profile update. When the email changes the
+// account becomes unverified, a verification email is sent to the new address
+// and a new token is returned because existing tokens are bound to the old email.
+func (s *UserService) UpdateUser(ctx context.Context, userID int, req models.RequestUpdateUser) (*models.ResponseUpdateUser, error){
+	const op = "services.UpdateUser"
 
-	deletedID, err := s.UserRepo.DeleteUser(email, userID)
+	current, err := s.UserRepo.GetUserByID(userID)
 	if err != nil{
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unitCandidates := []string{req.Unit}
+	if req.UnitSystem != nil{
+		unitCandidates = append(unitCandidates, *req.UnitSystem)
+	}
+	unit, err := units.Resolve(append(unitCandidates, current.UnitSystem)...)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields, err := validateUserUpdate(req, unit)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if email, ok := fields["email"]; ok && email == current.Email{
+		delete(fields, "email")
+	}
+	if email, ok := fields["email"].(string); ok{
+		if existing, err := s.UserRepo.GetUserByEmail(email); err == nil && existing.ID != userID{
+			return nil, fmt.Errorf("%s: email is already in use", op)
+		}
 	}
 
-	return deletedID, nil
-}
\ No newline at end of file
+	user, err := s.UserRepo.UpdateUser(userID, fields)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// a weight change is a new measurement, so it goes to the body metrics log too
+	if weight, ok := fields["weight"].(float64); ok{
+		if _, err := s.MetricRepo.SaveMetric(models.BodyMetric{UserID: userID, MeasuredAt: time.Now(), Weight: &weight}); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	resp := &models.ResponseUpdateUser{User: *user}
+	if _, ok := fields["email"]; ok{
+		if err := s.Verification.SendVerification(ctx, *user); err != nil{
+			log.Printf("warning: failed to send verification email to user %d: %v", user.ID, err)
+		}
+		resp.Token, err = auth.GenerateJWT(user.Email, user.TokenVersion)
+		if err != nil{
+			return nil, fmt.Errorf("%s: failed to generate token: %w", op, err)
+		}
+	}
+
+	return resp, nil
+}
+
+func validateUserUpdate(req models.RequestUpdateUser, unit units.System) (map[string]interface{}, error){
+	fields := make(map[string]interface{})
+
+	if req.Name != nil{
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > 255{
+			return nil, fmt.Errorf("name must be between 1 and 255 characters")
+		}
+		fields["name"] = name
+	}
+	if req.Email != nil{
+		email, err := normalizeEmailAddress(*req.Email)
+		if err != nil{
+			return nil, err
+		}
+		fields["email"] = email
+	}
+	if req.Age != nil{
+		if *req.Age < 10 || *req.Age > 120{
+			return nil, fmt.Errorf("age must be between 10 and 120")
+		}
+		fields["age"] = *req.Age
+	}
+	if req.Gender != nil{
+		gender := strings.ToLower(strings.TrimSpace(*req.Gender))
+		if !allowedGenders[gender]{
+			return nil, fmt.Errorf("gender must be one of male, female, other")
+		}
+		fields["gender"] = gender
+	}
+	if req.Height != nil{
+		if *req.Height < 50 || *req.Height > 300{
+			return nil, fmt.Errorf("height must be between 50 and 300 cm")
+		}
+		fields["height"] = *req.Height
+	}
+	if req.Weight != nil{
+		weight := units.ToKilograms(*req.Weight, unit)
+		if weight < 20 || weight > 500{
+			return nil, fmt.Errorf("weight must be between 20 and 500 kg")
+		}
+		fields["weight"] = weight
+	}
+	if req.UnitSystem != nil{
+		system, err := units.Parse(*req.UnitSystem)
+		if err != nil{
+			return nil, err
+		}
+		fields["unit_system"] = string(system)
+	}
+	if req.ReschedulePolicy != nil{
+		switch *req.ReschedulePolicy{
+		case models.ReschedulePolicyNone, models.ReschedulePolicyNextFreeDay, models.ReschedulePolicyShift:
+			fields["reschedule_policy"] = *req.ReschedulePolicy
+		default:
+			return nil, fmt.Errorf("reschedule_policy must be none, next_free_day or shift")
+		}
+	}
+
+	return fields, nil
+}
+
+func normalizeEmailAddress(email string) (string, error){
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || addr.Address != strings.TrimSpace(email){
+		return "", fmt.Errorf("invalid email address")
+	}
+	return addr.Address, nil
+}
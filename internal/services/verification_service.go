@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/internal/models"
+	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/auth"
+	"github.com/artembliss/go-fitness-tracker/pkg/mailer"
+	"github.com/artembliss/go-fitness-tracker/pkg/ratelimit"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	resendCooldown       = time.Minute
+	resendWindow         = time.Hour
+	resendLimit          = 5
+)
+
+// ResendLimitedError is returned when a user asks for verification emails too often.
+type ResendLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResendLimitedError) Error() string{
+	return fmt.Sprintf("verification email was sent recently, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+type VerificationService struct {
+	UserRepo         *repositories.UserRepository
+	VerificationRepo *repositories.EmailVerificationRepository
+	Mailer           mailer.Mailer
+	Limiter          ratelimit.Store
+}
+
+func NewVerificationService(userRepo *repositories.UserRepository, verificationRepo *repositories.EmailVerificationRepository,
+	m mailer.Mailer, limiter ratelimit.Store) *VerificationService {
+	return &VerificationService{UserRepo: userRepo, VerificationRepo: verificationRepo, Mailer: m, Limiter: limiter}
+}
+
+func (s *VerificationService) SendVerification(ctx context.Context, user models.User) error{
+	const op = "services.verification_service.SendVerification"
+
+	token, err := auth.GenerateRandomToken(32)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.VerificationRepo.SaveToken(user.ID, user.Email, auth.HashToken(token), time.Now().Add(emailVerificationTTL)); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	msg := mailer.Message{
+		To: user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below. It expires in %s.\n\n"+
+			"%s/user/verify?token=%s\n",
+			user.Name, emailVerificationTTL, appBaseURL(), token),
+	}
+	if err := s.Mailer.Send(ctx, msg); err != nil{
+		return fmt.Errorf("%s: failed to send verification email: %w", op, err)
+	}
+	return nil
+}
+
+func (s *VerificationService) VerifyEmail(token string) error{
+	const op = "services.verification_service.VerifyEmail"
+
+	verification, err := s.VerificationRepo.ConsumeToken(auth.HashToken(token))
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.UserRepo.MarkEmailVerified(verification.UserID, verification.Email); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// ResendVerification allows one email per resendCooldown and at most
+// resendLimit emails per resendWindow for each user.
+func (s *VerificationService) ResendVerification(ctx context.Context, userID int) error{
+	const op = "services.verification_service.ResendVerification"
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.EmailVerified{
+		return fmt.Errorf("%s: email is already verified", op)
+	}
+
+	key := fmt.Sprintf("verify:resend:%d", userID)
+	if d, err := s.Limiter.BlockedFor(ctx, key); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	} else if d > 0{
+		return &ResendLimitedError{RetryAfter: d}
+	}
+
+	sent, err := s.Limiter.Increment(ctx, key, resendWindow)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	block := resendCooldown
+	if sent >= resendLimit{
+		block = resendWindow
+	}
+	if err := s.Limiter.Block(ctx, key, block); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.SendVerification(ctx, *user); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
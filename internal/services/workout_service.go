@@ -2,27 +2,33 @@ package services
 
 import (
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/artembliss/go-fitness-tracker/internal/models"
 	"github.com/artembliss/go-fitness-tracker/internal/repositories"
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
 	"github.com/lib/pq"
 )
 
 type WorkoutService struct {
 	WorkoutRepo *repositories.WorkoutRepository
+	UserRepo    *repositories.UserRepository
+	Progression *ProgressionService
+	Schedule    *ScheduleService
 }
 
-func NewWorkoutService(repo *repositories.WorkoutRepository) *WorkoutService{
-	return &WorkoutService{WorkoutRepo: repo}
+func NewWorkoutService(repo *repositories.WorkoutRepository, userRepo *repositories.UserRepository, progression *ProgressionService, schedule *ScheduleService) *WorkoutService{
+	return &WorkoutService{WorkoutRepo: repo, UserRepo: userRepo, Progression: progression, Schedule: schedule}
 }
 
-func (s *WorkoutService) CreateWorkout(userID int, workoutCreate models.RequestCreateWorkout) (int, error){
+func (s *WorkoutService) CreateWorkout(userID int, workoutCreate models.RequestCreateWorkout, unit units.System) (int, error){
 	const op = "internal.servises.CreateWorkout"
 
 	var workout models.Workout
 	
-	programID, err := s.WorkoutRepo.GetProgramIdByName(workoutCreate.ProgramName)
+	programID, err := s.resolveProgramID(userID, workoutCreate.ProgramID, workoutCreate.ProgramName)
 	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
@@ -32,9 +38,21 @@ func (s *WorkoutService) CreateWorkout(userID int, workoutCreate models.RequestC
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	exercisesEntryToSave, notFound := s.MapToDBExercisesEntry(workoutCreate.Exercises, nameToID)
+	exercisesEntryToSave, notFound, err := s.MapToDBExercisesEntry(workoutCreate.Exercises, nameToID, unit)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
 	if len(notFound) > 0 {
-		return 0, fmt.Errorf("%s: some exercises not found: %w", op, err)
+		return 0, fmt.Errorf("%s: some exercises not found: %v", op, notFound)
+	}
+
+	if err := s.ValidateExercises(exercisesEntryToSave); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	groups, err := buildExerciseGroups(workoutCreate.Groups, entryGroupLabels(exercisesEntryToSave))
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	duration, err := time.ParseDuration(workoutCreate.Duration)
@@ -46,8 +64,12 @@ func (s *WorkoutService) CreateWorkout(userID int, workoutCreate models.RequestC
 		UserID: userID,
 		ProgramID: programID,
 		Exercises: exercisesEntryToSave,
+		ExerciseGroups: groups,
 		Duration: duration,
-		Calories: workoutCreate.Calories,
+	}
+
+	if err := s.ApplyCalories(&workout, workoutCreate.Calories); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	workoutID, err := s.WorkoutRepo.SaveWorkout(workout)
@@ -55,31 +77,51 @@ func (s *WorkoutService) CreateWorkout(userID int, workoutCreate models.RequestC
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// the workout is already saved, so a failed evaluation only leaves targets unchanged
+	if programID != nil && s.Progression != nil{
+		if err := s.Progression.Evaluate(userID, *programID, workoutID, exercisesEntryToSave); err != nil{
+			log.Printf("warning: failed to evaluate progression for workout %d: %v", workoutID, err)
+		}
+	}
+	if programID != nil && s.Schedule != nil{
+		if err := s.Schedule.CompleteSession(userID, *programID, workoutID); err != nil{
+			log.Printf("warning: failed to complete scheduled session for workout %d: %v", workoutID, err)
+		}
+	}
+
 	return workoutID, nil
 }
 
-func (s *WorkoutService) UpdateWorkout(workoutID int, userID int, workoutUpdate models.RequestCreateWorkout) (int, error){
+func (s *WorkoutService) UpdateWorkout(workoutID int, userID int, workoutUpdate models.RequestCreateWorkout, unit units.System) (int, error){
 	const op = "internal.servises.UpdateWorkout"
 
 	var workout models.Workout
 
-	programID, err := s.WorkoutRepo.GetProgramIdByName(workoutUpdate.ProgramName)
+	programID, err := s.resolveProgramID(userID, workoutUpdate.ProgramID, workoutUpdate.ProgramName)
 	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err := s.WorkoutRepo.DeleteWorkoutExercises(workoutID); err != nil{
+	nameToID, err := s.GetNameToID(workoutUpdate.Exercises)
+	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	nameToID, err := s.GetNameToID(workoutUpdate.Exercises)
+	exercisesEntryToSave, notFound, err := s.MapToDBExercisesEntry(workoutUpdate.Exercises, nameToID, unit)
 	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
-
-	exercisesEntryToSave, notFound := s.MapToDBExercisesEntry(workoutUpdate.Exercises, nameToID)
 	if len(notFound) > 0 {
-		return 0, fmt.Errorf("%s: some exercises not found: %w", op, err)
+		return 0, fmt.Errorf("%s: some exercises not found: %v", op, notFound)
+	}
+
+	if err := s.ValidateExercises(exercisesEntryToSave); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	groups, err := buildExerciseGroups(workoutUpdate.Groups, entryGroupLabels(exercisesEntryToSave))
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	duration, err := time.ParseDuration(workoutUpdate.Duration)
@@ -87,12 +129,21 @@ func (s *WorkoutService) UpdateWorkout(workoutID int, userID int, workoutUpdate
 		return 0, fmt.Errorf("%s: Invalid duration format: %w", op, err)
 	}
 
+	// the request is fully validated before anything of the workout changes
+	if _, err := s.WorkoutRepo.GetWorkoutByID(workoutID, userID); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
 	workout = models.Workout{
 		UserID: userID,
 		ProgramID: programID,
 		Exercises: exercisesEntryToSave,
+		ExerciseGroups: groups,
 		Duration: duration,
-		Calories: workoutUpdate.Calories,
+	}
+
+	if err := s.ApplyCalories(&workout, workoutUpdate.Calories); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	updatedID, err := s.WorkoutRepo.UpdateWorkout(workout, workoutID)
@@ -104,7 +155,7 @@ func (s *WorkoutService) UpdateWorkout(workoutID int, userID int, workoutUpdate
 }
 
 
-func (s *WorkoutService) GetWorkout(workoutID int, userID int) (*models.RequestGetWorkout, error){
+func (s *WorkoutService) GetWorkout(workoutID int, userID int, unit units.System) (*models.RequestGetWorkout, error){
 	const op = "internal.servises.workout_service.GetWorkout"
 
 	workoutDB, err := s.WorkoutRepo.GetWorkoutByID(workoutID, userID) 
@@ -117,53 +168,258 @@ func (s *WorkoutService) GetWorkout(workoutID int, userID int) (*models.RequestG
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	workout, err := s.BuildResponseWorkout(*workoutDB)
+	workout, err := s.BuildResponseWorkout(*workoutDB, unit)
 	if err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return workout, nil
 }
 
-func (s *WorkoutService) BuildResponseWorkout(workoutDB models.Workout) (*models.RequestGetWorkout, error){
+func (s *WorkoutService) BuildResponseWorkout(workoutDB models.Workout, unit units.System) (*models.RequestGetWorkout, error){
 	const op = "internal.servises.BuildResponseWorkout"
 	IdToName, err := s.GetIdToName(workoutDB.Exercises)
 	if err != nil{
 		return nil, fmt.Errorf("%s: failed to build response: %w", op, err)
 	}
 	fmt.Println(IdToName)
-	exercises, notFound := s.MapToResponseExercises(workoutDB.Exercises, IdToName)
+	exercises, notFound := s.MapToResponseExercises(workoutDB.Exercises, IdToName, unit)
 	if len(notFound) > 0{
 		return nil, fmt.Errorf("%s: some exercises not found: %v", op, notFound)
 	}
+	var programVersion *int
+	if workoutDB.ProgramVersionID != nil{
+		version, err := s.WorkoutRepo.GetProgramVersionNumber(*workoutDB.ProgramVersionID)
+		if err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		programVersion = &version
+	}
 	workout := models.RequestGetWorkout{
 		ID: workoutDB.ID,
 		UserID: workoutDB.UserID,
 		ProgramID: workoutDB.ProgramID,
+		ProgramVersion: programVersion,
 		Date: workoutDB.Date,
 		Exercises: exercises,
+		Groups: workoutDB.ExerciseGroups,
 		Duration: workoutDB.Duration.String(),
 		Calories: workoutDB.Calories,
+		CaloriesEstimated: workoutDB.CaloriesEstimated,
+		CaloriesOverride: workoutDB.CaloriesOverride,
+		Unit: unit.WeightUnit(),
 		CreatedAt: workoutDB.CreatedAt,
 	}
 	return &workout, nil
 }
 
-func (s *WorkoutService) DeleteWorkout(workoutID int, userID int) (int, error){
-	const op = "internal.servises.DeleteWorkout"
+// ApplyCalories stores the server estimate next to the user's override. The
+// effective Calories value is the override when given, otherwise the estimate.
+func (s *WorkoutService) ApplyCalories(workout *models.Workout, override *float64) error{
+	const op = "internal.servises.ApplyCalories"
 
-	deletedWorkoutId, err := s.WorkoutRepo.DeleteWorkout(workoutID, userID)
+	user, err := s.UserRepo.GetUserByID(workout.UserID)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	exercises, err := s.GetExercisesForEntries(workout.Exercises)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// cardio entries are weighted by their minutes, strength entries by their sets
+	samples := make([]metSample, 0, len(workout.Exercises))
+	for _, ex := range workout.Exercises{
+		weight := float64(max(ex.Sets, 1))
+		if ex.DurationSeconds != nil{
+			weight = float64(*ex.DurationSeconds) / 60
+		}
+		samples = append(samples, metSample{met: metForType(exercises[ex.ExerciseID].Type), weight: weight})
+	}
+
+	workout.CaloriesEstimated = nil
+	if estimate, ok := EstimateCalories(*user, samples, workout.Duration); ok{
+		workout.CaloriesEstimated = &estimate
+		workout.Calories = estimate
+	}
+
+	workout.CaloriesOverride = override
+	if override != nil{
+		if *override < 0{
+			return fmt.Errorf("%s: calories can not be negative", op)
+		}
+		workout.Calories = *override
+	}
+
+	return nil
+}
+
+// resolveProgramID returns nil for workouts logged without a program. Only
+// the user's own programs are considered; a name shared by several of them
+// must be disambiguated with the program id.
+func (s *WorkoutService) resolveProgramID(userID int, programID *int, programName string) (*int, error){
+	if programID != nil{
+		owned, err := s.WorkoutRepo.ProgramBelongsToUser(*programID, userID)
+		if err != nil{
+			return nil, err
+		}
+		if !owned{
+			return nil, fmt.Errorf("program %d not found", *programID)
+		}
+		return programID, nil
+	}
+	if programName == ""{
+		return nil, nil
+	}
+
+	programIDs, err := s.WorkoutRepo.GetProgramIDsByName(programName, userID)
+	if err != nil{
+		return nil, err
+	}
+	switch len(programIDs){
+	case 0:
+		return nil, fmt.Errorf("program %q not found", programName)
+	case 1:
+		return &programIDs[0], nil
+	}
+	return nil, fmt.Errorf("program name %q matches %d of your programs (ids %v); pass program_id instead",
+		programName, len(programIDs), programIDs)
+}
+
+// ValidateExercises checks every entry against the type of its exercise.
+func (s *WorkoutService) ValidateExercises(entries []models.ExerciseEntry) error{
+	const op = "internal.servises.ValidateExercises"
+
+	exercises, err := s.GetExercisesForEntries(entries)
+	if err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, ex := range entries{
+		exercise := exercises[ex.ExerciseID]
+		if err := validateExerciseEntry(exercise.Name, ex, exercise.Type); err != nil{
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+func (s *WorkoutService) GetExercisesForEntries(entries []models.ExerciseEntry) (map[int]models.Exercise, error){
+	const op = "internal.servises.GetExercisesForEntries"
+
+	idSlice := make([]int, 0, len(entries))
+	for _, ex := range entries{
+		idSlice = append(idSlice, ex.ExerciseID)
+	}
+	found, err := s.WorkoutRepo.GetExercisesByID(idSlice)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	byID := make(map[int]models.Exercise, len(found))
+	for _, ex := range found{
+		byID[ex.ID] = ex
+	}
+	return byID, nil
+}
+
+// GetStats aggregates the cardio entries of workouts between from and to.
+func (s *WorkoutService) GetStats(userID int, from, to time.Time, unit units.System) (*models.ResponseWorkoutStats, error){
+	const op = "internal.servises.GetStats"
+
+	entries, err := s.WorkoutRepo.GetCardioEntries(userID, from, to)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	stats := BuildWorkoutStats(entries, unit)
+	return &stats, nil
+}
+
+// SwapExercise replaces an exercise of a workout, keeping the logged sets.
+// Each entry remembers the exercise it was planned with, so the workout
+// still lines up with its program. The calorie estimate follows the new
+// exercise types; a user override stays the effective value.
+func (s *WorkoutService) SwapExercise(userID int, workoutID int, req models.RequestSwapWorkoutExercise) (int, error){
+	const op = "internal.servises.SwapWorkoutExercise"
+
+	workout, err := s.WorkoutRepo.GetWorkoutByID(workoutID, userID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	entries, err := s.WorkoutRepo.GetExercsisesWorkout(workoutID)
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	exercise, replacement := strings.TrimSpace(req.Exercise), strings.TrimSpace(req.Replacement)
+	if exercise == "" || replacement == ""{
+		return 0, fmt.Errorf("%s: exercise and replacement are required", op)
+	}
+	if exercise == replacement{
+		return 0, fmt.Errorf("%s: the replacement must be a different exercise", op)
+	}
+	nameToID, err := s.GetNameToID([]models.ExerciseRequestEntry{{Name: exercise}, {Name: replacement}})
 	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	for _, name := range []string{exercise, replacement}{
+		if _, ok := nameToID[name]; !ok{
+			return 0, fmt.Errorf("%s: exercise %q not found", op, name)
+		}
+	}
+	fromID, toID := nameToID[exercise], nameToID[replacement]
 
-	if err := s.WorkoutRepo.DeleteWorkoutExercises(workoutID); err != nil{
+	types, err := s.GetExercisesForEntries([]models.ExerciseEntry{{ExerciseID: fromID}, {ExerciseID: toID}})
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if (types[fromID].Type == models.ExerciseTypeCardio) != (types[toID].Type == models.ExerciseTypeCardio){
+		return 0, fmt.Errorf("%s: cardio and strength exercises can not replace each other", op)
+	}
+
+	var swapped []models.ExerciseEntry
+	for i, entry := range entries{
+		if entry.ExerciseID != fromID || (req.Position != 0 && entry.Position != req.Position){
+			continue
+		}
+		entry.SubstitutedForID = substitutionLink(entry.SubstitutedForID, fromID, toID)
+		entry.ExerciseID = toID
+		entries[i] = entry
+		swapped = append(swapped, entry)
+	}
+	if len(swapped) == 0{
+		return 0, fmt.Errorf("%s: %q is not in the workout", op, exercise)
+	}
+
+	workout.Exercises = entries
+	if err := s.ApplyCalories(workout, workout.CaloriesOverride); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.WorkoutRepo.SwapEntryExercises(*workout, swapped); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return len(swapped), nil
+}
+
+func (s *WorkoutService) DeleteWorkout(workoutID int, userID int) (int, error){
+	const op = "internal.servises.DeleteWorkout"
+
+	if s.Schedule != nil{
+		if err := s.Schedule.ReopenSession(userID, workoutID); err != nil{
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// the entries are removed with the workout by ON DELETE CASCADE
+	deletedWorkoutId, err := s.WorkoutRepo.DeleteWorkout(workoutID, userID)
+	if err != nil{
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 	return deletedWorkoutId, nil
 }
 
 
-func (s *WorkoutService) MapToResponseExercises(dbEx []models.ExerciseEntry, idToName map[int]string) ([]models.ExerciseRequestEntry, []int) {
+func (s *WorkoutService) MapToResponseExercises(dbEx []models.ExerciseEntry, idToName map[int]string, unit units.System) ([]models.ExerciseRequestEntry, []int) {
     var result []models.ExerciseRequestEntry
     var notFound []int
 
@@ -179,15 +435,23 @@ func (s *WorkoutService) MapToResponseExercises(dbEx []models.ExerciseEntry, idT
             reps[i] = int(v) 
         }
 
-        weight := make([]float64, len(ex.Weight))
-        copy(weight, ex.Weight)
+        weight := units.FromKilogramsSlice(ex.Weight, unit)
 
-        result = append(result, models.ExerciseRequestEntry{
+        entry := models.ExerciseRequestEntry{
             Name:       name,
             Sets:       ex.Sets,
             Reps:       reps,
             Weight:     weight,
-        })
+        }
+        if ex.Group != nil {
+            entry.Group = *ex.Group
+        }
+        if ex.SubstitutedForID != nil {
+            entry.SubstitutedFor = idToName[*ex.SubstitutedForID]
+        }
+        mapCardioToResponse(ex, &entry, unit)
+
+        result = append(result, entry)
     }
 
     return result, notFound
@@ -201,6 +465,9 @@ func (s *WorkoutService) GetIdToName(exercises []models.ExerciseEntry) (map[int]
 
 	for _, exercise := range exercises{
 		idSlice = append(idSlice, exercise.ExerciseID)
+		if exercise.SubstitutedForID != nil{
+			idSlice = append(idSlice, *exercise.SubstitutedForID)
+		}
 	}
 
 	found, err := s.WorkoutRepo.GetExercisesByID(idSlice) 
@@ -223,6 +490,9 @@ func (s *WorkoutService) GetNameToID(exercises []models.ExerciseRequestEntry) (m
 
 	for _, exercise := range exercises{
 		names = append(names, exercise.Name)
+		if exercise.SubstitutedFor != ""{
+			names = append(names, exercise.SubstitutedFor)
+		}
 	}
 
 	found, err := s.WorkoutRepo.GetExercisesByNames(names)
@@ -239,7 +509,7 @@ func (s *WorkoutService) GetNameToID(exercises []models.ExerciseRequestEntry) (m
 	return exerciseMap, nil
 }
 
-func (s *WorkoutService) MapToDBExercisesEntry(regEx []models.ExerciseRequestEntry, nameToDB map[string]int) ([]models.ExerciseEntry, []string) {
+func (s *WorkoutService) MapToDBExercisesEntry(regEx []models.ExerciseRequestEntry, nameToDB map[string]int, unit units.System) ([]models.ExerciseEntry, []string, error) {
     var result []models.ExerciseEntry
     var notFound []string
 
@@ -255,16 +525,42 @@ func (s *WorkoutService) MapToDBExercisesEntry(regEx []models.ExerciseRequestEnt
             reps[i] = int64(v) 
         }
 
-        weight := make(pq.Float64Array, len(ex.Weight))
-        copy(weight, ex.Weight)  
+        weight := pq.Float64Array(units.ToKilogramsSlice(ex.Weight, unit))
 
-        result = append(result, models.ExerciseEntry{
+        entry := models.ExerciseEntry{
             ExerciseID: id,
             Sets:       ex.Sets,
             Reps:       reps,
             Weight:     weight,
-        })
+            Group:      groupLabel(ex.Group),
+        }
+        if ex.SubstitutedFor != "" {
+            originalID, ok := nameToDB[ex.SubstitutedFor]
+            if !ok {
+                notFound = append(notFound, ex.SubstitutedFor)
+                continue
+            }
+            entry.SubstitutedForID = &originalID
+        }
+        if err := mapCardioToDB(ex, &entry, unit); err != nil {
+            return nil, nil, err
+        }
+
+        result = append(result, entry)
     }
 
-    return result, notFound
-}
\ No newline at end of file
+    return result, notFound, nil
+}
+
+// entryGroupLabels lists the group label of each entry in order.
+func entryGroupLabels(entries []models.ExerciseEntry) []string{
+	labels := make([]string, 0, len(entries))
+	for _, entry := range entries{
+		label := ""
+		if entry.Group != nil{
+			label = *entry.Group
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
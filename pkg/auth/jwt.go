@@ -10,18 +10,69 @@ import (
 
 var jwtSecret = []byte(os.Getenv("JWT_KEY"))
 
-func GenerateJWT(email string) (string, error) {
-	claims := &jwt.RegisteredClaims{
-		Subject:   email,
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(72 * time.Hour)),
+const challengeAudience = "2fa-challenge"
+
+// Claims carries the user's token version. Bumping users.token_version
+// invalidates every token issued before the change.
+type Claims struct {
+	TokenVersion int `json:"ver"`
+	jwt.RegisteredClaims
+}
+
+func GenerateJWT(email string, tokenVersion int) (string, error) {
+	claims := &Claims{
+		TokenVersion: tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   email,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(72 * time.Hour)),
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
 
-func VerifyJWT(tokenStr string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+// GenerateChallengeJWT issues a short-lived token proving that the password step
+// of a two-factor login succeeded. It is not accepted by VerifyJWT.
+func GenerateChallengeJWT(email string) (string, error) {
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   email,
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+func VerifyJWT(tokenStr string) (*Claims, error) {
+	claims, err := parseJWT(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Audience) > 0 {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return claims, nil
+}
+
+func VerifyChallengeJWT(tokenStr string) (*Claims, error) {
+	claims, err := parseJWT(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	for _, aud := range claims.Audience {
+		if aud == challengeAudience {
+			return claims, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid challenge token")
+}
+
+func parseJWT(tokenStr string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method")
 		}
@@ -31,7 +82,7 @@ func VerifyJWT(tokenStr string) (*jwt.RegisteredClaims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		return claims, nil
 	}
 
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -18,4 +19,13 @@ func HashPassword(pswd string) (string, error) {
 func CheckPassword(pswd, hash string) (bool){
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(pswd))
 	return err == nil
-}
\ No newline at end of file
+}
+
+const minPasswordLength = 8
+
+func ValidatePassword(pswd string) error{
+	if utf8.RuneCountInString(pswd) < minPasswordLength{
+		return fmt.Errorf("password must be at least %d characters long", minPasswordLength)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+func GenerateRandomToken(size int) (string, error){
+	const op = "auth.token.GenerateRandomToken"
+
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil{
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken is used for high-entropy secrets (recovery codes, one-time links),
+// where a fast hash is enough and lookups by hash must be possible.
+func HashToken(token string) string{
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func GenerateRecoveryCodes(count int) ([]string, error){
+	const op = "auth.token.GenerateRecoveryCodes"
+
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++{
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+func NormalizeRecoveryCode(code string) string{
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) == 10{
+		code = code[:5] + "-" + code[5:]
+	}
+	return code
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 SHA1 test key "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPCodeRFCVectors(t *testing.T){
+	tests := []struct{
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests{
+		code, err := GenerateTOTPCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil{
+			t.Fatalf("GenerateTOTPCode(%d): %v", tt.unix, err)
+		}
+		if code != tt.code{
+			t.Errorf("GenerateTOTPCode(%d) = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T){
+	now := time.Unix(1111111109, 0)
+	step := now.Unix() / totpPeriod
+	codeAt := func(t2 time.Time) string{
+		code, err := GenerateTOTPCode(rfcSecret, t2)
+		if err != nil{
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct{
+		name     string
+		code     string
+		secret   string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current period", codeAt(now), rfcSecret, 0, step, true},
+		{"previous period", codeAt(now.Add(-totpPeriod * time.Second)), rfcSecret, 0, step - 1, true},
+		{"next period", codeAt(now.Add(totpPeriod * time.Second)), rfcSecret, 0, step + 1, true},
+		{"two periods old", codeAt(now.Add(-2 * totpPeriod * time.Second)), rfcSecret, 0, 0, false},
+		{"wrong code", "000000", rfcSecret, 0, 0, false},
+		{"short code", "12345", rfcSecret, 0, 0, false},
+		{"invalid secret", codeAt(now), "not base32!", 0, 0, false},
+		{"replayed code", codeAt(now), rfcSecret, step, 0, false},
+		{"code older than last use", codeAt(now.Add(-totpPeriod * time.Second)), rfcSecret, step, 0, false},
+		{"code newer than last use", codeAt(now.Add(totpPeriod * time.Second)), rfcSecret, step, step + 1, true},
+	}
+	for _, tt := range tests{
+		t.Run(tt.name, func(t *testing.T){
+			gotStep, ok := ValidateTOTP(tt.code, tt.secret, tt.lastStep, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep{
+				t.Errorf("ValidateTOTP() = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+const csvFlushEvery = 500
+
+// csvWriter puts every section into one table: a record_type column naming
+// the section followed by the union of all columns in declaration order.
+type csvWriter struct {
+	w        *csv.Writer
+	sections []Section
+	columns  []string
+	// positions maps each section's columns onto the union.
+	positions [][]int
+	current   int
+	started   bool
+	rows      int
+}
+
+func newCSVWriter(w io.Writer, sections []Section) *csvWriter{
+	cw := &csvWriter{w: csv.NewWriter(w), sections: sections, columns: []string{"record_type"}, current: -1}
+
+	index := map[string]int{}
+	for _, s := range sections{
+		var pos []int
+		for _, col := range s.Columns{
+			i, ok := index[col]
+			if !ok{
+				i = len(cw.columns)
+				index[col] = i
+				cw.columns = append(cw.columns, col)
+			}
+			pos = append(pos, i)
+		}
+		cw.positions = append(cw.positions, pos)
+	}
+	return cw
+}
+
+func (cw *csvWriter) BeginSection(name string) error{
+	i, err := findSection(cw.sections, name)
+	if err != nil{
+		return err
+	}
+	cw.current = i
+	if !cw.started{
+		cw.started = true
+		return cw.w.Write(cw.columns)
+	}
+	return nil
+}
+
+func (cw *csvWriter) WriteRow(values []interface{}) error{
+	if cw.current < 0{
+		return fmt.Errorf("row written before a section was started")
+	}
+	pos := cw.positions[cw.current]
+	if len(values) != len(pos){
+		return fmt.Errorf("section %s expects %d values, got %d", cw.sections[cw.current].Name, len(pos), len(values))
+	}
+
+	record := make([]string, len(cw.columns))
+	record[0] = cw.sections[cw.current].Name
+	for i, v := range values{
+		record[pos[i]] = formatValue(v)
+	}
+	if err := cw.w.Write(record); err != nil{
+		return err
+	}
+
+	cw.rows++
+	if cw.rows%csvFlushEvery == 0{
+		cw.w.Flush()
+		return cw.w.Error()
+	}
+	return nil
+}
+
+func (cw *csvWriter) Close() error{
+	if !cw.started{
+		if err := cw.w.Write(cw.columns); err != nil{
+			return err
+		}
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
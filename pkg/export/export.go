@@ -0,0 +1,91 @@
+// Package export writes tabular data as CSV, JSON or XLSX while it is being
+// produced, so large exports never have to be held in memory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+)
+
+// Section is a named table. Every writer gets all sections up front so the
+// layout is fixed before the first row is written.
+type Section struct {
+	Name    string
+	Columns []string
+}
+
+// Writer receives the sections in the declared order. Row values are nil,
+// string, int or float64 and must match the section's columns.
+type Writer interface {
+	BeginSection(name string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// ValidateFormat lets callers reject a format before they start a response.
+func ValidateFormat(format string) error{
+	switch format{
+	case FormatCSV, FormatJSON, FormatXLSX:
+		return nil
+	default:
+		return fmt.Errorf("unsupported export format %q, expected csv, json or xlsx", format)
+	}
+}
+
+func NewWriter(format string, w io.Writer, sections []Section) (Writer, error){
+	if err := ValidateFormat(format); err != nil{
+		return nil, err
+	}
+	switch format{
+	case FormatCSV:
+		return newCSVWriter(w, sections), nil
+	case FormatJSON:
+		return newJSONWriter(w, sections), nil
+	default:
+		return newXLSXWriter(w, sections), nil
+	}
+}
+
+func ContentType(format string) string{
+	switch format{
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func findSection(sections []Section, name string) (int, error){
+	for i, s := range sections{
+		if s.Name == name{
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown export section %q", name)
+}
+
+func formatValue(v interface{}) string{
+	switch val := v.(type){
+	case nil:
+		return ""
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(val)
+	}
+}
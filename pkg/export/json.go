@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonWriter produces {"section": [{"column": value, ...}, ...], ...} with
+// keys in column order. Sections that were never started are written as
+// empty arrays so the document always has the same keys.
+type jsonWriter struct {
+	w        *bufio.Writer
+	sections []Section
+	done     []bool
+	current  int
+	rowCount int
+	started  bool
+}
+
+func newJSONWriter(w io.Writer, sections []Section) *jsonWriter{
+	return &jsonWriter{w: bufio.NewWriter(w), sections: sections, done: make([]bool, len(sections)), current: -1}
+}
+
+func (jw *jsonWriter) BeginSection(name string) error{
+	i, err := findSection(jw.sections, name)
+	if err != nil{
+		return err
+	}
+	if err := jw.endSection(); err != nil{
+		return err
+	}
+	return jw.startSection(i)
+}
+
+func (jw *jsonWriter) startSection(i int) error{
+	prefix := ","
+	if !jw.started{
+		prefix = "{"
+		jw.started = true
+	}
+	key, _ := json.Marshal(jw.sections[i].Name)
+	if _, err := fmt.Fprintf(jw.w, "%s%s:[", prefix, key); err != nil{
+		return err
+	}
+	jw.current = i
+	jw.done[i] = true
+	jw.rowCount = 0
+	return nil
+}
+
+func (jw *jsonWriter) endSection() error{
+	if jw.current < 0{
+		return nil
+	}
+	jw.current = -1
+	_, err := jw.w.WriteString("]")
+	return err
+}
+
+func (jw *jsonWriter) WriteRow(values []interface{}) error{
+	if jw.current < 0{
+		return fmt.Errorf("row written before a section was started")
+	}
+	columns := jw.sections[jw.current].Columns
+	if len(values) != len(columns){
+		return fmt.Errorf("section %s expects %d values, got %d", jw.sections[jw.current].Name, len(columns), len(values))
+	}
+
+	if jw.rowCount > 0{
+		if err := jw.w.WriteByte(','); err != nil{
+			return err
+		}
+	}
+	jw.rowCount++
+
+	if err := jw.w.WriteByte('{'); err != nil{
+		return err
+	}
+	for i, col := range columns{
+		key, _ := json.Marshal(col)
+		value, err := json.Marshal(values[i])
+		if err != nil{
+			return err
+		}
+		sep := ","
+		if i == 0{
+			sep = ""
+		}
+		if _, err := fmt.Fprintf(jw.w, "%s%s:%s", sep, key, value); err != nil{
+			return err
+		}
+	}
+	return jw.w.WriteByte('}')
+}
+
+func (jw *jsonWriter) Close() error{
+	if err := jw.endSection(); err != nil{
+		return err
+	}
+	for i, done := range jw.done{
+		if !done{
+			if err := jw.startSection(i); err != nil{
+				return err
+			}
+			if err := jw.endSection(); err != nil{
+				return err
+			}
+		}
+	}
+	if !jw.started{
+		if _, err := jw.w.WriteString("{"); err != nil{
+			return err
+		}
+	}
+	if _, err := jw.w.WriteString("}\n"); err != nil{
+		return err
+	}
+	return jw.w.Flush()
+}
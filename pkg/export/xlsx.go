@@ -0,0 +1,170 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xlsxWriter streams one worksheet per section using inline strings, so no
+// shared string table has to be built up in memory. The workbook parts that
+// list the sheets are written last.
+type xlsxWriter struct {
+	zw       *zip.Writer
+	sheet    *bufio.Writer
+	sections []Section
+	written  []int
+	current  int
+	row      int
+}
+
+func newXLSXWriter(w io.Writer, sections []Section) *xlsxWriter{
+	return &xlsxWriter{zw: zip.NewWriter(w), sections: sections, current: -1}
+}
+
+func (xw *xlsxWriter) BeginSection(name string) error{
+	i, err := findSection(xw.sections, name)
+	if err != nil{
+		return err
+	}
+	if err := xw.endSheet(); err != nil{
+		return err
+	}
+	return xw.startSheet(i)
+}
+
+func (xw *xlsxWriter) startSheet(i int) error{
+	w, err := xw.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(xw.written)+1))
+	if err != nil{
+		return err
+	}
+	xw.sheet = bufio.NewWriter(w)
+	xw.current = i
+	xw.written = append(xw.written, i)
+	xw.row = 0
+
+	if _, err := xw.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil{
+		return err
+	}
+
+	header := make([]interface{}, len(xw.sections[i].Columns))
+	for j, col := range xw.sections[i].Columns{
+		header[j] = col
+	}
+	return xw.writeCells(header)
+}
+
+func (xw *xlsxWriter) endSheet() error{
+	if xw.current < 0{
+		return nil
+	}
+	xw.current = -1
+	if _, err := xw.sheet.WriteString(`</sheetData></worksheet>`); err != nil{
+		return err
+	}
+	return xw.sheet.Flush()
+}
+
+func (xw *xlsxWriter) WriteRow(values []interface{}) error{
+	if xw.current < 0{
+		return fmt.Errorf("row written before a section was started")
+	}
+	columns := xw.sections[xw.current].Columns
+	if len(values) != len(columns){
+		return fmt.Errorf("section %s expects %d values, got %d", xw.sections[xw.current].Name, len(columns), len(values))
+	}
+	return xw.writeCells(values)
+}
+
+func (xw *xlsxWriter) writeCells(values []interface{}) error{
+	xw.row++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, xw.row)
+	for i, v := range values{
+		ref := columnName(i) + strconv.Itoa(xw.row)
+		switch val := v.(type){
+		case nil:
+			continue
+		case int, float64:
+			fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, formatValue(val))
+		default:
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(&b, []byte(formatValue(val))); err != nil{
+				return err
+			}
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString(`</row>`)
+	_, err := xw.sheet.WriteString(b.String())
+	return err
+}
+
+// Close adds empty sheets for sections without rows, then the workbook parts.
+func (xw *xlsxWriter) Close() error{
+	if err := xw.endSheet(); err != nil{
+		return err
+	}
+	for i := range xw.sections{
+		started := false
+		for _, w := range xw.written{
+			if w == i{
+				started = true
+			}
+		}
+		if !started{
+			if err := xw.startSheet(i); err != nil{
+				return err
+			}
+			if err := xw.endSheet(); err != nil{
+				return err
+			}
+		}
+	}
+
+	var types, sheets, rels strings.Builder
+	for n, i := range xw.written{
+		id := n + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, id)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xw.sections[i].Name, id, id)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, id, id)
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+	}
+	for _, p := range parts{
+		w, err := xw.zw.Create(p.name)
+		if err != nil{
+			return err
+		}
+		if _, err := io.WriteString(w, p.content); err != nil{
+			return err
+		}
+	}
+	return xw.zw.Close()
+}
+
+// columnName turns a zero-based index into A, B, ..., Z, AA, AB, ...
+func columnName(i int) string{
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26{
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
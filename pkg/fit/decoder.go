@@ -0,0 +1,326 @@
+// Package fit decodes Garmin FIT activity files. Only the messages needed to
+// import workouts are interpreted; everything else is skipped.
+package fit
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// fitEpoch is 1989-12-31T00:00:00Z, the zero point of FIT timestamps.
+const fitEpoch = 631065600
+
+const (
+	mesgSession = 18
+	mesgLap     = 19
+	mesgRecord  = 20
+	mesgSet     = 225
+
+	fieldTimestamp = 253
+)
+
+var (
+	ErrNotFIT  = errors.New("not a FIT file")
+	ErrCorrupt = errors.New("corrupt FIT file")
+)
+
+// baseTypeSizes is indexed by the base type number (the low five bits).
+var baseTypeSizes = [...]int{1, 1, 1, 2, 2, 4, 4, 1, 4, 8, 1, 2, 4, 1, 8, 8, 8}
+
+type fieldDef struct {
+	num      byte
+	size     int
+	baseType byte
+}
+
+type definition struct {
+	global    uint16
+	order     binary.ByteOrder
+	fields    []fieldDef
+	devSize   int
+}
+
+type rawField struct {
+	baseType byte
+	data     []byte
+}
+
+type message struct {
+	num    uint16
+	order  binary.ByteOrder
+	fields map[byte]rawField
+}
+
+// Decode checks the header and CRC and returns the session, lap, record and
+// set messages of the file.
+func Decode(data []byte) (*File, error){
+	const op = "fit.Decode"
+
+	if len(data) < 12 || string(data[8:12]) != ".FIT"{
+		return nil, fmt.Errorf("%s: %w", op, ErrNotFIT)
+	}
+	headerSize := int(data[0])
+	if headerSize < 12 || len(data) < headerSize{
+		return nil, fmt.Errorf("%s: %w: bad header size", op, ErrCorrupt)
+	}
+	dataSize := int(binary.LittleEndian.Uint32(data[4:8]))
+	end := headerSize + dataSize
+	if end > len(data){
+		return nil, fmt.Errorf("%s: %w: truncated file", op, ErrCorrupt)
+	}
+	if len(data) >= end+2{
+		if expected := binary.LittleEndian.Uint16(data[end:end+2]); expected != 0 && crc16(data[:end]) != expected{
+			return nil, fmt.Errorf("%s: %w: checksum mismatch", op, ErrCorrupt)
+		}
+	}
+
+	messages, err := readMessages(data[headerSize:end])
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	file := &File{}
+	for _, m := range messages{
+		switch m.num{
+		case mesgSession:
+			file.Sessions = append(file.Sessions, newSession(m))
+		case mesgLap:
+			file.Laps = append(file.Laps, newLap(m))
+		case mesgRecord:
+			file.Records = append(file.Records, newRecord(m))
+		case mesgSet:
+			file.Sets = append(file.Sets, newSet(m))
+		}
+	}
+	return file, nil
+}
+
+func readMessages(buf []byte) ([]message, error){
+	defs := make(map[byte]*definition)
+	var messages []message
+	var lastTimestamp uint32
+
+	pos := 0
+	for pos < len(buf){
+		header := buf[pos]
+		pos++
+
+		switch{
+		case header&0x80 != 0:
+			// compressed timestamp header: bits 5-6 local type, bits 0-4 time offset
+			local := (header >> 5) & 0x03
+			def, ok := defs[local]
+			if !ok{
+				return nil, fmt.Errorf("%w: data for undefined local message %d", ErrCorrupt, local)
+			}
+			offset := uint32(header & 0x1f)
+			timestamp := (lastTimestamp &^ 0x1f) + offset
+			if offset < lastTimestamp&0x1f{
+				timestamp += 0x20
+			}
+			m, n, err := readData(buf[pos:], def)
+			if err != nil{
+				return nil, err
+			}
+			pos += n
+			ts := make([]byte, 4)
+			def.order.PutUint32(ts, timestamp)
+			m.fields[fieldTimestamp] = rawField{baseType: 0x86, data: ts}
+			lastTimestamp = timestamp
+			messages = append(messages, m)
+
+		case header&0x40 != 0:
+			def, n, err := readDefinition(buf[pos:], header&0x20 != 0)
+			if err != nil{
+				return nil, err
+			}
+			pos += n
+			defs[header&0x0f] = def
+
+		default:
+			def, ok := defs[header&0x0f]
+			if !ok{
+				return nil, fmt.Errorf("%w: data for undefined local message %d", ErrCorrupt, header&0x0f)
+			}
+			m, n, err := readData(buf[pos:], def)
+			if err != nil{
+				return nil, err
+			}
+			pos += n
+			if ts, ok := m.uint(fieldTimestamp); ok{
+				lastTimestamp = uint32(ts)
+			}
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+func readDefinition(buf []byte, developer bool) (*definition, int, error){
+	if len(buf) < 5{
+		return nil, 0, fmt.Errorf("%w: truncated definition", ErrCorrupt)
+	}
+	def := &definition{order: binary.LittleEndian}
+	if buf[1] == 1{
+		def.order = binary.BigEndian
+	}
+	def.global = def.order.Uint16(buf[2:4])
+	count := int(buf[4])
+	pos := 5
+
+	if len(buf) < pos+count*3{
+		return nil, 0, fmt.Errorf("%w: truncated definition", ErrCorrupt)
+	}
+	for i := 0; i < count; i++{
+		def.fields = append(def.fields, fieldDef{num: buf[pos], size: int(buf[pos+1]), baseType: buf[pos+2]})
+		pos += 3
+	}
+
+	if developer{
+		if len(buf) < pos+1{
+			return nil, 0, fmt.Errorf("%w: truncated definition", ErrCorrupt)
+		}
+		devCount := int(buf[pos])
+		pos++
+		if len(buf) < pos+devCount*3{
+			return nil, 0, fmt.Errorf("%w: truncated definition", ErrCorrupt)
+		}
+		for i := 0; i < devCount; i++{
+			def.devSize += int(buf[pos+1])
+			pos += 3
+		}
+	}
+	return def, pos, nil
+}
+
+func readData(buf []byte, def *definition) (message, int, error){
+	m := message{num: def.global, order: def.order, fields: make(map[byte]rawField, len(def.fields))}
+	pos := 0
+	for _, f := range def.fields{
+		if len(buf) < pos+f.size{
+			return message{}, 0, fmt.Errorf("%w: truncated data message", ErrCorrupt)
+		}
+		m.fields[f.num] = rawField{baseType: f.baseType, data: buf[pos:pos+f.size]}
+		pos += f.size
+	}
+	if len(buf) < pos+def.devSize{
+		return message{}, 0, fmt.Errorf("%w: truncated data message", ErrCorrupt)
+	}
+	return m, pos + def.devSize, nil
+}
+
+// uints decodes every valid element of an integer field.
+func (m message) uints(num byte) []uint64{
+	f, ok := m.fields[num]
+	if !ok{
+		return nil
+	}
+	kind := int(f.baseType & 0x1f)
+	if kind >= len(baseTypeSizes) || kind == 7 || kind == 8 || kind == 9{
+		return nil
+	}
+	size := baseTypeSizes[kind]
+	var values []uint64
+	for pos := 0; pos+size <= len(f.data); pos += size{
+		raw := f.data[pos:pos+size]
+		var v uint64
+		switch size{
+		case 1:
+			v = uint64(raw[0])
+		case 2:
+			v = uint64(m.order.Uint16(raw))
+		case 4:
+			v = uint64(m.order.Uint32(raw))
+		case 8:
+			v = m.order.Uint64(raw)
+		}
+		if !validValue(kind, v, size){
+			continue
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func (m message) uint(num byte) (uint64, bool){
+	values := m.uints(num)
+	if len(values) == 0{
+		return 0, false
+	}
+	return values[0], true
+}
+
+func (m message) int(num byte) (int64, bool){
+	v, ok := m.uint(num)
+	if !ok{
+		return 0, false
+	}
+	switch baseTypeSizes[m.fields[num].baseType&0x1f]{
+	case 1:
+		return int64(int8(v)), true
+	case 2:
+		return int64(int16(v)), true
+	case 4:
+		return int64(int32(v)), true
+	}
+	return int64(v), true
+}
+
+// scaled applies the profile's scale and offset: value / scale - offset.
+func (m message) scaled(num byte, scale, offset float64) (float64, bool){
+	v, ok := m.uint(num)
+	if !ok{
+		return 0, false
+	}
+	return float64(v)/scale - offset, true
+}
+
+func (m message) time(num byte) (time.Time, bool){
+	v, ok := m.uint(num)
+	if !ok{
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v)+fitEpoch, 0).UTC(), true
+}
+
+// validValue filters the "invalid" sentinels: all bits set for unsigned
+// types, the maximum positive value for signed types and zero for the z types.
+func validValue(kind int, v uint64, size int) bool{
+	bits := uint(size * 8)
+	allSet := uint64(math.MaxUint64)
+	if bits < 64{
+		allSet = (uint64(1) << bits) - 1
+	}
+	switch kind{
+	case 1, 3, 5, 14:
+		return v != allSet>>1
+	case 10, 11, 12, 16:
+		return v != 0
+	case 13:
+		return true
+	default:
+		return v != allSet
+	}
+}
+
+var crcTable = [16]uint16{
+	0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+	0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+}
+
+func crc16(data []byte) uint16{
+	var crc uint16
+	for _, b := range data{
+		tmp := crcTable[crc&0xf]
+		crc = (crc >> 4) & 0x0fff
+		crc = crc ^ tmp ^ crcTable[b&0xf]
+
+		tmp = crcTable[crc&0xf]
+		crc = (crc >> 4) & 0x0fff
+		crc = crc ^ tmp ^ crcTable[(b>>4)&0xf]
+	}
+	return crc
+}
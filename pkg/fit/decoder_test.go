@@ -0,0 +1,238 @@
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Base types used by the test files.
+const (
+	typeEnum   = 0x00
+	typeUint8  = 0x02
+	typeSint32 = 0x85
+	typeUint16 = 0x84
+	typeUint32 = 0x86
+)
+
+// fitBuilder writes FIT records; bytes adds the header and the CRC.
+type fitBuilder struct {
+	records bytes.Buffer
+	orders  map[byte]binary.ByteOrder
+}
+
+type testField struct {
+	num      byte
+	size     byte
+	baseType byte
+}
+
+func newFITBuilder() *fitBuilder{
+	return &fitBuilder{orders: map[byte]binary.ByteOrder{}}
+}
+
+func (b *fitBuilder) define(local byte, global uint16, order binary.ByteOrder, fields ...testField){
+	b.records.WriteByte(0x40 | local)
+	b.records.WriteByte(0)
+	arch := byte(0)
+	if order == binary.BigEndian{
+		arch = 1
+	}
+	b.records.WriteByte(arch)
+	g := make([]byte, 2)
+	order.PutUint16(g, global)
+	b.records.Write(g)
+	b.records.WriteByte(byte(len(fields)))
+	for _, f := range fields{
+		b.records.Write([]byte{f.num, f.size, f.baseType})
+	}
+	b.orders[local] = order
+}
+
+// data writes a data message; values are uint8, uint16, uint32 or int32 and
+// must follow the definition's field order.
+func (b *fitBuilder) data(header byte, values ...interface{}){
+	b.records.WriteByte(header)
+	order := b.orders[header&0x0f]
+	if header&0x80 != 0{
+		order = b.orders[(header>>5)&0x03]
+	}
+	for _, v := range values{
+		switch v := v.(type){
+		case uint8:
+			b.records.WriteByte(v)
+		case uint16:
+			buf := make([]byte, 2)
+			order.PutUint16(buf, v)
+			b.records.Write(buf)
+		case uint32:
+			buf := make([]byte, 4)
+			order.PutUint32(buf, v)
+			b.records.Write(buf)
+		case int32:
+			buf := make([]byte, 4)
+			order.PutUint32(buf, uint32(v))
+			b.records.Write(buf)
+		}
+	}
+}
+
+func (b *fitBuilder) bytes() []byte{
+	header := make([]byte, 14)
+	header[0] = 14
+	header[1] = 0x10
+	binary.LittleEndian.PutUint32(header[4:8], uint32(b.records.Len()))
+	copy(header[8:12], ".FIT")
+
+	file := append(header, b.records.Bytes()...)
+	crc := make([]byte, 2)
+	binary.LittleEndian.PutUint16(crc, crc16(file))
+	return append(file, crc...)
+}
+
+func fitTime(t time.Time) uint32{
+	return uint32(t.Unix() - fitEpoch)
+}
+
+func TestDecodeActivity(t *testing.T){
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	b := newFITBuilder()
+	b.define(0, mesgSession, binary.LittleEndian,
+		testField{fieldTimestamp, 4, typeUint32}, testField{2, 4, typeUint32}, testField{5, 1, typeEnum},
+		testField{8, 4, typeUint32}, testField{9, 4, typeUint32}, testField{16, 1, typeUint8}, testField{17, 1, typeUint8})
+	b.data(0x00, fitTime(start.Add(10*time.Minute)), fitTime(start), uint8(1), uint32(600000), uint32(250000), uint8(150), uint8(0xff))
+
+	// records are big-endian to check the architecture flag
+	b.define(1, mesgRecord, binary.BigEndian,
+		testField{fieldTimestamp, 4, typeUint32}, testField{0, 4, typeSint32}, testField{1, 4, typeSint32},
+		testField{2, 2, typeUint16}, testField{5, 4, typeUint32}, testField{3, 1, typeUint8})
+	b.data(0x01, fitTime(start), int32(-1<<30), int32(1<<29), uint16((100+500)*5), uint32(0), uint8(140))
+
+	// a compressed timestamp record ten seconds later, without position
+	b.define(2, mesgRecord, binary.LittleEndian, testField{5, 4, typeUint32}, testField{3, 1, typeUint8})
+	offset := byte((fitTime(start) + 10) & 0x1f)
+	b.data(0x80|2<<5|offset, uint32(3050), uint8(0xff))
+
+	b.define(3, mesgLap, binary.LittleEndian,
+		testField{2, 4, typeUint32}, testField{8, 4, typeUint32}, testField{9, 4, typeUint32}, testField{15, 1, typeUint8})
+	b.data(0x03, fitTime(start), uint32(600000), uint32(250000), uint8(150))
+
+	file, err := Decode(b.bytes())
+	if err != nil{
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if len(file.Sessions) != 1{
+		t.Fatalf("got %d sessions, want 1", len(file.Sessions))
+	}
+	session := file.Sessions[0]
+	if session.Sport != "running" || !session.Start.Equal(start) || session.TimerSeconds != 600 || session.Distance != 2500{
+		t.Errorf("session = %+v, want running from %v, 600 s, 2500 m", session, start)
+	}
+	if session.AvgHeartRate != 150 || session.MaxHeartRate != 0{
+		t.Errorf("session heart rate = %d/%d, want 150 and the invalid max dropped", session.AvgHeartRate, session.MaxHeartRate)
+	}
+
+	if len(file.Records) != 2{
+		t.Fatalf("got %d records, want 2", len(file.Records))
+	}
+	first := file.Records[0]
+	if !first.HasPos || first.Lat != -90 || first.Lon != 45{
+		t.Errorf("position = %v %v (%v), want -90 45", first.Lat, first.Lon, first.HasPos)
+	}
+	if first.Altitude == nil || *first.Altitude != 100{
+		t.Errorf("altitude = %v, want 100", first.Altitude)
+	}
+	if first.HeartRate != 140 || first.Distance == nil || *first.Distance != 0{
+		t.Errorf("first record = %+v, want 140 bpm at 0 m", first)
+	}
+	second := file.Records[1]
+	if !second.Time.Equal(start.Add(10*time.Second)){
+		t.Errorf("compressed timestamp = %v, want %v", second.Time, start.Add(10*time.Second))
+	}
+	if second.HasPos || second.HeartRate != 0 || second.Distance == nil || *second.Distance != 30.5{
+		t.Errorf("second record = %+v, want 30.5 m without position or heart rate", second)
+	}
+
+	if len(file.Laps) != 1 || file.Laps[0].TimerSeconds != 600 || file.Laps[0].AvgHeartRate != 150{
+		t.Errorf("laps = %+v, want one 600 s lap at 150 bpm", file.Laps)
+	}
+}
+
+func TestDecodeStrengthSets(t *testing.T){
+	start := time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC)
+
+	b := newFITBuilder()
+	b.define(0, mesgSet, binary.LittleEndian,
+		testField{6, 4, typeUint32}, testField{0, 4, typeUint32}, testField{3, 2, typeUint16}, testField{4, 2, typeUint16},
+		testField{5, 1, typeUint8}, testField{7, 2, typeUint16}, testField{8, 2, typeUint16})
+	b.data(0x00, fitTime(start), uint32(45000), uint16(8), uint16(60*16+4), uint8(1), uint16(0), uint16(1))
+	b.data(0x00, fitTime(start.Add(time.Minute)), uint32(90000), uint16(0xffff), uint16(0xffff), uint8(0), uint16(0xfffe), uint16(0xffff))
+
+	file, err := Decode(b.bytes())
+	if err != nil{
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(file.Sets) != 2{
+		t.Fatalf("got %d sets, want 2", len(file.Sets))
+	}
+
+	active := file.Sets[0]
+	if !active.Active || active.Reps != 8 || active.Category != "bench_press" || active.Subtype != 1{
+		t.Errorf("active set = %+v, want 8 reps of bench_press subtype 1", active)
+	}
+	if active.Weight == nil || *active.Weight != 60.25{
+		t.Errorf("weight = %v, want 60.25", active.Weight)
+	}
+	if !active.Start.Equal(start) || active.DurationSeconds != 45{
+		t.Errorf("start, duration = %v, %v, want %v, 45", active.Start, active.DurationSeconds, start)
+	}
+
+	rest := file.Sets[1]
+	if rest.Active || rest.Reps != 0 || rest.Weight != nil || rest.Category != CategoryUnknown{
+		t.Errorf("rest set = %+v, want an inactive set without reps, weight or category", rest)
+	}
+}
+
+func TestDecodeErrors(t *testing.T){
+	valid := func() []byte{
+		b := newFITBuilder()
+		b.define(0, mesgRecord, binary.LittleEndian, testField{3, 1, typeUint8})
+		b.data(0x00, uint8(120))
+		return b.bytes()
+	}
+
+	badCRC := valid()
+	badCRC[len(badCRC)-1] ^= 0xff
+
+	truncated := valid()
+	truncated = truncated[:len(truncated)-4]
+
+	undefined := newFITBuilder()
+	undefined.records.Write([]byte{0x05, 0x01})
+
+	tests := []struct{
+		name string
+		data []byte
+		want error
+	}{
+		{"not fit", []byte("<gpx></gpx>........"), ErrNotFIT},
+		{"checksum", badCRC, ErrCorrupt},
+		{"truncated", truncated, ErrCorrupt},
+		{"undefined local message", undefined.bytes(), ErrCorrupt},
+	}
+	for _, tt := range tests{
+		t.Run(tt.name, func(t *testing.T){
+			if _, err := Decode(tt.data); !errors.Is(err, tt.want){
+				t.Errorf("Decode() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Decode(valid()); err != nil{
+		t.Errorf("Decode() of the valid file: %v", err)
+	}
+}
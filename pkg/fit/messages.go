@@ -0,0 +1,191 @@
+package fit
+
+import (
+	"math"
+	"time"
+)
+
+const semicirclesToDegrees = 180.0 / (1 << 31)
+
+type File struct {
+	Sessions []Session
+	Laps     []Lap
+	Records  []Record
+	Sets     []Set
+}
+
+type Session struct {
+	Start           time.Time
+	Sport           string
+	ElapsedSeconds  float64
+	TimerSeconds    float64
+	Distance        float64
+	Calories        int
+	AvgHeartRate    int
+	MaxHeartRate    int
+	AvgCadence      int
+	TotalAscent     float64
+}
+
+type Lap struct {
+	Start        time.Time
+	TimerSeconds float64
+	Distance     float64
+	AvgHeartRate int
+	MaxHeartRate int
+	AvgCadence   int
+}
+
+type Record struct {
+	Time      time.Time
+	Lat       float64
+	Lon       float64
+	HasPos    bool
+	Altitude  *float64
+	Distance  *float64
+	HeartRate int
+	Cadence   int
+}
+
+// Set is a strength training set. Weight is in kilograms.
+type Set struct {
+	Start           time.Time
+	DurationSeconds float64
+	Active          bool
+	Reps            int
+	Weight          *float64
+	Category        string
+	Subtype         int
+}
+
+func newSession(m message) Session{
+	s := Session{}
+	s.Start, _ = m.time(2)
+	if sport, ok := m.uint(5); ok{
+		s.Sport = sportNames[sport]
+	}
+	s.ElapsedSeconds, _ = m.scaled(7, 1000, 0)
+	s.TimerSeconds, _ = m.scaled(8, 1000, 0)
+	s.Distance, _ = m.scaled(9, 100, 0)
+	s.Calories = intField(m, 11)
+	s.AvgHeartRate = intField(m, 16)
+	s.MaxHeartRate = intField(m, 17)
+	s.AvgCadence = intField(m, 18)
+	s.TotalAscent, _ = m.scaled(22, 1, 0)
+	return s
+}
+
+func newLap(m message) Lap{
+	l := Lap{}
+	l.Start, _ = m.time(2)
+	l.TimerSeconds, _ = m.scaled(8, 1000, 0)
+	l.Distance, _ = m.scaled(9, 100, 0)
+	l.AvgHeartRate = intField(m, 15)
+	l.MaxHeartRate = intField(m, 16)
+	l.AvgCadence = intField(m, 17)
+	return l
+}
+
+func newRecord(m message) Record{
+	r := Record{}
+	r.Time, _ = m.time(fieldTimestamp)
+
+	lat, latOK := m.int(0)
+	lon, lonOK := m.int(1)
+	if latOK && lonOK{
+		r.Lat, r.Lon, r.HasPos = float64(lat)*semicirclesToDegrees, float64(lon)*semicirclesToDegrees, true
+	}
+
+	// enhanced_altitude replaces altitude on newer devices
+	if alt, ok := m.scaled(78, 5, 500); ok{
+		r.Altitude = &alt
+	} else if alt, ok := m.scaled(2, 5, 500); ok{
+		r.Altitude = &alt
+	}
+	if dist, ok := m.scaled(5, 100, 0); ok{
+		r.Distance = &dist
+	}
+	r.HeartRate = intField(m, 3)
+	r.Cadence = intField(m, 4)
+	return r
+}
+
+func newSet(m message) Set{
+	s := Set{Category: CategoryUnknown}
+	s.Start, _ = m.time(6)
+	if s.Start.IsZero(){
+		s.Start, _ = m.time(254)
+	}
+	s.DurationSeconds, _ = m.scaled(0, 1000, 0)
+	s.Reps = intField(m, 3)
+	if weight, ok := m.scaled(4, 16, 0); ok{
+		weight = math.Round(weight*100) / 100
+		s.Weight = &weight
+	}
+	if setType, ok := m.uint(5); ok{
+		s.Active = setType == 1
+	}
+	if category, ok := m.uint(7); ok{
+		if name, known := categoryNames[category]; known{
+			s.Category = name
+		}
+	}
+	s.Subtype = intField(m, 8)
+	return s
+}
+
+func intField(m message, num byte) int{
+	v, _ := m.uint(num)
+	return int(v)
+}
+
+var sportNames = map[uint64]string{
+	0:  "generic",
+	1:  "running",
+	2:  "cycling",
+	4:  "fitness_equipment",
+	5:  "swimming",
+	10: "training",
+	11: "walking",
+	15: "rowing",
+	17: "hiking",
+}
+
+const CategoryUnknown = "unknown"
+
+// categoryNames is the exercise_category enum of the FIT profile.
+var categoryNames = map[uint64]string{
+	0:  "bench_press",
+	1:  "calf_raise",
+	2:  "cardio",
+	3:  "carry",
+	4:  "chop",
+	5:  "core",
+	6:  "crunch",
+	7:  "curl",
+	8:  "deadlift",
+	9:  "flye",
+	10: "hip_raise",
+	11: "hip_stability",
+	12: "hip_swing",
+	13: "hyperextension",
+	14: "lateral_raise",
+	15: "leg_curl",
+	16: "leg_raise",
+	17: "lunge",
+	18: "olympic_lift",
+	19: "plank",
+	20: "plyo",
+	21: "pull_up",
+	22: "push_up",
+	23: "row",
+	24: "shoulder_press",
+	25: "shoulder_stability",
+	26: "shrug",
+	27: "sit_up",
+	28: "squat",
+	29: "total_body",
+	30: "triceps_extension",
+	31: "warm_up",
+	32: "run",
+}
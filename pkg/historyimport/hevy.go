@@ -0,0 +1,99 @@
+package historyimport
+
+import (
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+// hevyParser reads "title,start_time,end_time,...,exercise_title,set_index,
+// set_type,weight_kg,reps,distance_km,duration_seconds,rpe" exports. Hevy
+// names the unit in the column (weight_kg or weight_lbs, distance_km or
+// distance_miles).
+type hevyParser struct {
+	cols map[string]int
+}
+
+var hevyTimeLayouts = []string{"2 Jan 2006, 15:04", "Jan 2, 2006, 15:04", "2006-01-02 15:04:05", time.RFC3339}
+
+func (p *hevyParser) source() string{
+	return SourceHevy
+}
+
+func (p *hevyParser) parseRow(record []string) (row, bool, error){
+	name := field(record, p.cols, "exercise_title")
+	if name == ""{
+		return row{}, false, nil
+	}
+
+	start, err := parseTime(field(record, p.cols, "start_time"), hevyTimeLayouts...)
+	if err != nil{
+		return row{}, false, err
+	}
+	var duration time.Duration
+	if endStr := field(record, p.cols, "end_time"); endStr != ""{
+		if end, err := parseTime(endStr, hevyTimeLayouts...); err == nil && end.After(start){
+			duration = end.Sub(start)
+		}
+	}
+
+	set, err := p.parseSet(record)
+	if err != nil{
+		return row{}, false, err
+	}
+	if set == (Set{}){
+		return row{}, false, nil
+	}
+
+	return row{
+		title: field(record, p.cols, "title"),
+		start: start,
+		duration: duration,
+		exercise: name,
+		set: set,
+	}, true, nil
+}
+
+func (p *hevyParser) parseSet(record []string) (Set, error){
+	var set Set
+
+	reps, err := parseOptionalInt(field(record, p.cols, "reps"))
+	if err != nil{
+		return Set{}, err
+	}
+	if reps != nil{
+		set.Reps = *reps
+	}
+
+	for column, unit := range map[string]units.System{"weight_kg": units.Metric, "weight_lbs": units.Imperial}{
+		weight, err := parseOptionalFloat(field(record, p.cols, column))
+		if err != nil{
+			return Set{}, err
+		}
+		if weight != nil{
+			kg := units.ToKilograms(*weight, unit)
+			set.Weight = &kg
+		}
+	}
+
+	for column, unit := range map[string]units.System{"distance_km": units.Metric, "distance_miles": units.Imperial}{
+		distance, err := parseOptionalFloat(field(record, p.cols, column))
+		if err != nil{
+			return Set{}, err
+		}
+		if distance != nil{
+			meters := units.DistanceToMeters(*distance, unit)
+			set.Distance = &meters
+		}
+	}
+
+	if set.Seconds, err = parseOptionalInt(field(record, p.cols, "duration_seconds")); err != nil{
+		return Set{}, err
+	}
+
+	if strings.EqualFold(field(record, p.cols, "set_type"), "rest"){
+		return Set{}, nil
+	}
+	return set, nil
+}
@@ -0,0 +1,213 @@
+// Package historyimport reads workout history exported by other apps
+// (Strong and Hevy CSV files) and groups the per-set rows into workouts.
+package historyimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const (
+	SourceStrong = "strong"
+	SourceHevy   = "hevy"
+)
+
+var ErrUnknownFormat = errors.New("unrecognised CSV export, expected a Strong or Hevy export")
+
+// Set values are converted to kilograms, metres and seconds.
+type Set struct {
+	Reps     int
+	Weight   *float64
+	Distance *float64
+	Seconds  *int
+}
+
+type Exercise struct {
+	Name string
+	Sets []Set
+}
+
+type Workout struct {
+	Title     string
+	Start     time.Time
+	Duration  time.Duration
+	Exercises []Exercise
+}
+
+// Parse detects the source from the header row. fallback is the unit of
+// weights and distances for exports that don't name their unit (older
+// Strong exports use whatever the user had configured in the app).
+func Parse(r io.Reader, fallback units.System) (string, []Workout, error){
+	const op = "historyimport.Parse"
+
+	br := bufio.NewReader(r)
+	firstLine, err := br.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF){
+		return "", nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.Comma = detectDelimiter(firstLine)
+
+	header, err := reader.Read()
+	if err != nil{
+		return "", nil, fmt.Errorf("%s: %w", op, ErrUnknownFormat)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header{
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+
+	var p parser
+	switch{
+	case hasColumns(cols, "date", "workout name", "exercise name", "set order"):
+		p = &strongParser{cols: cols, fallback: fallback}
+	case hasColumns(cols, "title", "start_time", "exercise_title", "set_index"):
+		p = &hevyParser{cols: cols}
+	default:
+		return "", nil, fmt.Errorf("%s: %w", op, ErrUnknownFormat)
+	}
+
+	g := newGrouper()
+	line := 1
+	for{
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF){
+			break
+		}
+		line++
+		if err != nil{
+			return "", nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+		}
+		row, ok, err := p.parseRow(record)
+		if err != nil{
+			return "", nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+		}
+		if ok{
+			g.add(row)
+		}
+	}
+
+	return p.source(), g.workouts(), nil
+}
+
+type row struct {
+	title    string
+	start    time.Time
+	duration time.Duration
+	exercise string
+	set      Set
+}
+
+type parser interface {
+	source() string
+	// parseRow returns false for rows that don't describe a set (rest timers, notes).
+	parseRow(record []string) (row, bool, error)
+}
+
+type grouper struct {
+	order []string
+	byKey map[string]*Workout
+}
+
+func newGrouper() *grouper{
+	return &grouper{byKey: make(map[string]*Workout)}
+}
+
+// add keeps workouts in file order and exercises in the order they were first seen.
+func (g *grouper) add(r row){
+	key := r.start.Format(time.RFC3339) + "|" + r.title
+	w, ok := g.byKey[key]
+	if !ok{
+		w = &Workout{Title: r.title, Start: r.start, Duration: r.duration}
+		g.byKey[key] = w
+		g.order = append(g.order, key)
+	}
+	for i := range w.Exercises{
+		if w.Exercises[i].Name == r.exercise{
+			w.Exercises[i].Sets = append(w.Exercises[i].Sets, r.set)
+			return
+		}
+	}
+	w.Exercises = append(w.Exercises, Exercise{Name: r.exercise, Sets: []Set{r.set}})
+}
+
+func (g *grouper) workouts() []Workout{
+	result := make([]Workout, 0, len(g.order))
+	for _, key := range g.order{
+		result = append(result, *g.byKey[key])
+	}
+	return result
+}
+
+// detectDelimiter picks ';' when the header row has more semicolons than
+// commas; Strong uses semicolons in some locales.
+func detectDelimiter(head []byte) rune{
+	firstLine := string(head)
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0{
+		firstLine = firstLine[:i]
+	}
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ","){
+		return ';'
+	}
+	return ','
+}
+
+func hasColumns(cols map[string]int, names ...string) bool{
+	for _, name := range names{
+		if _, ok := cols[name]; !ok{
+			return false
+		}
+	}
+	return true
+}
+
+func field(record []string, cols map[string]int, name string) string{
+	i, ok := cols[name]
+	if !ok || i >= len(record){
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func parseOptionalFloat(value string) (*float64, error){
+	if value == ""{
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+	if err != nil{
+		return nil, fmt.Errorf("invalid number %q", value)
+	}
+	if v == 0{
+		return nil, nil
+	}
+	return &v, nil
+}
+
+func parseOptionalInt(value string) (*int, error){
+	f, err := parseOptionalFloat(value)
+	if err != nil || f == nil{
+		return nil, err
+	}
+	v := int(*f)
+	return &v, nil
+}
+
+func parseTime(value string, layouts ...string) (time.Time, error){
+	for _, layout := range layouts{
+		if t, err := time.Parse(layout, value); err == nil{
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,153 @@
+package historyimport
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+const strongSample = `Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+2024-05-01 07:00:00,Push Day,1h 5m,Bench Press (Barbell),1,135,8,,,,,
+2024-05-01 07:00:00,Push Day,1h 5m,Bench Press (Barbell),2,135,6,,,,,
+2024-05-01 07:00:00,Push Day,1h 5m,Rest Timer,Rest Timer,,,,90,,,
+2024-05-01 07:00:00,Push Day,1h 5m,Plank,1,,,,60,,,
+2024-05-01 07:00:00,Push Day,1h 5m,Bench Press (Barbell),3,0,0,,,,,
+2024-05-03 18:30:00,Run,30m,Running,1,,,3,1800,,,
+`
+
+const hevySample = `title,start_time,end_time,description,exercise_title,superset_id,exercise_notes,set_index,set_type,weight_kg,reps,distance_km,duration_seconds,rpe
+"Legs","2 May 2024, 18:00","2 May 2024, 19:15",,"Squat (Barbell)",,,0,warmup,60,5,,,
+"Legs","2 May 2024, 18:00","2 May 2024, 19:15",,"Squat (Barbell)",,,1,normal,100,5,,,8
+"Legs","2 May 2024, 18:00","2 May 2024, 19:15",,"Leg Press",,,0,normal,180,10,,,
+"Legs","2 May 2024, 18:00","2 May 2024, 19:15",,"Leg Press",,,1,rest,,,,120,
+"Ride","4 May 2024, 08:00","4 May 2024, 07:00",,"Cycling",,,0,normal,,,12.5,2400,
+`
+
+func TestParseStrong(t *testing.T){
+	source, workouts, err := Parse(strings.NewReader(strongSample), units.Imperial)
+	if err != nil{
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if source != SourceStrong{
+		t.Errorf("source = %q, want %q", source, SourceStrong)
+	}
+	if len(workouts) != 2{
+		t.Fatalf("got %d workouts, want 2", len(workouts))
+	}
+
+	push := workouts[0]
+	if push.Title != "Push Day" || push.Duration != 65*time.Minute{
+		t.Errorf("workout = %q %v, want Push Day 1h5m", push.Title, push.Duration)
+	}
+	if want := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC); !push.Start.Equal(want){
+		t.Errorf("Start = %v, want %v", push.Start, want)
+	}
+	// the rest timer and the empty third set are dropped
+	if len(push.Exercises) != 2 || push.Exercises[0].Name != "Bench Press (Barbell)" || push.Exercises[1].Name != "Plank"{
+		t.Fatalf("exercises = %+v, want bench press then plank", push.Exercises)
+	}
+	bench := push.Exercises[0].Sets
+	if len(bench) != 2 || bench[0].Reps != 8 || bench[1].Reps != 6{
+		t.Fatalf("bench sets = %+v, want 8 and 6 reps", bench)
+	}
+	// older exports have no unit column and use the fallback
+	if bench[0].Weight == nil || math.Abs(*bench[0].Weight-135/units.PoundsPerKilogram) > 1e-9{
+		t.Errorf("weight = %v, want 135 lb in kg", bench[0].Weight)
+	}
+	if plank := push.Exercises[1].Sets; len(plank) != 1 || plank[0].Seconds == nil || *plank[0].Seconds != 60{
+		t.Errorf("plank sets = %+v, want one 60 s set", plank)
+	}
+
+	run := workouts[1].Exercises[0].Sets[0]
+	if run.Distance == nil || math.Abs(*run.Distance-3*units.MetersPerMile) > 1e-9{
+		t.Errorf("distance = %v, want 3 mi in metres", run.Distance)
+	}
+}
+
+func TestParseStrongUnitColumnsAndSemicolons(t *testing.T){
+	data := "Date;Workout Name;Duration;Exercise Name;Set Order;Weight;Weight Unit;Reps;Distance;Distance Unit;Seconds\n" +
+		"2024-05-01 07:00:00;Mixed;45m;Deadlift (Barbell);1;102,5;kg;3;;;\n" +
+		"2024-05-01 07:00:00;Mixed;45m;Rowing (Machine);1;;;;2;km;480\n"
+
+	_, workouts, err := Parse(strings.NewReader(data), units.Imperial)
+	if err != nil{
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(workouts) != 1 || len(workouts[0].Exercises) != 2{
+		t.Fatalf("workouts = %+v, want one workout with two exercises", workouts)
+	}
+	deadlift := workouts[0].Exercises[0].Sets[0]
+	if deadlift.Weight == nil || *deadlift.Weight != 102.5{
+		t.Errorf("weight = %v, want 102.5 kg from the unit column", deadlift.Weight)
+	}
+	row := workouts[0].Exercises[1].Sets[0]
+	if row.Distance == nil || *row.Distance != 2000{
+		t.Errorf("distance = %v, want 2000 m from the unit column", row.Distance)
+	}
+}
+
+func TestParseHevy(t *testing.T){
+	source, workouts, err := Parse(strings.NewReader(hevySample), units.Metric)
+	if err != nil{
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if source != SourceHevy{
+		t.Errorf("source = %q, want %q", source, SourceHevy)
+	}
+	if len(workouts) != 2{
+		t.Fatalf("got %d workouts, want 2", len(workouts))
+	}
+
+	legs := workouts[0]
+	if legs.Title != "Legs" || legs.Duration != 75*time.Minute{
+		t.Errorf("workout = %q %v, want Legs 1h15m", legs.Title, legs.Duration)
+	}
+	if len(legs.Exercises) != 2{
+		t.Fatalf("got %d exercises, want 2", len(legs.Exercises))
+	}
+	squat := legs.Exercises[0].Sets
+	if len(squat) != 2 || squat[1].Weight == nil || *squat[1].Weight != 100 || squat[1].Reps != 5{
+		t.Errorf("squat sets = %+v, want the warm-up and 5 x 100 kg", squat)
+	}
+	// the rest row is not a set
+	if press := legs.Exercises[1].Sets; len(press) != 1{
+		t.Errorf("leg press sets = %+v, want one", press)
+	}
+
+	ride := workouts[1]
+	if ride.Duration != 0{
+		t.Errorf("Duration = %v, want 0 when the end is before the start", ride.Duration)
+	}
+	set := ride.Exercises[0].Sets[0]
+	if set.Distance == nil || *set.Distance != 12500 || set.Seconds == nil || *set.Seconds != 2400{
+		t.Errorf("ride set = %+v, want 12500 m in 2400 s", set)
+	}
+}
+
+func TestParseErrors(t *testing.T){
+	tests := []struct{
+		name    string
+		data    string
+		wantErr error
+	}{
+		{"empty", "", ErrUnknownFormat},
+		{"unknown header", "date,exercise,weight\n2024-05-01,Squat,100\n", ErrUnknownFormat},
+		{"bad date", "Date,Workout Name,Exercise Name,Set Order,Reps\nyesterday,A,Squat,1,5\n", nil},
+		{"bad number", "Date,Workout Name,Exercise Name,Set Order,Reps\n2024-05-01 07:00:00,A,Squat,1,five\n", nil},
+	}
+	for _, tt := range tests{
+		t.Run(tt.name, func(t *testing.T){
+			_, _, err := Parse(strings.NewReader(tt.data), units.Metric)
+			if err == nil{
+				t.Fatal("Parse() succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr){
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
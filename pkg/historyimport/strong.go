@@ -0,0 +1,106 @@
+package historyimport
+
+import (
+	"strings"
+	"time"
+
+	"github.com/artembliss/go-fitness-tracker/pkg/units"
+)
+
+// strongParser reads "Date,Workout Name,Duration,Exercise Name,Set Order,
+// Weight,Reps,Distance,Seconds,..." exports. Newer exports add
+// "Weight Unit" and "Distance Unit" columns; older ones rely on the fallback.
+type strongParser struct {
+	cols     map[string]int
+	fallback units.System
+}
+
+func (p *strongParser) source() string{
+	return SourceStrong
+}
+
+func (p *strongParser) parseRow(record []string) (row, bool, error){
+	name := field(record, p.cols, "exercise name")
+	setOrder := field(record, p.cols, "set order")
+	if name == "" || strings.EqualFold(setOrder, "rest timer") || strings.EqualFold(name, "rest timer"){
+		return row{}, false, nil
+	}
+
+	start, err := parseTime(field(record, p.cols, "date"), "2006-01-02 15:04:05", "2006-01-02 15:04", time.RFC3339)
+	if err != nil{
+		return row{}, false, err
+	}
+
+	set, err := p.parseSet(record)
+	if err != nil{
+		return row{}, false, err
+	}
+	if set == (Set{}){
+		return row{}, false, nil
+	}
+
+	return row{
+		title: field(record, p.cols, "workout name"),
+		start: start,
+		duration: parseStrongDuration(field(record, p.cols, "duration")),
+		exercise: name,
+		set: set,
+	}, true, nil
+}
+
+func (p *strongParser) parseSet(record []string) (Set, error){
+	var set Set
+
+	reps, err := parseOptionalInt(field(record, p.cols, "reps"))
+	if err != nil{
+		return Set{}, err
+	}
+	if reps != nil{
+		set.Reps = *reps
+	}
+
+	weight, err := parseOptionalFloat(field(record, p.cols, "weight"))
+	if err != nil{
+		return Set{}, err
+	}
+	if weight != nil{
+		unit := p.fallback
+		if u := strings.ToLower(field(record, p.cols, "weight unit")); u != ""{
+			if unit, err = units.Parse(u); err != nil{
+				return Set{}, err
+			}
+		}
+		kg := units.ToKilograms(*weight, unit)
+		set.Weight = &kg
+	}
+
+	distance, err := parseOptionalFloat(field(record, p.cols, "distance"))
+	if err != nil{
+		return Set{}, err
+	}
+	if distance != nil{
+		unit := p.fallback
+		switch strings.ToLower(field(record, p.cols, "distance unit")){
+		case "km":
+			unit = units.Metric
+		case "mi", "miles":
+			unit = units.Imperial
+		}
+		meters := units.DistanceToMeters(*distance, unit)
+		set.Distance = &meters
+	}
+
+	if set.Seconds, err = parseOptionalInt(field(record, p.cols, "seconds")); err != nil{
+		return Set{}, err
+	}
+	return set, nil
+}
+
+// parseStrongDuration reads values such as "1h 5m" or "45m".
+func parseStrongDuration(value string) time.Duration{
+	d, err := time.ParseDuration(strings.ReplaceAll(value, " ", ""))
+	if err != nil{
+		return 0
+	}
+	return d
+}
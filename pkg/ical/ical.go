@@ -0,0 +1,129 @@
+// Package ical writes iCalendar (RFC 5545) feeds for calendar subscriptions.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	// maxLineOctets is the longest content line allowed before folding.
+	maxLineOctets = 75
+)
+
+// Event is either an all-day event (dates only, End exclusive) or a timed
+// event in UTC.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Summary     string
+	Description string
+	// Status is CONFIRMED, TENTATIVE or CANCELLED; empty leaves it out.
+	Status   string
+	Created  time.Time
+	Modified time.Time
+}
+
+type Calendar struct {
+	Name string
+	// ProductID identifies the generator, e.g. "-//fitness-tracker//EN".
+	ProductID string
+	Events    []Event
+}
+
+// Write serialises the calendar with CRLF line endings and folded lines.
+func Write(w io.Writer, cal Calendar) error{
+	bw := bufio.NewWriter(w)
+	lw := &lineWriter{w: bw}
+
+	lw.line("BEGIN", "VCALENDAR")
+	lw.line("VERSION", "2.0")
+	lw.line("PRODID", cal.ProductID)
+	lw.line("CALSCALE", "GREGORIAN")
+	lw.line("METHOD", "PUBLISH")
+	if cal.Name != ""{
+		lw.line("X-WR-CALNAME", escapeText(cal.Name))
+	}
+
+	for _, e := range cal.Events{
+		lw.line("BEGIN", "VEVENT")
+		lw.line("UID", e.UID)
+		stamp := e.Modified
+		if stamp.IsZero(){
+			stamp = e.Created
+		}
+		if stamp.IsZero(){
+			stamp = time.Now()
+		}
+		lw.line("DTSTAMP", stamp.UTC().Format(dateTimeLayout))
+		if e.AllDay{
+			lw.line("DTSTART;VALUE=DATE", e.Start.Format(dateLayout))
+			lw.line("DTEND;VALUE=DATE", e.End.Format(dateLayout))
+		} else{
+			lw.line("DTSTART", e.Start.UTC().Format(dateTimeLayout))
+			lw.line("DTEND", e.End.UTC().Format(dateTimeLayout))
+		}
+		lw.line("SUMMARY", escapeText(e.Summary))
+		if e.Description != ""{
+			lw.line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Status != ""{
+			lw.line("STATUS", e.Status)
+		}
+		if !e.Created.IsZero(){
+			lw.line("CREATED", e.Created.UTC().Format(dateTimeLayout))
+		}
+		if !e.Modified.IsZero(){
+			lw.line("LAST-MODIFIED", e.Modified.UTC().Format(dateTimeLayout))
+		}
+		lw.line("END", "VEVENT")
+	}
+
+	lw.line("END", "VCALENDAR")
+	if lw.err != nil{
+		return lw.err
+	}
+	return bw.Flush()
+}
+
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes "name:value", folding it into continuation lines that start
+// with a space so no line exceeds 75 octets. Folds never split a UTF-8 rune.
+func (lw *lineWriter) line(name, value string){
+	if lw.err != nil{
+		return
+	}
+	content := name + ":" + value
+
+	limit := maxLineOctets
+	for len(content) > limit{
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]){
+			cut--
+		}
+		if _, lw.err = lw.w.WriteString(content[:cut] + "\r\n "); lw.err != nil{
+			return
+		}
+		content = content[cut:]
+		// continuation lines lose one octet to the leading space
+		limit = maxLineOctets - 1
+	}
+	_, lw.err = lw.w.WriteString(content + "\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string{
+	return textEscaper.Replace(s)
+}
@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxKeptMessages bounds the messages FileMailer keeps in memory.
+const maxKeptMessages = 100
+
+// FileMailer writes every message as an .eml file into a directory instead of
+// delivering it. The latest messages are also kept in memory for tests.
+type FileMailer struct {
+	dir  string
+	mu   sync.Mutex
+	sent []Message
+}
+
+func NewFileMailer(dir string) (*FileMailer, error){
+	const op = "mailer.file.NewFileMailer"
+
+	if err := os.MkdirAll(dir, 0o755); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &FileMailer{dir: dir}, nil
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error{
+	const op = "mailer.file.Send"
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), sanitize(msg.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), buildMessage("outbox@localhost", msg), 0o644); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	m.sent = append(m.sent, msg)
+	if len(m.sent) > maxKeptMessages{
+		m.sent = append([]Message(nil), m.sent[len(m.sent)-maxKeptMessages:]...)
+	}
+	return nil
+}
+
+// Sent returns up to the last maxKeptMessages messages, oldest first.
+func (m *FileMailer) Sent() []Message{
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.sent...)
+}
+
+func sanitize(s string) string{
+	return strings.Map(func(r rune) rune {
+		switch{
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New picks an implementation from MAILER: "smtp" for real delivery, anything
+// else writes messages to MAILER_OUTBOX_DIR for development and tests.
+func New() (Mailer, error){
+	const op = "mailer.New"
+
+	switch os.Getenv("MAILER"){
+	case "smtp":
+		host, hostExist := os.LookupEnv("SMTP_HOST")
+		port, portExist := os.LookupEnv("SMTP_PORT")
+		from, fromExist := os.LookupEnv("SMTP_FROM")
+		if !hostExist || !portExist || !fromExist{
+			return nil, fmt.Errorf("%s: some SMTP env variables not set", op)
+		}
+		return NewSMTPMailer(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from), nil
+	default:
+		dir := os.Getenv("MAILER_OUTBOX_DIR")
+		if dir == ""{
+			dir = "outbox"
+		}
+		return NewFileMailer(dir)
+	}
+}
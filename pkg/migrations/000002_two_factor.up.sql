@@ -0,0 +1,11 @@
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS totp_secret TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS recovery_codes(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+code_hash VARCHAR(64) NOT NULL,
+used_at TIMESTAMP,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
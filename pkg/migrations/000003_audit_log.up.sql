@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS audit_log(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+event VARCHAR(100) NOT NULL,
+ip VARCHAR(64),
+details TEXT,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
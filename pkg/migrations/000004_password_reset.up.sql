@@ -0,0 +1,10 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+token_hash VARCHAR(64) UNIQUE NOT NULL,
+expires_at TIMESTAMP NOT NULL,
+used_at TIMESTAMP,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
@@ -0,0 +1,13 @@
+-- accounts created before verification existed are treated as verified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+email VARCHAR(255) NOT NULL,
+token_hash VARCHAR(64) UNIQUE NOT NULL,
+expires_at TIMESTAMP NOT NULL,
+used_at TIMESTAMP,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS body_metrics;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS body_metrics(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+measured_at TIMESTAMP NOT NULL,
+weight FLOAT,
+body_fat FLOAT,
+waist FLOAT,
+chest FLOAT,
+arms FLOAT,
+thighs FLOAT,
+resting_heart_rate INT,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS body_metrics_user_measured_idx ON body_metrics(user_id, measured_at);
+
+-- keep the weight known so far as the first history entry
+INSERT INTO body_metrics (user_id, measured_at, weight)
+SELECT id, created_at, weight FROM users WHERE weight IS NOT NULL AND weight > 0;
@@ -0,0 +1,4 @@
+ALTER TABLE exercises_entry ALTER COLUMN weight TYPE DECIMAL(6,3)[];
+ALTER TABLE exercises_program ALTER COLUMN weight TYPE DECIMAL(6,3);
+
+ALTER TABLE users DROP COLUMN IF EXISTS unit_system;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS unit_system VARCHAR(10) NOT NULL DEFAULT 'metric';
+
+-- weights are stored in kilograms; the extra precision keeps lb round-trips exact to 0.01
+ALTER TABLE exercises_program ALTER COLUMN weight TYPE NUMERIC(8,3);
+ALTER TABLE exercises_entry ALTER COLUMN weight TYPE NUMERIC(8,3)[];
@@ -0,0 +1,3 @@
+ALTER TABLE workouts
+DROP COLUMN IF EXISTS calories_estimated,
+DROP COLUMN IF EXISTS calories_override;
\ No newline at end of file
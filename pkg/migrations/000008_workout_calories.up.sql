@@ -0,0 +1,6 @@
+ALTER TABLE workouts
+ADD COLUMN IF NOT EXISTS calories_estimated FLOAT,
+ADD COLUMN IF NOT EXISTS calories_override FLOAT;
+
+-- calories sent by clients so far were user-entered values
+UPDATE workouts SET calories_override = calories WHERE calories IS NOT NULL AND calories_override IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE exercises_entry
+DROP COLUMN IF EXISTS distance,
+DROP COLUMN IF EXISTS duration_seconds,
+DROP COLUMN IF EXISTS elevation_gain,
+DROP COLUMN IF EXISTS avg_heart_rate,
+DROP COLUMN IF EXISTS max_heart_rate,
+DROP COLUMN IF EXISTS intervals;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE exercises_entry
+ADD COLUMN IF NOT EXISTS distance NUMERIC(10,2),
+ADD COLUMN IF NOT EXISTS duration_seconds INT,
+ADD COLUMN IF NOT EXISTS elevation_gain NUMERIC(8,2),
+ADD COLUMN IF NOT EXISTS avg_heart_rate INT,
+ADD COLUMN IF NOT EXISTS max_heart_rate INT,
+ADD COLUMN IF NOT EXISTS intervals JSONB;
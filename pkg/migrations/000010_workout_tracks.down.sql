@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workout_tracks;
+
+ALTER TABLE exercises_entry DROP COLUMN IF EXISTS avg_cadence;
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS avg_cadence INT;
+
+CREATE TABLE IF NOT EXISTS workout_tracks(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+workout_id INT REFERENCES workouts(id) ON DELETE CASCADE,
+format VARCHAR(8) NOT NULL,
+content_hash VARCHAR(64) NOT NULL,
+data BYTEA NOT NULL,
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+UNIQUE (user_id, content_hash)
+);
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS import_jobs(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+source VARCHAR(16) NOT NULL,
+status VARCHAR(16) NOT NULL,
+dry_run BOOLEAN NOT NULL DEFAULT false,
+content_hash VARCHAR(64) NOT NULL,
+total_workouts INT NOT NULL DEFAULT 0,
+processed_workouts INT NOT NULL DEFAULT 0,
+created_workouts INT NOT NULL DEFAULT 0,
+skipped_workouts INT NOT NULL DEFAULT 0,
+unmapped JSONB NOT NULL DEFAULT '[]',
+preview JSONB,
+error TEXT NOT NULL DEFAULT '',
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS import_jobs_user_hash_idx ON import_jobs(user_id, content_hash);
+
+CREATE TABLE IF NOT EXISTS exercise_name_mappings(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+source_name VARCHAR(255) NOT NULL,
+exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS exercise_name_mappings_user_name_idx ON exercise_name_mappings(user_id, lower(source_name));
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS data_exports(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+status VARCHAR(16) NOT NULL,
+file_path TEXT NOT NULL DEFAULT '',
+size_bytes BIGINT NOT NULL DEFAULT 0,
+error TEXT NOT NULL DEFAULT '',
+expires_at TIMESTAMP,
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+finished_at TIMESTAMP
+);
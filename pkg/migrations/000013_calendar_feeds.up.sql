@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS calendar_feeds(
+id SERIAL PRIMARY KEY,
+user_id INT UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+token_hash VARCHAR(64) UNIQUE NOT NULL,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
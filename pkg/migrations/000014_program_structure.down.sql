@@ -0,0 +1,12 @@
+INSERT INTO exercises_program (program_id, exercise_id, sets, reps, weight)
+SELECT ph.program_id, s.exercise_id, s.sets, s.reps, s.weight
+FROM program_slots s
+JOIN program_days d ON d.id = s.day_id
+JOIN program_weeks w ON w.id = d.week_id
+JOIN program_phases ph ON ph.id = w.phase_id
+ORDER BY ph.program_id, ph.position, w.position, d.position, s.position;
+
+DROP TABLE IF EXISTS program_slots;
+DROP TABLE IF EXISTS program_days;
+DROP TABLE IF EXISTS program_weeks;
+DROP TABLE IF EXISTS program_phases;
@@ -0,0 +1,61 @@
+CREATE TABLE IF NOT EXISTS program_phases(
+id SERIAL PRIMARY KEY,
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+position INT NOT NULL,
+name VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS program_weeks(
+id SERIAL PRIMARY KEY,
+phase_id INT REFERENCES program_phases(id) ON DELETE CASCADE,
+position INT NOT NULL,
+name VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS program_days(
+id SERIAL PRIMARY KEY,
+week_id INT REFERENCES program_weeks(id) ON DELETE CASCADE,
+position INT NOT NULL,
+name VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS program_slots(
+id SERIAL PRIMARY KEY,
+day_id INT REFERENCES program_days(id) ON DELETE CASCADE,
+position INT NOT NULL,
+exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+sets INT NOT NULL,
+reps INT NOT NULL,
+reps_max INT,
+weight NUMERIC(8,3),
+percent_e1rm NUMERIC(5,2),
+rest_seconds INT,
+notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS program_phases_program_idx ON program_phases(program_id);
+CREATE INDEX IF NOT EXISTS program_weeks_phase_idx ON program_weeks(phase_id);
+CREATE INDEX IF NOT EXISTS program_days_week_idx ON program_days(week_id);
+CREATE INDEX IF NOT EXISTS program_slots_day_idx ON program_slots(day_id);
+
+INSERT INTO program_phases (program_id, position, name)
+SELECT DISTINCT ep.program_id, 1, '' FROM exercises_program ep
+WHERE NOT EXISTS (SELECT 1 FROM program_phases ph WHERE ph.program_id = ep.program_id);
+
+INSERT INTO program_weeks (phase_id, position, name)
+SELECT ph.id, 1, '' FROM program_phases ph
+WHERE NOT EXISTS (SELECT 1 FROM program_weeks w WHERE w.phase_id = ph.id);
+
+INSERT INTO program_days (week_id, position, name)
+SELECT w.id, 1, 'Day 1' FROM program_weeks w
+WHERE NOT EXISTS (SELECT 1 FROM program_days d WHERE d.week_id = w.id);
+
+INSERT INTO program_slots (day_id, position, exercise_id, sets, reps, weight)
+SELECT d.id, ROW_NUMBER() OVER (PARTITION BY ep.program_id ORDER BY ep.id), ep.exercise_id, ep.sets, ep.reps, ep.weight
+FROM exercises_program ep
+JOIN program_phases ph ON ph.program_id = ep.program_id AND ph.position = 1
+JOIN program_weeks w ON w.phase_id = ph.id AND w.position = 1
+JOIN program_days d ON d.week_id = w.id AND d.position = 1
+WHERE NOT EXISTS (SELECT 1 FROM program_slots s WHERE s.day_id = d.id);
+
+DELETE FROM exercises_program;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS program_progression_history;
+DROP TABLE IF EXISTS program_progression_state;
+DROP TABLE IF EXISTS program_progression_rules;
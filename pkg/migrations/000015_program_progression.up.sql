@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS program_progression_rules(
+id SERIAL PRIMARY KEY,
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+type VARCHAR(16) NOT NULL,
+increment NUMERIC(8,3) NOT NULL DEFAULT 0,
+deload_after INT NOT NULL DEFAULT 0,
+deload_percent NUMERIC(5,2) NOT NULL DEFAULT 10,
+wave_percents NUMERIC(5,2)[],
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+UNIQUE (program_id, exercise_id)
+);
+
+CREATE TABLE IF NOT EXISTS program_progression_state(
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+weight NUMERIC(8,3) NOT NULL,
+reps INT NOT NULL,
+wave_step INT NOT NULL DEFAULT 0,
+failures INT NOT NULL DEFAULT 0,
+updated_at TIMESTAMP DEFAULT now() NOT NULL,
+PRIMARY KEY (program_id, exercise_id)
+);
+
+CREATE TABLE IF NOT EXISTS program_progression_history(
+id SERIAL PRIMARY KEY,
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+workout_id INT REFERENCES workouts(id) ON DELETE SET NULL,
+action VARCHAR(16) NOT NULL,
+previous_weight NUMERIC(8,3) NOT NULL,
+new_weight NUMERIC(8,3) NOT NULL,
+previous_reps INT NOT NULL,
+new_reps INT NOT NULL,
+reason TEXT NOT NULL,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS program_progression_history_program_idx ON program_progression_history(program_id, created_at);
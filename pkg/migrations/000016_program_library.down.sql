@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS program_followers;
+DROP INDEX IF EXISTS programs_public_idx;
+DROP INDEX IF EXISTS programs_tags_idx;
+DROP INDEX IF EXISTS programs_forked_from_idx;
+ALTER TABLE programs DROP COLUMN IF EXISTS forked_from_author;
+ALTER TABLE programs DROP COLUMN IF EXISTS forked_from_name;
+ALTER TABLE programs DROP COLUMN IF EXISTS forked_from_id;
+ALTER TABLE programs DROP COLUMN IF EXISTS tags;
+ALTER TABLE programs DROP COLUMN IF EXISTS share_token;
+ALTER TABLE programs DROP COLUMN IF EXISTS visibility;
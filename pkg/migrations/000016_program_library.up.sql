@@ -0,0 +1,17 @@
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS visibility VARCHAR(16) NOT NULL DEFAULT 'private';
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS share_token VARCHAR(64) UNIQUE;
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS forked_from_id INT REFERENCES programs(id) ON DELETE SET NULL;
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS forked_from_name TEXT;
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS forked_from_author TEXT;
+
+CREATE TABLE IF NOT EXISTS program_followers(
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+PRIMARY KEY (program_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS programs_public_idx ON programs(created_at) WHERE visibility = 'public';
+CREATE INDEX IF NOT EXISTS programs_tags_idx ON programs USING GIN (tags);
+CREATE INDEX IF NOT EXISTS programs_forked_from_idx ON programs(forked_from_id);
@@ -0,0 +1,2 @@
+ALTER TABLE workouts DROP COLUMN IF EXISTS program_version_id;
+DROP TABLE IF EXISTS program_versions;
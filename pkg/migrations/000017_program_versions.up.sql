@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS program_versions(
+id SERIAL PRIMARY KEY,
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+version INT NOT NULL,
+name VARCHAR(255) NOT NULL,
+structure JSONB NOT NULL,
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+UNIQUE (program_id, version)
+);
+
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS program_version_id INT REFERENCES program_versions(id) ON DELETE SET NULL;
+
+INSERT INTO program_versions (program_id, version, name, structure, created_at)
+SELECT p.id, 1, p.name, COALESCE((SELECT jsonb_agg(jsonb_build_object('name', ph.name, 'weeks', COALESCE((
+	SELECT jsonb_agg(jsonb_build_object('name', w.name, 'days', COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('name', d.name, 'slots', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object('exercise_id', s.exercise_id, 'sets', s.sets, 'reps', s.reps,
+				'reps_max', s.reps_max, 'weight', s.weight, 'percent_e1rm', s.percent_e1rm,
+				'rest_seconds', s.rest_seconds, 'notes', s.notes) ORDER BY s.position)
+			FROM program_slots s WHERE s.day_id = d.id), '[]'::jsonb)) ORDER BY d.position)
+		FROM program_days d WHERE d.week_id = w.id), '[]'::jsonb)) ORDER BY w.position)
+	FROM program_weeks w WHERE w.phase_id = ph.id), '[]'::jsonb)) ORDER BY ph.position)
+FROM program_phases ph WHERE ph.program_id = p.id), '[]'::jsonb), p.created_at
+FROM programs p
+WHERE NOT EXISTS (SELECT 1 FROM program_versions v WHERE v.program_id = p.id);
+
+UPDATE workouts wo SET program_version_id = v.id
+FROM program_versions v
+WHERE v.program_id = wo.program_id AND v.version = 1 AND wo.program_version_id IS NULL;
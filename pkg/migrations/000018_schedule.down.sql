@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS scheduled_sessions;
+DROP TABLE IF EXISTS schedule_rules;
+ALTER TABLE users DROP COLUMN IF EXISTS reschedule_policy;
@@ -0,0 +1,33 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS reschedule_policy VARCHAR(16) NOT NULL DEFAULT 'none';
+
+CREATE TABLE IF NOT EXISTS schedule_rules(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+weekdays INT[] NOT NULL,
+start_date DATE NOT NULL,
+end_date DATE,
+day_index INT,
+index_offset INT NOT NULL DEFAULT 0,
+materialized_until DATE,
+created_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_sessions(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+rule_id INT REFERENCES schedule_rules(id) ON DELETE SET NULL,
+occurrence INT,
+day_index INT NOT NULL,
+date DATE NOT NULL,
+status VARCHAR(16) NOT NULL DEFAULT 'planned',
+workout_id INT REFERENCES workouts(id) ON DELETE SET NULL,
+rescheduled_from DATE,
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+updated_at TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS scheduled_sessions_rule_occurrence_idx ON scheduled_sessions(rule_id, occurrence);
+CREATE INDEX IF NOT EXISTS scheduled_sessions_user_date_idx ON scheduled_sessions(user_id, date);
+CREATE INDEX IF NOT EXISTS schedule_rules_user_idx ON schedule_rules(user_id);
@@ -0,0 +1,6 @@
+ALTER TABLE exercises_entry DROP COLUMN IF EXISTS group_label;
+ALTER TABLE exercises_entry DROP COLUMN IF EXISTS position;
+ALTER TABLE workouts DROP COLUMN IF EXISTS exercise_groups;
+
+ALTER TABLE program_slots DROP COLUMN IF EXISTS group_label;
+ALTER TABLE program_days DROP COLUMN IF EXISTS groups;
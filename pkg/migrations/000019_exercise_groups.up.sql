@@ -0,0 +1,13 @@
+ALTER TABLE program_days ADD COLUMN IF NOT EXISTS groups JSONB;
+ALTER TABLE program_slots ADD COLUMN IF NOT EXISTS group_label TEXT;
+
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS exercise_groups JSONB;
+ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS position INT;
+ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS group_label TEXT;
+
+UPDATE exercises_entry ee SET position = ordered.position
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY workout_id ORDER BY id) AS position FROM exercises_entry) ordered
+WHERE ee.id = ordered.id AND ee.position IS NULL;
+
+ALTER TABLE exercises_entry ALTER COLUMN position SET DEFAULT 0;
+ALTER TABLE exercises_entry ALTER COLUMN position SET NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE exercises_entry DROP COLUMN IF EXISTS substituted_for_id;
+ALTER TABLE program_slots DROP COLUMN IF EXISTS substituted_for_id;
@@ -0,0 +1,2 @@
+ALTER TABLE program_slots ADD COLUMN IF NOT EXISTS substituted_for_id INT REFERENCES exercises(id) ON DELETE SET NULL;
+ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS substituted_for_id INT REFERENCES exercises(id) ON DELETE SET NULL;
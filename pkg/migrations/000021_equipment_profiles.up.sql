@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS equipment_profiles(
+id SERIAL PRIMARY KEY,
+user_id INT REFERENCES users(id) ON DELETE CASCADE,
+name VARCHAR(64) NOT NULL,
+equipment TEXT[] NOT NULL DEFAULT '{}',
+plate_increment NUMERIC(8,3),
+dumbbell_increment NUMERIC(8,3),
+is_default BOOLEAN NOT NULL DEFAULT false,
+created_at TIMESTAMP DEFAULT now() NOT NULL,
+UNIQUE (user_id, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS equipment_profiles_default_idx ON equipment_profiles(user_id) WHERE is_default;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS exercises_program(
+id SERIAL PRIMARY KEY,
+program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+sets INTEGER NOT NULL,
+reps INTEGER NOT NULL,
+weight NUMERIC(8,3));
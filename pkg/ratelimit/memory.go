@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type counter struct {
+	value   int64
+	expires time.Time
+}
+
+// sweepInterval is how often expired counters and blocks are dropped.
+const sweepInterval = time.Minute
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]counter
+	blocks    map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters: make(map[string]counter),
+		blocks:   make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryStore) Increment(_ context.Context, key string, window time.Duration) (int64, error){
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evict(now)
+
+	c, ok := s.counters[key]
+	if !ok || now.After(c.expires){
+		c = counter{expires: now.Add(window)}
+	}
+	c.value++
+	s.counters[key] = c
+
+	return c.value, nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error{
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.counters, key)
+	return nil
+}
+
+func (s *MemoryStore) Block(_ context.Context, key string, d time.Duration) error{
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evict(now)
+
+	s.blocks[key] = now.Add(d)
+	return nil
+}
+
+func (s *MemoryStore) BlockedFor(_ context.Context, key string) (time.Duration, error){
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.blocks[key]
+	if !ok{
+		return 0, nil
+	}
+	left := time.Until(until)
+	if left <= 0{
+		delete(s.blocks, key)
+		return 0, nil
+	}
+	return left, nil
+}
+
+// evict drops expired counters and blocks, at most once per sweepInterval,
+// so keys that are never seen again do not stay in memory.
+func (s *MemoryStore) evict(now time.Time){
+	if now.Sub(s.lastSweep) < sweepInterval{
+		return
+	}
+	s.lastSweep = now
+
+	for key, c := range s.counters{
+		if now.After(c.expires){
+			delete(s.counters, key)
+		}
+	}
+	for key, until := range s.blocks{
+		if !now.Before(until){
+			delete(s.blocks, key)
+		}
+	}
+}
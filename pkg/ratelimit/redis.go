@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Increment(ctx context.Context, key string, window time.Duration) (int64, error){
+	const op = "ratelimit.redis.Increment"
+
+	k := s.prefix + key
+	pipe := s.client.TxPipeline()
+	incr := pipe.Incr(ctx, k)
+	pipe.ExpireNX(ctx, k, window)
+	if _, err := pipe.Exec(ctx); err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return incr.Val(), nil
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error{
+	const op = "ratelimit.redis.Reset"
+
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Block(ctx context.Context, key string, d time.Duration) error{
+	const op = "ratelimit.redis.Block"
+
+	if err := s.client.Set(ctx, s.prefix+"block:"+key, 1, d).Err(); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) BlockedFor(ctx context.Context, key string) (time.Duration, error){
+	const op = "ratelimit.redis.BlockedFor"
+
+	ttl, err := s.client.PTTL(ctx, s.prefix+"block:"+key).Result()
+	if err != nil{
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if ttl < 0{
+		return 0, nil
+	}
+	return ttl, nil
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Store keeps attempt counters and temporary blocks keyed by arbitrary strings
+// (e.g. "login:account:<email>" or "login:ip:<addr>").
+type Store interface {
+	// Increment bumps the counter for key and returns the new value. The counter
+	// expires window after the first increment.
+	Increment(ctx context.Context, key string, window time.Duration) (int64, error)
+	Reset(ctx context.Context, key string) error
+	Block(ctx context.Context, key string, d time.Duration) error
+	// BlockedFor returns the remaining block time for key, or 0 when it is not blocked.
+	BlockedFor(ctx context.Context, key string) (time.Duration, error)
+}
+
+// FallbackStore uses the primary store and switches to the fallback for a call
+// whenever the primary returns an error, so an unavailable Redis does not disable protection.
+type FallbackStore struct {
+	Primary  Store
+	Fallback Store
+}
+
+func NewFallbackStore(primary, fallback Store) *FallbackStore {
+	return &FallbackStore{Primary: primary, Fallback: fallback}
+}
+
+func (s *FallbackStore) Increment(ctx context.Context, key string, window time.Duration) (int64, error){
+	n, err := s.Primary.Increment(ctx, key, window)
+	if err != nil{
+		log.Printf("warning: rate limit store unavailable, using fallback: %v", err)
+		return s.Fallback.Increment(ctx, key, window)
+	}
+	return n, nil
+}
+
+func (s *FallbackStore) Reset(ctx context.Context, key string) error{
+	if err := s.Primary.Reset(ctx, key); err != nil{
+		log.Printf("warning: rate limit store unavailable, using fallback: %v", err)
+	}
+	return s.Fallback.Reset(ctx, key)
+}
+
+func (s *FallbackStore) Block(ctx context.Context, key string, d time.Duration) error{
+	if err := s.Primary.Block(ctx, key, d); err != nil{
+		log.Printf("warning: rate limit store unavailable, using fallback: %v", err)
+		return s.Fallback.Block(ctx, key, d)
+	}
+	return nil
+}
+
+func (s *FallbackStore) BlockedFor(ctx context.Context, key string) (time.Duration, error){
+	d, err := s.Primary.BlockedFor(ctx, key)
+	if err != nil{
+		log.Printf("warning: rate limit store unavailable, using fallback: %v", err)
+		return s.Fallback.BlockedFor(ctx, key)
+	}
+	if d == 0{
+		return s.Fallback.BlockedFor(ctx, key)
+	}
+	return d, nil
+}
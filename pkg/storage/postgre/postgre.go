@@ -89,18 +89,6 @@ func New() (*Storage, error){
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	createTableExercisesProgramQuery := `
-	CREATE TABLE IF NOT EXISTS exercises_program(
-	id SERIAL PRIMARY KEY,
-	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
-	exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
-	sets INTEGER NOT NULL,
-	reps INTEGER NOT NULL,
-	weight DECIMAL(6,3))`
-	if _, err := db.Exec(createTableExercisesProgramQuery); err != nil{
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
-
 	createTableExercisesEntryQuery := `
 	CREATE TABLE IF NOT EXISTS exercises_entry(
 	id SERIAL PRIMARY KEY,
@@ -112,5 +100,511 @@ func New() (*Storage, error){
 	if _, err := db.Exec(createTableExercisesEntryQuery); err != nil{
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+
+	alterUsersTwoFactorQuery := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS totp_secret TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false,
+	ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0`
+	if _, err := db.Exec(alterUsersTwoFactorQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableRecoveryCodesQuery := `
+	CREATE TABLE IF NOT EXISTS recovery_codes(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	code_hash VARCHAR(64) NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	)`
+	if _, err := db.Exec(createTableRecoveryCodesQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableAuditLogQuery := `
+	CREATE TABLE IF NOT EXISTS audit_log(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	event VARCHAR(100) NOT NULL,
+	ip VARCHAR(64),
+	details TEXT,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	)`
+	if _, err := db.Exec(createTableAuditLogQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	alterUsersTokenVersionQuery := `
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0`
+	if _, err := db.Exec(alterUsersTokenVersionQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTablePasswordResetTokensQuery := `
+	CREATE TABLE IF NOT EXISTS password_reset_tokens(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	token_hash VARCHAR(64) UNIQUE NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	)`
+	if _, err := db.Exec(createTablePasswordResetTokensQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	alterUsersEmailVerifiedQuery := `
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT true;
+	ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT false`
+	if _, err := db.Exec(alterUsersEmailVerifiedQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableEmailVerificationTokensQuery := `
+	CREATE TABLE IF NOT EXISTS email_verification_tokens(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	email VARCHAR(255) NOT NULL,
+	token_hash VARCHAR(64) UNIQUE NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	)`
+	if _, err := db.Exec(createTableEmailVerificationTokensQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	bodyMetricsExist, err := tableExists(db, "body_metrics")
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	createTableBodyMetricsQuery := `
+	CREATE TABLE IF NOT EXISTS body_metrics(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	measured_at TIMESTAMP NOT NULL,
+	weight FLOAT,
+	body_fat FLOAT,
+	waist FLOAT,
+	chest FLOAT,
+	arms FLOAT,
+	thighs FLOAT,
+	resting_heart_rate INT,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS body_metrics_user_measured_idx ON body_metrics(user_id, measured_at);`
+	if _, err := db.Exec(createTableBodyMetricsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !bodyMetricsExist{
+		// keep the weight known so far as the first history entry
+		backfillBodyMetricsQuery := `INSERT INTO body_metrics (user_id, measured_at, weight)
+		SELECT id, created_at, weight FROM users WHERE weight IS NOT NULL AND weight > 0`
+		if _, err := db.Exec(backfillBodyMetricsQuery); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	alterUnitsQuery := `
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS unit_system VARCHAR(10) NOT NULL DEFAULT 'metric';
+	ALTER TABLE exercises_entry ALTER COLUMN weight TYPE NUMERIC(8,3)[]`
+	if _, err := db.Exec(alterUnitsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	caloriesOverrideExists, err := columnExists(db, "workouts", "calories_override")
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	alterWorkoutsCaloriesQuery := `
+	ALTER TABLE workouts
+	ADD COLUMN IF NOT EXISTS calories_estimated FLOAT,
+	ADD COLUMN IF NOT EXISTS calories_override FLOAT`
+	if _, err := db.Exec(alterWorkoutsCaloriesQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !caloriesOverrideExists{
+		// calories sent by clients so far were user-entered values
+		backfillCaloriesQuery := `UPDATE workouts SET calories_override = calories
+		WHERE calories IS NOT NULL AND calories_override IS NULL`
+		if _, err := db.Exec(backfillCaloriesQuery); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	alterExercisesEntryCardioQuery := `
+	ALTER TABLE exercises_entry
+	ADD COLUMN IF NOT EXISTS distance NUMERIC(10,2),
+	ADD COLUMN IF NOT EXISTS duration_seconds INT,
+	ADD COLUMN IF NOT EXISTS elevation_gain NUMERIC(8,2),
+	ADD COLUMN IF NOT EXISTS avg_heart_rate INT,
+	ADD COLUMN IF NOT EXISTS max_heart_rate INT,
+	ADD COLUMN IF NOT EXISTS intervals JSONB`
+	if _, err := db.Exec(alterExercisesEntryCardioQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableWorkoutTracksQuery := `
+	ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS avg_cadence INT;
+	CREATE TABLE IF NOT EXISTS workout_tracks(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	workout_id INT REFERENCES workouts(id) ON DELETE CASCADE,
+	format VARCHAR(8) NOT NULL,
+	content_hash VARCHAR(64) NOT NULL,
+	data BYTEA NOT NULL,
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	UNIQUE (user_id, content_hash)
+	)`
+	if _, err := db.Exec(createTableWorkoutTracksQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableImportsQuery := `
+	CREATE TABLE IF NOT EXISTS import_jobs(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	source VARCHAR(16) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	dry_run BOOLEAN NOT NULL DEFAULT false,
+	content_hash VARCHAR(64) NOT NULL,
+	total_workouts INT NOT NULL DEFAULT 0,
+	processed_workouts INT NOT NULL DEFAULT 0,
+	created_workouts INT NOT NULL DEFAULT 0,
+	skipped_workouts INT NOT NULL DEFAULT 0,
+	unmapped JSONB NOT NULL DEFAULT '[]',
+	preview JSONB,
+	error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	finished_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS import_jobs_user_hash_idx ON import_jobs(user_id, content_hash);
+
+	CREATE TABLE IF NOT EXISTS exercise_name_mappings(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	source_name VARCHAR(255) NOT NULL,
+	exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS exercise_name_mappings_user_name_idx ON exercise_name_mappings(user_id, lower(source_name));`
+	if _, err := db.Exec(createTableImportsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableDataExportsQuery := `
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP;
+	CREATE TABLE IF NOT EXISTS data_exports(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	status VARCHAR(16) NOT NULL,
+	file_path TEXT NOT NULL DEFAULT '',
+	size_bytes BIGINT NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT '',
+	expires_at TIMESTAMP,
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	finished_at TIMESTAMP
+	)`
+	if _, err := db.Exec(createTableDataExportsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableCalendarFeedsQuery := `
+	CREATE TABLE IF NOT EXISTS calendar_feeds(
+	id SERIAL PRIMARY KEY,
+	user_id INT UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+	token_hash VARCHAR(64) UNIQUE NOT NULL,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	)`
+	if _, err := db.Exec(createTableCalendarFeedsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableProgramStructureQuery := `
+	CREATE TABLE IF NOT EXISTS program_phases(
+	id SERIAL PRIMARY KEY,
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	position INT NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS program_weeks(
+	id SERIAL PRIMARY KEY,
+	phase_id INT REFERENCES program_phases(id) ON DELETE CASCADE,
+	position INT NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS program_days(
+	id SERIAL PRIMARY KEY,
+	week_id INT REFERENCES program_weeks(id) ON DELETE CASCADE,
+	position INT NOT NULL,
+	name VARCHAR(255) NOT NULL DEFAULT ''
+	);
+
+	CREATE TABLE IF NOT EXISTS program_slots(
+	id SERIAL PRIMARY KEY,
+	day_id INT REFERENCES program_days(id) ON DELETE CASCADE,
+	position INT NOT NULL,
+	exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+	sets INT NOT NULL,
+	reps INT NOT NULL,
+	reps_max INT,
+	weight NUMERIC(8,3),
+	percent_e1rm NUMERIC(5,2),
+	rest_seconds INT,
+	notes TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS program_phases_program_idx ON program_phases(program_id);
+	CREATE INDEX IF NOT EXISTS program_weeks_phase_idx ON program_weeks(phase_id);
+	CREATE INDEX IF NOT EXISTS program_days_week_idx ON program_days(week_id);
+	CREATE INDEX IF NOT EXISTS program_slots_day_idx ON program_slots(day_id);`
+	if _, err := db.Exec(createTableProgramStructureQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// flat programs from before phases existed become a single phase, week
+	// and day; this has to happen before their first version is recorded.
+	// Migration 000014 does the same and 000023 drops the old table.
+	flatProgramsExist, err := tableExists(db, "exercises_program")
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if flatProgramsExist{
+		convertFlatProgramsQuery := `
+		INSERT INTO program_phases (program_id, position, name)
+		SELECT DISTINCT ep.program_id, 1, '' FROM exercises_program ep
+		WHERE NOT EXISTS (SELECT 1 FROM program_phases ph WHERE ph.program_id = ep.program_id);
+
+		INSERT INTO program_weeks (phase_id, position, name)
+		SELECT ph.id, 1, '' FROM program_phases ph
+		WHERE NOT EXISTS (SELECT 1 FROM program_weeks w WHERE w.phase_id = ph.id);
+
+		INSERT INTO program_days (week_id, position, name)
+		SELECT w.id, 1, 'Day 1' FROM program_weeks w
+		WHERE NOT EXISTS (SELECT 1 FROM program_days d WHERE d.week_id = w.id);
+
+		INSERT INTO program_slots (day_id, position, exercise_id, sets, reps, weight)
+		SELECT d.id, ROW_NUMBER() OVER (PARTITION BY ep.program_id ORDER BY ep.id), ep.exercise_id, ep.sets, ep.reps, ep.weight
+		FROM exercises_program ep
+		JOIN program_phases ph ON ph.program_id = ep.program_id AND ph.position = 1
+		JOIN program_weeks w ON w.phase_id = ph.id AND w.position = 1
+		JOIN program_days d ON d.week_id = w.id AND d.position = 1
+		WHERE NOT EXISTS (SELECT 1 FROM program_slots s WHERE s.day_id = d.id);`
+		if _, err := db.Exec(convertFlatProgramsQuery); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	createTableProgressionQuery := `
+	CREATE TABLE IF NOT EXISTS program_progression_rules(
+	id SERIAL PRIMARY KEY,
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+	type VARCHAR(16) NOT NULL,
+	increment NUMERIC(8,3) NOT NULL DEFAULT 0,
+	deload_after INT NOT NULL DEFAULT 0,
+	deload_percent NUMERIC(5,2) NOT NULL DEFAULT 10,
+	wave_percents NUMERIC(5,2)[],
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	UNIQUE (program_id, exercise_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS program_progression_state(
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+	weight NUMERIC(8,3) NOT NULL,
+	reps INT NOT NULL,
+	wave_step INT NOT NULL DEFAULT 0,
+	failures INT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP DEFAULT now() NOT NULL,
+	PRIMARY KEY (program_id, exercise_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS program_progression_history(
+	id SERIAL PRIMARY KEY,
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	exercise_id INT REFERENCES exercises(id) ON DELETE CASCADE,
+	workout_id INT REFERENCES workouts(id) ON DELETE SET NULL,
+	action VARCHAR(16) NOT NULL,
+	previous_weight NUMERIC(8,3) NOT NULL,
+	new_weight NUMERIC(8,3) NOT NULL,
+	previous_reps INT NOT NULL,
+	new_reps INT NOT NULL,
+	reason TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS program_progression_history_program_idx ON program_progression_history(program_id, created_at);`
+	if _, err := db.Exec(createTableProgressionQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	alterTableProgramsLibraryQuery := `
+	ALTER TABLE programs ADD COLUMN IF NOT EXISTS visibility VARCHAR(16) NOT NULL DEFAULT 'private';
+	ALTER TABLE programs ADD COLUMN IF NOT EXISTS share_token VARCHAR(64) UNIQUE;
+	ALTER TABLE programs ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+	ALTER TABLE programs ADD COLUMN IF NOT EXISTS forked_from_id INT REFERENCES programs(id) ON DELETE SET NULL;
+	ALTER TABLE programs ADD COLUMN IF NOT EXISTS forked_from_name TEXT;
+	ALTER TABLE programs ADD COLUMN IF NOT EXISTS forked_from_author TEXT;
+
+	CREATE TABLE IF NOT EXISTS program_followers(
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	PRIMARY KEY (program_id, user_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS programs_public_idx ON programs(created_at) WHERE visibility = 'public';
+	CREATE INDEX IF NOT EXISTS programs_tags_idx ON programs USING GIN (tags);
+	CREATE INDEX IF NOT EXISTS programs_forked_from_idx ON programs(forked_from_id);`
+	if _, err := db.Exec(alterTableProgramsLibraryQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableProgramVersionsQuery := `
+	CREATE TABLE IF NOT EXISTS program_versions(
+	id SERIAL PRIMARY KEY,
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	version INT NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	structure JSONB NOT NULL,
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	UNIQUE (program_id, version)
+	);
+
+	ALTER TABLE workouts ADD COLUMN IF NOT EXISTS program_version_id INT REFERENCES program_versions(id) ON DELETE SET NULL;
+
+	INSERT INTO program_versions (program_id, version, name, structure, created_at)
+	SELECT p.id, 1, p.name, COALESCE((SELECT jsonb_agg(jsonb_build_object('name', ph.name, 'weeks', COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('name', w.name, 'days', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object('name', d.name, 'slots', COALESCE((
+				SELECT jsonb_agg(jsonb_build_object('exercise_id', s.exercise_id, 'sets', s.sets, 'reps', s.reps,
+					'reps_max', s.reps_max, 'weight', s.weight, 'percent_e1rm', s.percent_e1rm,
+					'rest_seconds', s.rest_seconds, 'notes', s.notes) ORDER BY s.position)
+				FROM program_slots s WHERE s.day_id = d.id), '[]'::jsonb)) ORDER BY d.position)
+			FROM program_days d WHERE d.week_id = w.id), '[]'::jsonb)) ORDER BY w.position)
+		FROM program_weeks w WHERE w.phase_id = ph.id), '[]'::jsonb)) ORDER BY ph.position)
+	FROM program_phases ph WHERE ph.program_id = p.id), '[]'::jsonb), p.created_at
+	FROM programs p
+	WHERE NOT EXISTS (SELECT 1 FROM program_versions v WHERE v.program_id = p.id);
+
+	UPDATE workouts wo SET program_version_id = v.id
+	FROM program_versions v
+	WHERE v.program_id = wo.program_id AND v.version = 1 AND wo.program_version_id IS NULL;`
+	if _, err := db.Exec(createTableProgramVersionsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableScheduleQuery := `
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS reschedule_policy VARCHAR(16) NOT NULL DEFAULT 'none';
+
+	CREATE TABLE IF NOT EXISTS schedule_rules(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	weekdays INT[] NOT NULL,
+	start_date DATE NOT NULL,
+	end_date DATE,
+	day_index INT,
+	index_offset INT NOT NULL DEFAULT 0,
+	materialized_until DATE,
+	created_at TIMESTAMP DEFAULT now() NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS scheduled_sessions(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	program_id INT REFERENCES programs(id) ON DELETE CASCADE,
+	rule_id INT REFERENCES schedule_rules(id) ON DELETE SET NULL,
+	occurrence INT,
+	day_index INT NOT NULL,
+	date DATE NOT NULL,
+	status VARCHAR(16) NOT NULL DEFAULT 'planned',
+	workout_id INT REFERENCES workouts(id) ON DELETE SET NULL,
+	rescheduled_from DATE,
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	updated_at TIMESTAMP DEFAULT now() NOT NULL
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS scheduled_sessions_rule_occurrence_idx ON scheduled_sessions(rule_id, occurrence);
+	CREATE INDEX IF NOT EXISTS scheduled_sessions_user_date_idx ON scheduled_sessions(user_id, date);
+	CREATE INDEX IF NOT EXISTS schedule_rules_user_idx ON schedule_rules(user_id);`
+	if _, err := db.Exec(createTableScheduleQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	entryPositionExists, err := columnExists(db, "exercises_entry", "position")
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	createExerciseGroupsQuery := `
+	ALTER TABLE program_days ADD COLUMN IF NOT EXISTS groups JSONB;
+	ALTER TABLE program_slots ADD COLUMN IF NOT EXISTS group_label TEXT;
+
+	ALTER TABLE workouts ADD COLUMN IF NOT EXISTS exercise_groups JSONB;
+	ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS position INT;
+	ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS group_label TEXT`
+	if _, err := db.Exec(createExerciseGroupsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !entryPositionExists{
+		// existing entries keep the order they were saved in
+		backfillEntryPositionQuery := `
+		UPDATE exercises_entry ee SET position = ordered.position
+		FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY workout_id ORDER BY id) AS position FROM exercises_entry) ordered
+		WHERE ee.id = ordered.id;
+
+		ALTER TABLE exercises_entry ALTER COLUMN position SET DEFAULT 0;
+		ALTER TABLE exercises_entry ALTER COLUMN position SET NOT NULL;`
+		if _, err := db.Exec(backfillEntryPositionQuery); err != nil{
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	createExerciseSubstitutionsQuery := `
+	ALTER TABLE program_slots ADD COLUMN IF NOT EXISTS substituted_for_id INT REFERENCES exercises(id) ON DELETE SET NULL;
+	ALTER TABLE exercises_entry ADD COLUMN IF NOT EXISTS substituted_for_id INT REFERENCES exercises(id) ON DELETE SET NULL;`
+	if _, err := db.Exec(createExerciseSubstitutionsQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createTableEquipmentProfilesQuery := `CREATE TABLE IF NOT EXISTS equipment_profiles(
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id) ON DELETE CASCADE,
+	name VARCHAR(64) NOT NULL,
+	equipment TEXT[] NOT NULL DEFAULT '{}',
+	plate_increment NUMERIC(8,3),
+	dumbbell_increment NUMERIC(8,3),
+	is_default BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMP DEFAULT now() NOT NULL,
+	UNIQUE (user_id, name)
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS equipment_profiles_default_idx ON equipment_profiles(user_id) WHERE is_default;`
+	if _, err := db.Exec(createTableEquipmentProfilesQuery); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 	return &Storage{db: db}, nil
-}
\ No newline at end of file
+}
+
+// tableExists reports whether a table is already there, so data backfills
+// that belong to its creation run only once.
+func tableExists(db *sqlx.DB, table string) (bool, error){
+	var exists bool
+	err := db.Get(&exists, `SELECT to_regclass($1) IS NOT NULL`, table)
+	return exists, err
+}
+
+func columnExists(db *sqlx.DB, table, column string) (bool, error){
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2)`
+	err := db.Get(&exists, query, table, column)
+	return exists, err
+}
@@ -0,0 +1,69 @@
+package trackfile
+
+import (
+	"fmt"
+
+	"github.com/artembliss/go-fitness-tracker/pkg/fit"
+)
+
+// ParseFIT decodes the GPS part of a FIT file. Strength sets are not part of
+// an Activity; use fit.Decode directly to read them.
+func ParseFIT(data []byte) (*Activity, error){
+	const op = "trackfile.ParseFIT"
+
+	file, err := fit.Decode(data)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return FromFIT(file), nil
+}
+
+func FromFIT(file *fit.File) *Activity{
+	activity := &Activity{Format: FormatFIT}
+	if len(file.Sessions) > 0{
+		activity.Sport = file.Sessions[0].Sport
+		activity.Start = file.Sessions[0].Start
+	}
+
+	for _, l := range file.Laps{
+		activity.Laps = append(activity.Laps, Lap{
+			Start: l.Start,
+			DurationSeconds: l.TimerSeconds,
+			Distance: l.Distance,
+			AvgHeartRate: l.AvgHeartRate,
+			MaxHeartRate: l.MaxHeartRate,
+			AvgCadence: l.AvgCadence,
+		})
+	}
+	// files without laps still carry the totals in the session
+	if len(activity.Laps) == 0{
+		for _, s := range file.Sessions{
+			activity.Laps = append(activity.Laps, Lap{
+				Start: s.Start,
+				DurationSeconds: s.TimerSeconds,
+				Distance: s.Distance,
+				AvgHeartRate: s.AvgHeartRate,
+				MaxHeartRate: s.MaxHeartRate,
+				AvgCadence: s.AvgCadence,
+			})
+		}
+	}
+
+	for _, r := range file.Records{
+		activity.Points = append(activity.Points, Point{
+			Time: r.Time,
+			Lat: r.Lat,
+			Lon: r.Lon,
+			HasPos: r.HasPos,
+			Elevation: r.Altitude,
+			Distance: r.Distance,
+			HeartRate: r.HeartRate,
+			Cadence: r.Cadence,
+		})
+	}
+
+	if activity.Start.IsZero() && len(activity.Points) > 0{
+		activity.Start = activity.Points[0].Time
+	}
+	return activity
+}
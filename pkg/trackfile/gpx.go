@@ -0,0 +1,86 @@
+package trackfile
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type gpxFile struct {
+	Metadata struct {
+		Time string `xml:"time"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// gpxPoint matches heart rate and cadence from the Garmin TrackPointExtension
+// by local name, so any namespace prefix works.
+type gpxPoint struct {
+	Lat       float64  `xml:"lat,attr"`
+	Lon       float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele"`
+	Time      string   `xml:"time"`
+	HeartRate int      `xml:"extensions>TrackPointExtension>hr"`
+	Cadence   int      `xml:"extensions>TrackPointExtension>cad"`
+}
+
+func ParseGPX(r io.Reader) (*Activity, error){
+	const op = "trackfile.ParseGPX"
+
+	var file gpxFile
+	if err := decodeXML(r, &file); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	activity := &Activity{Format: FormatGPX}
+	for _, trk := range file.Tracks{
+		if activity.Name == ""{
+			activity.Name = trk.Name
+		}
+		if activity.Sport == ""{
+			activity.Sport = trk.Type
+		}
+		for _, seg := range trk.Segments{
+			for _, p := range seg.Points{
+				t, err := parseTime(p.Time)
+				if err != nil{
+					return nil, fmt.Errorf("%s: %w", op, err)
+				}
+				activity.Points = append(activity.Points, Point{
+					Time: t,
+					Lat: p.Lat,
+					Lon: p.Lon,
+					HasPos: true,
+					Elevation: p.Elevation,
+					HeartRate: p.HeartRate,
+					Cadence: p.Cadence,
+				})
+			}
+		}
+	}
+
+	if len(activity.Points) > 0{
+		activity.Start = activity.Points[0].Time
+	} else if file.Metadata.Time != ""{
+		activity.Start, _ = parseTime(file.Metadata.Time)
+	}
+
+	return activity, nil
+}
+
+func parseTime(value string) (time.Time, error){
+	if value == ""{
+		return time.Time{}, fmt.Errorf("track point without time")
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil{
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,92 @@
+package trackfile
+
+import (
+	"fmt"
+	"io"
+)
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string   `xml:"Sport,attr"`
+		ID    string   `xml:"Id"`
+		Notes string   `xml:"Notes"`
+		Laps  []tcxLap `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+type tcxLap struct {
+	StartTime        string  `xml:"StartTime,attr"`
+	TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64 `xml:"DistanceMeters"`
+	AvgHeartRate     int     `xml:"AverageHeartRateBpm>Value"`
+	MaxHeartRate     int     `xml:"MaximumHeartRateBpm>Value"`
+	Cadence          int     `xml:"Cadence"`
+	// running cadence lives in the ActivityExtension namespace
+	AvgRunCadence int             `xml:"Extensions>LX>AvgRunCadence"`
+	Points        []tcxTrackpoint `xml:"Track>Trackpoint"`
+}
+
+type tcxTrackpoint struct {
+	Time       string   `xml:"Time"`
+	Lat        *float64 `xml:"Position>LatitudeDegrees"`
+	Lon        *float64 `xml:"Position>LongitudeDegrees"`
+	Altitude   *float64 `xml:"AltitudeMeters"`
+	Distance   *float64 `xml:"DistanceMeters"`
+	HeartRate  int      `xml:"HeartRateBpm>Value"`
+	Cadence    int      `xml:"Cadence"`
+	RunCadence int      `xml:"Extensions>TPX>RunCadence"`
+}
+
+// ParseTCX reads the first activity of a Training Center file.
+func ParseTCX(r io.Reader) (*Activity, error){
+	const op = "trackfile.ParseTCX"
+
+	var file tcxFile
+	if err := decodeXML(r, &file); err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(file.Activities) == 0{
+		return nil, fmt.Errorf("%s: file contains no activities", op)
+	}
+	src := file.Activities[0]
+
+	activity := &Activity{Format: FormatTCX, Sport: src.Sport, Name: src.Notes}
+	if src.ID != ""{
+		activity.Start, _ = parseTime(src.ID)
+	}
+
+	for _, l := range src.Laps{
+		lap := Lap{
+			DurationSeconds: l.TotalTimeSeconds,
+			Distance: l.DistanceMeters,
+			AvgHeartRate: l.AvgHeartRate,
+			MaxHeartRate: l.MaxHeartRate,
+			AvgCadence: max(l.Cadence, l.AvgRunCadence),
+		}
+		lap.Start, _ = parseTime(l.StartTime)
+		activity.Laps = append(activity.Laps, lap)
+
+		for _, tp := range l.Points{
+			t, err := parseTime(tp.Time)
+			if err != nil{
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			point := Point{
+				Time: t,
+				Elevation: tp.Altitude,
+				Distance: tp.Distance,
+				HeartRate: tp.HeartRate,
+				Cadence: max(tp.Cadence, tp.RunCadence),
+			}
+			if tp.Lat != nil && tp.Lon != nil{
+				point.Lat, point.Lon, point.HasPos = *tp.Lat, *tp.Lon, true
+			}
+			activity.Points = append(activity.Points, point)
+		}
+	}
+
+	if activity.Start.IsZero() && len(activity.Laps) > 0{
+		activity.Start = activity.Laps[0].Start
+	}
+	return activity, nil
+}
@@ -0,0 +1,313 @@
+// Package trackfile parses GPS activity files (GPX, TCX and FIT) into a common
+// activity representation.
+package trackfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+	FormatFIT = "fit"
+
+	earthRadius = 6371000.0
+	// elevationThreshold filters GPS altitude noise out of the elevation gain.
+	elevationThreshold = 2.0
+	splitDistance      = 1000.0
+)
+
+var ErrUnknownFormat = errors.New("unknown track file format")
+
+type Point struct {
+	Time      time.Time
+	Lat       float64
+	Lon       float64
+	HasPos    bool
+	Elevation *float64
+	// Distance is the cumulative distance in metres when the device recorded it.
+	Distance  *float64
+	HeartRate int
+	Cadence   int
+}
+
+type Lap struct {
+	Start           time.Time
+	DurationSeconds float64
+	Distance        float64
+	AvgHeartRate    int
+	MaxHeartRate    int
+	AvgCadence      int
+}
+
+type Activity struct {
+	Format string
+	Sport  string
+	Name   string
+	Start  time.Time
+	Laps   []Lap
+	Points []Point
+}
+
+type Summary struct {
+	Distance        float64
+	DurationSeconds int
+	ElevationGain   float64
+	AvgHeartRate    int
+	MaxHeartRate    int
+	AvgCadence      int
+}
+
+// Split is a lap recorded by the device or a computed kilometre split.
+type Split struct {
+	Type            string
+	Distance        float64
+	DurationSeconds int
+	AvgHeartRate    int
+	AvgCadence      int
+}
+
+// DetectFormat looks at the content rather than the file name: FIT files
+// carry a ".FIT" signature, XML files are told apart by their root element.
+func DetectFormat(data []byte) (string, error){
+	if len(data) >= 12 && string(data[8:12]) == ".FIT"{
+		return FormatFIT, nil
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for{
+		tok, err := dec.Token()
+		if err != nil{
+			return "", ErrUnknownFormat
+		}
+		if start, ok := tok.(xml.StartElement); ok{
+			switch start.Name.Local{
+			case "gpx":
+				return FormatGPX, nil
+			case "TrainingCenterDatabase":
+				return FormatTCX, nil
+			default:
+				return "", ErrUnknownFormat
+			}
+		}
+	}
+}
+
+// Parse decodes a GPX, TCX or FIT file.
+func Parse(data []byte) (*Activity, error){
+	const op = "trackfile.Parse"
+
+	format, err := DetectFormat(data)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var activity *Activity
+	switch format{
+	case FormatGPX:
+		activity, err = ParseGPX(bytes.NewReader(data))
+	case FormatTCX:
+		activity, err = ParseTCX(bytes.NewReader(data))
+	case FormatFIT:
+		activity, err = ParseFIT(data)
+	}
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(activity.Points) == 0 && len(activity.Laps) == 0{
+		return nil, fmt.Errorf("%s: file contains no track points or laps", op)
+	}
+	return activity, nil
+}
+
+// Summary prefers device-recorded lap totals and distances over values
+// derived from GPS positions.
+func (a *Activity) Summary() Summary{
+	var s Summary
+
+	var lapDistance, lapSeconds float64
+	for _, l := range a.Laps{
+		lapDistance += l.Distance
+		lapSeconds += l.DurationSeconds
+		s.MaxHeartRate = max(s.MaxHeartRate, l.MaxHeartRate)
+	}
+
+	s.Distance = lapDistance
+	if s.Distance == 0{
+		s.Distance = a.pointsDistance()
+	}
+
+	s.DurationSeconds = int(math.Round(lapSeconds))
+	if s.DurationSeconds == 0 && len(a.Points) > 1{
+		s.DurationSeconds = int(a.Points[len(a.Points)-1].Time.Sub(a.Points[0].Time).Seconds())
+	}
+
+	s.ElevationGain = elevationGain(a.Points)
+	s.AvgHeartRate, s.AvgCadence = averages(a.Points)
+	for _, p := range a.Points{
+		s.MaxHeartRate = max(s.MaxHeartRate, p.HeartRate)
+	}
+
+	if s.AvgHeartRate == 0 && lapSeconds > 0{
+		var weighted, cadence float64
+		for _, l := range a.Laps{
+			weighted += float64(l.AvgHeartRate) * l.DurationSeconds
+			cadence += float64(l.AvgCadence) * l.DurationSeconds
+		}
+		s.AvgHeartRate = int(math.Round(weighted / lapSeconds))
+		if s.AvgCadence == 0{
+			s.AvgCadence = int(math.Round(cadence / lapSeconds))
+		}
+	}
+
+	return s
+}
+
+// Splits returns the device laps when there is more than one, otherwise
+// kilometre splits computed from the track points.
+func (a *Activity) Splits() []Split{
+	if len(a.Laps) > 1{
+		splits := make([]Split, 0, len(a.Laps))
+		for _, l := range a.Laps{
+			splits = append(splits, Split{
+				Type: "lap",
+				Distance: l.Distance,
+				DurationSeconds: int(math.Round(l.DurationSeconds)),
+				AvgHeartRate: l.AvgHeartRate,
+				AvgCadence: l.AvgCadence,
+			})
+		}
+		return splits
+	}
+
+	distances := a.cumulativeDistances()
+	if len(distances) < 2{
+		return nil
+	}
+
+	var splits []Split
+	startIdx := 0
+	for i := 1; i < len(a.Points); i++{
+		last := i == len(a.Points)-1
+		if distances[i]-distances[startIdx] < splitDistance && !last{
+			continue
+		}
+		segment := a.Points[startIdx:i+1]
+		hr, cadence := averages(segment)
+		split := Split{
+			Type: "split",
+			Distance: distances[i] - distances[startIdx],
+			DurationSeconds: int(segment[len(segment)-1].Time.Sub(segment[0].Time).Seconds()),
+			AvgHeartRate: hr,
+			AvgCadence: cadence,
+		}
+		if split.Distance > 0{
+			splits = append(splits, split)
+		}
+		startIdx = i
+	}
+	return splits
+}
+
+func (a *Activity) pointsDistance() float64{
+	distances := a.cumulativeDistances()
+	if len(distances) == 0{
+		return 0
+	}
+	return distances[len(distances)-1]
+}
+
+// cumulativeDistances uses recorded distances when every point has one and
+// falls back to great-circle distances between positions.
+func (a *Activity) cumulativeDistances() []float64{
+	if len(a.Points) == 0{
+		return nil
+	}
+
+	recorded := true
+	for _, p := range a.Points{
+		if p.Distance == nil{
+			recorded = false
+			break
+		}
+	}
+
+	distances := make([]float64, len(a.Points))
+	for i := 1; i < len(a.Points); i++{
+		prev, cur := a.Points[i-1], a.Points[i]
+		switch{
+		case recorded:
+			distances[i] = *cur.Distance - *a.Points[0].Distance
+		case prev.HasPos && cur.HasPos:
+			distances[i] = distances[i-1] + haversine(prev.Lat, prev.Lon, cur.Lat, cur.Lon)
+		default:
+			distances[i] = distances[i-1]
+		}
+	}
+	return distances
+}
+
+func elevationGain(points []Point) float64{
+	var gain float64
+	var ref *float64
+	for _, p := range points{
+		if p.Elevation == nil{
+			continue
+		}
+		ele := *p.Elevation
+		switch{
+		case ref == nil || ele < *ref:
+			ref = &ele
+		case ele-*ref >= elevationThreshold:
+			gain += ele - *ref
+			ref = &ele
+		}
+	}
+	return math.Round(gain*10) / 10
+}
+
+func averages(points []Point) (int, int){
+	var hrSum, hrCount, cadSum, cadCount int
+	for _, p := range points{
+		if p.HeartRate > 0{
+			hrSum += p.HeartRate
+			hrCount++
+		}
+		if p.Cadence > 0{
+			cadSum += p.Cadence
+			cadCount++
+		}
+	}
+	var hr, cadence int
+	if hrCount > 0{
+		hr = int(math.Round(float64(hrSum) / float64(hrCount)))
+	}
+	if cadCount > 0{
+		cadence = int(math.Round(float64(cadSum) / float64(cadCount)))
+	}
+	return hr, cadence
+}
+
+func haversine(lat1, lon1, lat2, lon2 float64) float64{
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+func decodeXML(r io.Reader, v interface{}) error{
+	dec := xml.NewDecoder(r)
+	// some devices declare encodings other than UTF-8; their content is ASCII in practice
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error){
+		return input, nil
+	}
+	return dec.Decode(v)
+}
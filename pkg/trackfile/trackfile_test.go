@@ -0,0 +1,159 @@
+package trackfile
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// gpxSample runs along the equator in steps of 0.005° (about 556 m), one
+// point a minute, with altitude noise below elevationThreshold.
+const gpxSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1"
+  xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk>
+    <name>Morning Run</name>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="0" lon="0.000"><ele>100</ele><time>2024-05-01T07:00:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr><gpxtpx:cad>80</gpxtpx:cad></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="0" lon="0.005"><ele>101</ele><time>2024-05-01T07:01:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>140</gpxtpx:hr><gpxtpx:cad>84</gpxtpx:cad></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="0" lon="0.010"><ele>103</ele><time>2024-05-01T07:02:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr><gpxtpx:cad>86</gpxtpx:cad></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="0" lon="0.015"><ele>102</ele><time>2024-05-01T07:03:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>160</gpxtpx:hr><gpxtpx:cad>88</gpxtpx:cad></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="0" lon="0.020"><ele>105</ele><time>2024-05-01T07:04:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>170</gpxtpx:hr><gpxtpx:cad>90</gpxtpx:cad></gpxtpx:TrackPointExtension></extensions></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+const tcxSample = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2024-05-02T18:00:00Z</Id>
+      <Lap StartTime="2024-05-02T18:00:00Z">
+        <TotalTimeSeconds>600</TotalTimeSeconds>
+        <DistanceMeters>5000</DistanceMeters>
+        <AverageHeartRateBpm><Value>130</Value></AverageHeartRateBpm>
+        <MaximumHeartRateBpm><Value>150</Value></MaximumHeartRateBpm>
+        <Cadence>85</Cadence>
+      </Lap>
+      <Lap StartTime="2024-05-02T18:10:00Z">
+        <TotalTimeSeconds>300</TotalTimeSeconds>
+        <DistanceMeters>2000</DistanceMeters>
+        <AverageHeartRateBpm><Value>160</Value></AverageHeartRateBpm>
+        <MaximumHeartRateBpm><Value>175</Value></MaximumHeartRateBpm>
+        <Cadence>95</Cadence>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestDetectFormat(t *testing.T){
+	fitHeader := []byte{14, 0x10, 0, 0, 0, 0, 0, 0, '.', 'F', 'I', 'T', 0, 0}
+
+	tests := []struct{
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{"gpx", []byte(gpxSample), FormatGPX, false},
+		{"tcx", []byte(tcxSample), FormatTCX, false},
+		{"fit", fitHeader, FormatFIT, false},
+		{"other xml", []byte(`<?xml version="1.0"?><kml></kml>`), "", true},
+		{"not xml", []byte("date,exercise\n"), "", true},
+		{"empty", nil, "", true},
+	}
+	for _, tt := range tests{
+		t.Run(tt.name, func(t *testing.T){
+			got, err := DetectFormat(tt.data)
+			if (err != nil) != tt.wantErr{
+				t.Fatalf("DetectFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want{
+				t.Errorf("DetectFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGPX(t *testing.T){
+	activity, err := Parse([]byte(gpxSample))
+	if err != nil{
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if activity.Format != FormatGPX || activity.Sport != "running" || activity.Name != "Morning Run"{
+		t.Errorf("activity = %q %q %q, want gpx running Morning Run", activity.Format, activity.Sport, activity.Name)
+	}
+	if want := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC); !activity.Start.Equal(want){
+		t.Errorf("Start = %v, want %v", activity.Start, want)
+	}
+	if len(activity.Points) != 5{
+		t.Fatalf("got %d points, want 5", len(activity.Points))
+	}
+
+	summary := activity.Summary()
+	step := haversine(0, 0, 0, 0.005)
+	if math.Abs(summary.Distance-4*step) > 1{
+		t.Errorf("Distance = %.1f, want %.1f", summary.Distance, 4*step)
+	}
+	if summary.DurationSeconds != 240{
+		t.Errorf("DurationSeconds = %d, want 240", summary.DurationSeconds)
+	}
+	// 100 -> 103 and 102 -> 105; the 1 m step is noise
+	if summary.ElevationGain != 6{
+		t.Errorf("ElevationGain = %v, want 6", summary.ElevationGain)
+	}
+	if summary.AvgHeartRate != 148 || summary.MaxHeartRate != 170 || summary.AvgCadence != 86{
+		t.Errorf("heart rate and cadence = %d/%d/%d, want 148/170/86",
+			summary.AvgHeartRate, summary.MaxHeartRate, summary.AvgCadence)
+	}
+
+	splits := activity.Splits()
+	if len(splits) != 2{
+		t.Fatalf("got %d splits, want 2", len(splits))
+	}
+	for i, split := range splits{
+		if split.Type != "split" || math.Abs(split.Distance-2*step) > 1 || split.DurationSeconds != 120{
+			t.Errorf("split %d = %+v, want a 120 s split of %.1f m", i, split, 2*step)
+		}
+	}
+}
+
+func TestParseTCXLaps(t *testing.T){
+	activity, err := Parse([]byte(tcxSample))
+	if err != nil{
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if activity.Format != FormatTCX || activity.Sport != "Biking" || len(activity.Laps) != 2{
+		t.Fatalf("activity = %q %q with %d laps, want tcx Biking with 2", activity.Format, activity.Sport, len(activity.Laps))
+	}
+
+	summary := activity.Summary()
+	if summary.Distance != 7000 || summary.DurationSeconds != 900{
+		t.Errorf("Distance, DurationSeconds = %v, %d, want 7000, 900", summary.Distance, summary.DurationSeconds)
+	}
+	// lap averages are weighted by lap time: (130*600 + 160*300) / 900
+	if summary.AvgHeartRate != 140 || summary.MaxHeartRate != 175{
+		t.Errorf("AvgHeartRate, MaxHeartRate = %d, %d, want 140, 175", summary.AvgHeartRate, summary.MaxHeartRate)
+	}
+	if summary.AvgCadence != 88{
+		t.Errorf("AvgCadence = %d, want 88", summary.AvgCadence)
+	}
+
+	splits := activity.Splits()
+	if len(splits) != 2 || splits[0].Type != "lap" || splits[1].Distance != 2000 || splits[1].DurationSeconds != 300{
+		t.Errorf("Splits() = %+v, want the two device laps", splits)
+	}
+}
+
+func TestParseRejectsEmptyTrack(t *testing.T){
+	empty := `<?xml version="1.0"?><gpx version="1.1"><trk><trkseg></trkseg></trk></gpx>`
+	if _, err := Parse([]byte(empty)); err == nil{
+		t.Error("Parse() of a track without points succeeded, want an error")
+	}
+}
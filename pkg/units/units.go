@@ -0,0 +1,136 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// System is a user's preferred unit system. Weights are always stored in
+// kilograms and converted only at the API boundary.
+type System string
+
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+const PoundsPerKilogram = 2.20462262185
+
+// Parse accepts a system name or a weight unit abbreviation.
+func Parse(s string) (System, error){
+	switch strings.ToLower(strings.TrimSpace(s)){
+	case "metric", "kg", "kgs":
+		return Metric, nil
+	case "imperial", "lb", "lbs":
+		return Imperial, nil
+	default:
+		return "", fmt.Errorf("unknown unit %q, expected kg or lb", s)
+	}
+}
+
+// Resolve returns the first non-empty candidate, in order of precedence
+// (e.g. request body, query parameter, user preference), defaulting to Metric.
+func Resolve(candidates ...string) (System, error){
+	for _, c := range candidates{
+		if strings.TrimSpace(c) == ""{
+			continue
+		}
+		return Parse(c)
+	}
+	return Metric, nil
+}
+
+// WeightUnit is the abbreviation shown next to weights, e.g. "kg".
+func (s System) WeightUnit() string{
+	if s == Imperial{
+		return "lb"
+	}
+	return "kg"
+}
+
+func ToKilograms(v float64, s System) float64{
+	if s == Imperial{
+		return v / PoundsPerKilogram
+	}
+	return v
+}
+
+// FromKilograms converts a stored weight for output, rounded to 0.01.
+func FromKilograms(kg float64, s System) float64{
+	if s == Imperial{
+		return round2(kg * PoundsPerKilogram)
+	}
+	return round2(kg)
+}
+
+func ToKilogramsSlice(values []float64, s System) []float64{
+	result := make([]float64, len(values))
+	for i, v := range values{
+		result[i] = ToKilograms(v, s)
+	}
+	return result
+}
+
+func FromKilogramsSlice(values []float64, s System) []float64{
+	result := make([]float64, len(values))
+	for i, v := range values{
+		result[i] = FromKilograms(v, s)
+	}
+	return result
+}
+
+func round2(v float64) float64{
+	return math.Round(v*100) / 100
+}
+
+const (
+	MetersPerMile = 1609.344
+	FeetPerMeter  = 3.28083989501
+)
+
+// DistanceUnit is "km" or "mi"; distances are stored in metres.
+func (s System) DistanceUnit() string{
+	if s == Imperial{
+		return "mi"
+	}
+	return "km"
+}
+
+// ElevationUnit is "m" or "ft".
+func (s System) ElevationUnit() string{
+	if s == Imperial{
+		return "ft"
+	}
+	return "m"
+}
+
+// DistanceToMeters converts kilometres or miles to metres.
+func DistanceToMeters(v float64, s System) float64{
+	if s == Imperial{
+		return v * MetersPerMile
+	}
+	return v * 1000
+}
+
+// DistanceFromMeters converts metres to kilometres or miles, rounded to 0.01.
+func DistanceFromMeters(m float64, s System) float64{
+	if s == Imperial{
+		return round2(m / MetersPerMile)
+	}
+	return round2(m / 1000)
+}
+
+func ElevationToMeters(v float64, s System) float64{
+	if s == Imperial{
+		return v / FeetPerMeter
+	}
+	return v
+}
+
+func ElevationFromMeters(m float64, s System) float64{
+	if s == Imperial{
+		return round2(m * FeetPerMeter)
+	}
+	return round2(m)
+}